@@ -0,0 +1,15 @@
+# Reads all entries of the "blocklist" address list, including entries which
+# were added dynamically by firewall rules
+data "routeros-firewall-list_address_list" "blocklist" {
+  list = "blocklist"
+}
+
+output "blocked_addresses" {
+  value = [for e in data.routeros-firewall-list_address_list.blocklist.entries : e.address]
+}
+
+# Reads only the entries which were added dynamically, filtering on the device
+data "routeros-firewall-list_address_list" "dynamic_blocklist" {
+  list  = "blocklist"
+  query = ["dynamic=true"]
+}
@@ -0,0 +1,17 @@
+data "routeros-firewall-list_api_status" "router" {}
+
+# Only touch the firewall of a healthy device
+resource "routeros-firewall-list_rule_ordering" "input" {
+  rule_type = "filter"
+  rules = [
+    { ref = "comment:allow established" },
+    { ref = "comment:drop all else" },
+  ]
+
+  lifecycle {
+    precondition {
+      condition     = data.routeros-firewall-list_api_status.router.healthy
+      error_message = data.routeros-firewall-list_api_status.router.error
+    }
+  }
+}
@@ -0,0 +1,8 @@
+# List all chains of the filter table, e.g. to validate jump targets
+data "routeros-firewall-list_chains" "filter" {
+  rule_type = "filter"
+}
+
+output "custom_filter_chains" {
+  value = setsubtract(data.routeros-firewall-list_chains.filter.chains, ["input", "forward", "output"])
+}
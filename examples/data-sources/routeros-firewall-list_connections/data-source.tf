@@ -0,0 +1,10 @@
+# Check whether the web server port forwarding is actually passing traffic
+data "routeros-firewall-list_connections" "web" {
+  dst_address = "203.0.113.10:443"
+  protocol    = "tcp"
+  tcp_state   = "established"
+}
+
+output "web_connections" {
+  value = length([for c in data.routeros-firewall-list_connections.web.connections : c if c.dstnat])
+}
@@ -0,0 +1,15 @@
+# Look up the default "drop invalid" rule created by the default configuration
+data "routeros-firewall-list_firewall_rule" "drop_invalid" {
+  rule_type = "filter"
+  comment   = "defconf: drop invalid"
+}
+
+output "drop_invalid_packets" {
+  value = data.routeros-firewall-list_firewall_rule.drop_invalid.packets
+}
+
+# Look up the rule accepting SSH on the input chain, filtering on the device
+data "routeros-firewall-list_firewall_rule" "ssh" {
+  rule_type = "filter"
+  query     = ["chain=input", "protocol=tcp", "dst-port=22"]
+}
@@ -0,0 +1,12 @@
+# Fail the plan early if an interface or list used by the rules is missing
+data "routeros-firewall-list_interfaces" "this" {
+  require_interfaces = ["ether1", "bridge"]
+  require_lists      = ["WAN", "LAN"]
+}
+
+output "wan_interfaces_running" {
+  value = {
+    for name in data.routeros-firewall-list_interfaces.this.lists["WAN"].members :
+    name => data.routeros-firewall-list_interfaces.this.interfaces[name].running
+  }
+}
@@ -0,0 +1,9 @@
+# Reads all entries of the "blocklist" IPv6 address list, including entries
+# which were added dynamically by firewall rules
+data "routeros-firewall-list_ipv6_address_list" "blocklist" {
+  list = "blocklist"
+}
+
+output "blocked_addresses" {
+  value = [for e in data.routeros-firewall-list_ipv6_address_list.blocklist.entries : e.address]
+}
@@ -0,0 +1,10 @@
+# Normalize a feed before it is written to an address list, so that the plan
+# does not keep showing the entries RouterOS rewrote as changes
+data "routeros-firewall-list_normalize_cidrs" "feed" {
+  cidrs = ["1.2.3.4/32", "10.0.0.0/25", "10.0.0.128/25", "10.0.0.7", "1.2.3.4"]
+}
+
+output "feed" {
+  # ["1.2.3.4", "10.0.0.0/24"]
+  value = data.routeros-firewall-list_normalize_cidrs.feed.normalized
+}
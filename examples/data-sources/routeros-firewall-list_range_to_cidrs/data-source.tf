@@ -0,0 +1,9 @@
+# Publish a DHCP pool range to a feed which only accepts CIDR notation
+data "routeros-firewall-list_range_to_cidrs" "pool" {
+  range = "10.0.0.10-10.0.0.20"
+}
+
+output "pool_cidrs" {
+  # ["10.0.0.10/31", "10.0.0.12/30", "10.0.0.16/30", "10.0.0.20/32"]
+  value = data.routeros-firewall-list_range_to_cidrs.pool.cidrs
+}
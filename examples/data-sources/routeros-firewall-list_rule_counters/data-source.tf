@@ -0,0 +1,13 @@
+# Counters of the default drop rule, e.g. to assert that it is never hit by
+# traffic which should have been accepted earlier
+data "routeros-firewall-list_rule_counters" "filter" {
+  rule_type = "filter"
+  rules     = ["comment:drop all else"]
+}
+
+check "drop_rule_unused" {
+  assert {
+    condition     = data.routeros-firewall-list_rule_counters.filter.counters["comment:drop all else"].packets == 0
+    error_message = "The default drop rule matched packets"
+  }
+}
@@ -0,0 +1,16 @@
+# Reference rules by their comment without spelling out the reference syntax
+data "routeros-firewall-list_rule_ref" "allow_ssh" {
+  comment = "allow ssh"
+}
+
+data "routeros-firewall-list_rule_ref" "drop_invalid" {
+  comment = "drop invalid"
+}
+
+resource "routeros-firewall-list_rule_ordering" "input" {
+  rule_type = "filter"
+  rules = [
+    { ref = data.routeros-firewall-list_rule_ref.drop_invalid.ref },
+    { ref = data.routeros-firewall-list_rule_ref.allow_ssh.ref },
+  ]
+}
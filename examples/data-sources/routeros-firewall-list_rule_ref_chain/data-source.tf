@@ -0,0 +1,19 @@
+# The same comment is used in the input and the forward chain, so the
+# references are restricted to the chain of the rule
+data "routeros-firewall-list_rule_ref_chain" "input_drop_invalid" {
+  chain   = "input"
+  comment = "drop invalid"
+}
+
+data "routeros-firewall-list_rule_ref_chain" "forward_drop_invalid" {
+  chain   = "forward"
+  comment = "drop invalid"
+}
+
+output "refs" {
+  # ["chain:input,comment:drop invalid", "chain:forward,comment:drop invalid"]
+  value = [
+    data.routeros-firewall-list_rule_ref_chain.input_drop_invalid.ref,
+    data.routeros-firewall-list_rule_ref_chain.forward_drop_invalid.ref,
+  ]
+}
@@ -0,0 +1,12 @@
+# Snapshot of the filter table, e.g. to archive it as an audit artifact or to
+# diff it during change review
+data "routeros-firewall-list_table_snapshot" "filter" {
+  rule_type = "filter"
+}
+
+output "filter_rules" {
+  value = [
+    for rule in data.routeros-firewall-list_table_snapshot.filter.rules :
+    format("%d %s %s %s", rule.position, rule.chain, rule.action, coalesce(rule.comment, "-"))
+  ]
+}
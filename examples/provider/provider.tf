@@ -17,9 +17,9 @@ provider "routeros-firewall-list" {
 resource "routeros-firewall-list_rule_ordering" "rules" {
   rule_type = "filter"
   rules = [
-    "*A",
-    "*B",
-    "*C",
-    "*9",
+    { ref = "*A" },
+    { ref = "*B" },
+    { ref = "*C" },
+    { ref = "*9" },
   ]
 }
@@ -0,0 +1,24 @@
+# Populate an address list from a threat intelligence feed
+data "http" "feed" {
+  url = "https://www.spamhaus.org/drop/drop.txt"
+}
+
+locals {
+  feed_entries = [
+    for line in split("\n", data.http.feed.response_body) :
+    trimspace(split(";", line)[0]) if trimspace(split(";", line)[0]) != ""
+  ]
+}
+
+resource "routeros-firewall-list_address_list_bulk" "drop" {
+  list      = "spamhaus-drop"
+  addresses = toset(local.feed_entries)
+  comment   = "spamhaus DROP list"
+}
+
+# Temporarily block addresses, extending the block on every apply
+resource "routeros-firewall-list_address_list_bulk" "quarantine" {
+  list      = "quarantine"
+  addresses = ["198.51.100.7", "203.0.113.0/24"]
+  timeout   = "72h"
+}
@@ -0,0 +1,22 @@
+# Custom chain for traffic from the WAN, entered from the forward chain and
+# terminated by a final drop rule
+resource "routeros-firewall-list_chain" "wan_in" {
+  rule_type    = "filter"
+  name         = "wan-in"
+  parent_chain = "forward"
+  jump_properties = {
+    "in-interface-list" = "WAN"
+  }
+  final_action = "drop"
+  comment      = "Traffic from the WAN"
+}
+
+# The jump rule can be positioned like any other rule
+resource "routeros-firewall-list_rule_ordering" "forward" {
+  rule_type = "filter"
+  chain     = "forward"
+  rules = [
+    { ref = "*1" },
+    { ref = routeros-firewall-list_chain.wan_in.jump_rule_id },
+  ]
+}
@@ -0,0 +1,16 @@
+# Order the rules of the input and forward chains of the filter table
+resource "routeros-firewall-list_firewall_layout" "filter" {
+  rule_type = "filter"
+  chains = {
+    input = [
+      "comment:accept established",
+      "comment:accept ssh from lan",
+      "comment:drop all else",
+    ]
+    forward = [
+      "comment:fasttrack",
+      "comment:accept established",
+      "comment:drop invalid",
+    ]
+  }
+}
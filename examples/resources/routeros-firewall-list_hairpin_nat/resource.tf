@@ -0,0 +1,10 @@
+# Forward HTTPS on the public address to an internal web server, which LAN
+# clients also reach via the public address
+resource "routeros-firewall-list_hairpin_nat" "web" {
+  public_address   = "203.0.113.10"
+  port             = "443"
+  internal_address = "192.168.88.10"
+  internal_port    = "8443"
+  lan_subnet       = "192.168.88.0/24"
+  comment          = "web server"
+}
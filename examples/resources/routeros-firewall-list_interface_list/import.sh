@@ -0,0 +1,2 @@
+# Interface lists can be imported using their RouterOS ID
+terraform import routeros-firewall-list_interface_list.trusted '*2000010'
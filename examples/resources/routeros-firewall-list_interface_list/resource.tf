@@ -0,0 +1,7 @@
+# Interface list which can be referenced via `in-interface-list` /
+# `out-interface-list` in firewall rules
+resource "routeros-firewall-list_interface_list" "trusted" {
+  name    = "trusted"
+  comment = "Interfaces facing trusted networks"
+  include = ["LAN"]
+}
@@ -0,0 +1,2 @@
+# Interface list members can be imported using their RouterOS ID
+terraform import routeros-firewall-list_interface_list_member.wg0 '*5'
@@ -0,0 +1,4 @@
+resource "routeros-firewall-list_interface_list_member" "wg0" {
+  interface = "wg0"
+  list      = routeros-firewall-list_interface_list.trusted.name
+}
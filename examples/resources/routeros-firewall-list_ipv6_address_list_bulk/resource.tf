@@ -0,0 +1,14 @@
+# Block IPv6 networks, alongside their IPv4 counterparts in an address list of
+# the same name
+resource "routeros-firewall-list_ipv6_address_list_bulk" "drop" {
+  list      = "blocklist"
+  addresses = ["2001:db8:bad::/48", "2001:db8:1::7"]
+  comment   = "blocked networks"
+}
+
+# Temporarily block addresses, extending the block on every apply
+resource "routeros-firewall-list_ipv6_address_list_bulk" "quarantine" {
+  list      = "quarantine"
+  addresses = ["2001:db8:2::/64"]
+  timeout   = "72h"
+}
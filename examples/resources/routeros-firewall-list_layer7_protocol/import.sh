@@ -0,0 +1,2 @@
+# Layer7 protocols can be imported using their RouterOS ID
+terraform import routeros-firewall-list_layer7_protocol.youtube '*1'
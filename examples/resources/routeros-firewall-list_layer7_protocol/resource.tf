@@ -0,0 +1,7 @@
+# Layer7 protocol which can be referenced via `layer7-protocol` in firewall
+# rules
+resource "routeros-firewall-list_layer7_protocol" "youtube" {
+  name    = "youtube"
+  regexp  = "^.+(youtube.com|googlevideo.com).*$"
+  comment = "Matches YouTube traffic"
+}
@@ -0,0 +1,11 @@
+# Log new SSH connections from the WAN for an hour, in front of the rule
+# which drops everything else
+resource "routeros-firewall-list_log_rule" "debug_ssh" {
+  chain             = "input"
+  log_prefix        = "debug-ssh"
+  protocol          = "tcp"
+  dst_port          = "22"
+  in_interface_list = "WAN"
+  place_before      = "comment:drop all else"
+  ttl               = "1h"
+}
@@ -0,0 +1,2 @@
+# Mangle rules can be imported using their RouterOS ID
+terraform import routeros-firewall-list_mangle_rule.mark_conn '*3'
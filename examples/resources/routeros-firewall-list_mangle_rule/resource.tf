@@ -0,0 +1,26 @@
+# Route traffic of a single host through a secondary WAN link
+resource "routeros-firewall-list_mangle_rule" "mark_conn" {
+  chain               = "prerouting"
+  action              = "mark-connection"
+  src_address         = "192.168.88.10"
+  connection_state    = "new"
+  new_connection_mark = "wan2"
+  comment             = "mark wan2 connections"
+}
+
+resource "routeros-firewall-list_mangle_rule" "mark_route" {
+  chain            = "prerouting"
+  action           = "mark-routing"
+  connection_mark  = "wan2"
+  new_routing_mark = "to-wan2"
+  passthrough      = false
+  comment          = "route wan2 connections"
+}
+
+resource "routeros-firewall-list_rule_ordering" "mangle" {
+  rule_type = "mangle"
+  rules = [
+    { ref = routeros-firewall-list_mangle_rule.mark_conn.id },
+    { ref = routeros-firewall-list_mangle_rule.mark_route.id },
+  ]
+}
@@ -0,0 +1,10 @@
+# Forward SSH arriving on port 2222 of the WAN interfaces to an internal host,
+# accepting the forwarded connections before the catch-all drop rule
+resource "routeros-firewall-list_port_forward" "ssh" {
+  external_port     = "2222"
+  internal_address  = "192.168.88.20"
+  internal_port     = "22"
+  in_interface_list = "WAN"
+  allow_before      = "comment:drop all else"
+  comment           = "ssh to build server"
+}
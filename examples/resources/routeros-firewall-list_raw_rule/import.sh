@@ -0,0 +1,2 @@
+# Raw rules can be imported using their RouterOS ID
+terraform import routeros-firewall-list_raw_rule.drop_blocklist '*1'
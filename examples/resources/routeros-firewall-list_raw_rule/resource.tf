@@ -0,0 +1,16 @@
+# Drop traffic from known bad actors before it reaches connection tracking
+resource "routeros-firewall-list_raw_rule" "drop_blocklist" {
+  chain            = "prerouting"
+  action           = "drop"
+  src_address_list = "blocklist"
+  in_interface     = "ether1"
+  comment          = "drop blocklisted sources"
+}
+
+# Exempt high-volume local traffic from connection tracking
+resource "routeros-firewall-list_raw_rule" "notrack_lan" {
+  chain       = "prerouting"
+  action      = "notrack"
+  src_address = "192.168.88.0/24"
+  dst_address = "192.168.88.0/24"
+}
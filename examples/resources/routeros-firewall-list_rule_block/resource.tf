@@ -0,0 +1,38 @@
+# Rules which are created and kept together in the given order
+resource "routeros-firewall-list_rule_block" "input" {
+  rule_type = "filter"
+  rules = [
+    {
+      chain   = "input"
+      action  = "accept"
+      comment = "allow established"
+      properties = {
+        "connection-state" = "established,related"
+      }
+    },
+    {
+      chain   = "input"
+      action  = "accept"
+      comment = "allow ssh from lan"
+      properties = {
+        "protocol"          = "tcp"
+        "dst-port"          = "22"
+        "in-interface-list" = "LAN"
+      }
+    },
+    {
+      chain   = "input"
+      action  = "drop"
+      comment = "drop everything else"
+    },
+  ]
+}
+
+# The block is arranged relative to other rules by ordering its rules by ID
+resource "routeros-firewall-list_rule_ordering" "input" {
+  rule_type = "filter"
+  rules = concat(
+    [{ ref = "comment:drop invalid" }],
+    [for id in routeros-firewall-list_rule_block.input.rule_ids : { ref = id }],
+  )
+}
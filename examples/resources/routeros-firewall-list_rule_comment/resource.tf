@@ -0,0 +1,18 @@
+# Label a legacy rule, so that it can be referenced by its comment
+resource "routeros-firewall-list_rule_comment" "legacy_drop" {
+  match = {
+    chain       = "forward"
+    action      = "drop"
+    src-address = "10.0.0.0/8"
+  }
+  comment = "legacy: drop private sources"
+}
+
+resource "routeros-firewall-list_rule_ordering" "forward" {
+  rule_type = "filter"
+  chain     = "forward"
+  rules = [
+    { ref = "comment:${routeros-firewall-list_rule_comment.legacy_drop.comment}" },
+    { ref = "comment:allow established" },
+  ]
+}
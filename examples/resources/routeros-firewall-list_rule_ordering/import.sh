@@ -1 +1,5 @@
-# This resource is not importable
+# Unnamed orderings can be imported using their id
+terraform import routeros-firewall-list_rule_ordering.input filter-0123456789abcdef
+
+# Named orderings can be imported using their rule type and name
+terraform import routeros-firewall-list_rule_ordering.input filter:input
@@ -2,9 +2,51 @@
 resource "routeros-firewall-list_rule_ordering" "rules" {
   rule_type = "filter"
   rules = [
-    "*A",
-    "*B",
-    "*C",
-    "*9",
+    { ref = "*A" },
+    { ref = "*B" },
+    { ref = "*C" },
+    { ref = "*9" },
+  ]
+}
+
+# Rules may also be referenced by their comment. All rules of a NAT ordering
+# must belong to the same chain
+resource "routeros-firewall-list_rule_ordering" "nat" {
+  rule_type = "nat"
+  rules = [
+    { ref = "comment:no nat vpn" },
+    { ref = "comment:masquerade lan" },
+  ]
+}
+
+# Any table with movable items can be ordered by its menu path
+resource "routeros-firewall-list_rule_ordering" "ipv6" {
+  rule_type = "/ipv6/firewall/filter"
+  rules = [
+    { ref = "comment:allow icmpv6" },
+    { ref = "comment:drop invalid" },
+  ]
+}
+
+# Rule resources can be referenced directly
+resource "routeros-firewall-list_rule_ordering" "mangle" {
+  rule_type = "mangle"
+  rule_resources = [
+    routeros-firewall-list_mangle_rule.mark_voip,
+    routeros-firewall-list_mangle_rule.mark_bulk,
+  ]
+}
+
+# Orderings can be applied to any of the devices listed in the provider's hosts.
+# Rules which only exist on some of them are marked as optional, so that they
+# are skipped where they are missing
+resource "routeros-firewall-list_rule_ordering" "branch" {
+  for_each  = toset(["branch-a", "branch-b"])
+  host      = each.key
+  rule_type = "filter"
+  rules = [
+    { ref = "comment:allow established" },
+    { ref = "comment:allow vpn", optional = true },
+    { ref = "comment:drop invalid" },
   ]
 }
@@ -0,0 +1,3 @@
+# Rule states can be imported using the rule type and the RouterOS ID of the
+# rule. The current state of the rule is restored on destroy
+terraform import routeros-firewall-list_rule_state.allow_wan 'filter:*1A'
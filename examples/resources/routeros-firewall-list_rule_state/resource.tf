@@ -0,0 +1,6 @@
+# Temporarily disable a block rule of the default configuration. Destroying
+# the resource enables the rule again.
+resource "routeros-firewall-list_rule_state" "allow_wan" {
+  rule     = "comment:defconf: drop all not coming from LAN"
+  disabled = true
+}
@@ -0,0 +1,2 @@
+# Service ports can be imported using their name
+terraform import routeros-firewall-list_service_port.sip sip
@@ -0,0 +1,11 @@
+# Disable the SIP helper, which is known to break many VoIP setups
+resource "routeros-firewall-list_service_port" "sip" {
+  name     = "sip"
+  disabled = true
+}
+
+# Run the FTP helper on a non-standard port
+resource "routeros-firewall-list_service_port" "ftp" {
+  name  = "ftp"
+  ports = "2121"
+}
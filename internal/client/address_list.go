@@ -0,0 +1,113 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IPVersion selects between the IPv4 menus below `/ip` and their IPv6
+// counterparts below `/ipv6`.
+type IPVersion int
+
+const (
+	IPv4 IPVersion = 4
+	IPv6 IPVersion = 6
+)
+
+// AddressListMenu returns the menu path of the address lists of the given IP
+// version.
+func AddressListMenu(v IPVersion) string {
+	if v == IPv6 {
+		return "/ipv6/firewall/address-list"
+	}
+	return "/ip/firewall/address-list"
+}
+
+// AddressListEntry is an entry of `/ip/firewall/address-list` or
+// `/ipv6/firewall/address-list`.
+type AddressListEntry struct {
+	ID           string `json:".id,omitempty"`
+	List         string `json:"list"`
+	Address      string `json:"address"`
+	Timeout      string `json:"timeout,omitempty"`
+	Comment      string `json:"comment"`
+	Disabled     string `json:"disabled"`
+	Dynamic      string `json:"dynamic,omitempty"`
+	CreationTime string `json:"creation-time,omitempty"`
+	// Owner is the workspace which created the entry, if any.
+	Owner string `json:"-"`
+}
+
+// GetAddressList returns all entries, including dynamic ones, of the address
+// list of IP version v with the given name.
+func (c *Client) GetAddressList(ctx context.Context, v IPVersion, list string) ([]AddressListEntry, error) {
+	entries := []AddressListEntry{}
+	query := url.Values{"list": {list}}
+	err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s?%s", AddressListMenu(v), query.Encode()), nil, &entries)
+	for i := range entries {
+		entries[i].Comment, entries[i].Owner = c.untagComment(entries[i].Comment)
+	}
+	return entries, err
+}
+
+// AddAddressListEntries creates the passed entries in the address lists of IP
+// version v and returns them as reported by the device. Unlike `set` and
+// `remove`, the `add` command of RouterOS creates a single object even on
+// devices with bulk support, see supportsBulk, and the only way around it, a
+// script passed to `/execute`, would require quoting the entries' values for
+// the RouterOS scripting language and would not report the IDs of the new
+// entries. Entries are therefore created with as many parallel requests as
+// the client's concurrency limit allows. The result is aligned with entries;
+// if an error is returned, entries which could not be created have an empty
+// ID.
+func (c *Client) AddAddressListEntries(ctx context.Context, v IPVersion, entries []AddressListEntry) ([]AddressListEntry, error) {
+	created := make([]AddressListEntry, len(entries))
+	err := c.forEach(ctx, len(entries), func(ctx context.Context, i int) error {
+		var res AddressListEntry
+		e := entries[i]
+		e.Comment = c.tagComment(e.Comment)
+		if err := c.doJSON(ctx, http.MethodPut, AddressListMenu(v), e, &res); err != nil {
+			return err
+		}
+		res.Comment, res.Owner = c.untagComment(res.Comment)
+		created[i] = res
+		return nil
+	})
+	return created, err
+}
+
+// RemoveAddressListEntries removes all entries of IP version v with the given
+// IDs, using a single request on devices which support it.
+func (c *Client) RemoveAddressListEntries(ctx context.Context, v IPVersion, ids []string) error {
+	return c.removeObjects(ctx, AddressListMenu(v), ids)
+}
+
+// SetAddressListEntries applies props to all entries of IP version v with the
+// given IDs, using a single request on devices which support it.
+func (c *Client) SetAddressListEntries(ctx context.Context, v IPVersion, ids []string, props map[string]string) error {
+	if comment, ok := props["comment"]; ok {
+		props = copyProps(props)
+		props["comment"] = c.tagComment(comment)
+	}
+	return c.setObjects(ctx, AddressListMenu(v), ids, props)
+}
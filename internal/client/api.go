@@ -0,0 +1,121 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// API is the set of operations offered by Client. Consumers should depend on
+// this interface rather than on *Client, so that the device can be replaced
+// by a fake, see the rostest package.
+type API interface {
+	// Version returns the RouterOS version running on the device.
+	Version(ctx context.Context) (Version, error)
+	// GetSystemResource returns the current status of the device.
+	GetSystemResource(ctx context.Context) (SystemResource, error)
+	// GetIdentity returns the identity of the device.
+	GetIdentity(ctx context.Context) (string, error)
+	// CheckPolicies verifies that the configured user may manage the
+	// firewall.
+	CheckPolicies(ctx context.Context) error
+	// SkipReadOnError reports whether reads may fall back to the prior state
+	// if the device is unreachable.
+	SkipReadOnError() bool
+	// LoadIdentity fetches the identity of the device to attach it to errors,
+	// if enabled.
+	LoadIdentity(ctx context.Context) error
+	// ReportOrderingDrift reports whether orderings which drifted report a
+	// summary of the drift while refreshing.
+	ReportOrderingDrift() bool
+
+	// Rule tables and their ordering.
+	GetRulesOfType(ctx context.Context, ruleType string) ([]FirewallRule, error)
+	GetRulesOfChain(ctx context.Context, ruleType, chain string) ([]FirewallRule, error)
+	GetRule(ctx context.Context, ruleType, id string) (FirewallRule, error)
+	GetOrderingFrom(ctx context.Context, ruleType string, start FirewallRule, length int) ([]FirewallRule, error)
+	ResolveRuleReference(ctx context.Context, ruleType, ref string) (FirewallRule, error)
+	FindRuleByFingerprint(ctx context.Context, ruleType, fingerprint string) (FirewallRule, error)
+	RuleOrderExists(ctx context.Context, ruleType string, seq []FirewallRule, opts OrderingOpts) (bool, error)
+	MoveRules(ctx context.Context, ruleType string, ids []string, target Position) error
+	OrderRules(ctx context.Context, ruleType string, ids []string, opts OrderingOpts) (int, error)
+	OrderChains(ctx context.Context, ruleType string, chains map[string][]string, opts OrderingOpts) (int, error)
+	WaitForRuleOrder(ctx context.Context, ruleType string, ids []string, opts OrderingOpts, timeout time.Duration) (bool, error)
+	TableChanged(ctx context.Context, ruleType, chain string) (bool, error)
+
+	// Individual rules.
+	GetRuleProperties(ctx context.Context, ruleType, id string) (map[string]string, error)
+	ListRuleProperties(ctx context.Context, ruleType string) ([]map[string]string, error)
+	ManagedRuleIDs(ctx context.Context, ruleType string) (map[string]bool, error)
+	GetTables(ctx context.Context, ruleTypes []string) (map[string]Table, error)
+	FindRuleByComment(ctx context.Context, ruleType, comment string) (map[string]string, error)
+	FindRuleByProperties(ctx context.Context, ruleType string, match map[string]string) (map[string]string, error)
+	QueryRules(ctx context.Context, ruleType string, query []string) ([]map[string]string, error)
+	CreateRule(ctx context.Context, ruleType string, props map[string]string) (map[string]string, error)
+	UpdateRule(ctx context.Context, ruleType, id string, props map[string]string) (map[string]string, error)
+	DeleteRule(ctx context.Context, ruleType, id string) error
+	SetRules(ctx context.Context, ruleType string, ids []string, props map[string]string) error
+	RemoveRules(ctx context.Context, ruleType string, ids []string) error
+
+	// Address lists.
+	GetAddressList(ctx context.Context, v IPVersion, list string) ([]AddressListEntry, error)
+	QueryAddressList(ctx context.Context, v IPVersion, list string, query []string) ([]AddressListEntry, error)
+	AddAddressListEntries(ctx context.Context, v IPVersion, entries []AddressListEntry) ([]AddressListEntry, error)
+	RemoveAddressListEntries(ctx context.Context, v IPVersion, ids []string) error
+	SetAddressListEntries(ctx context.Context, v IPVersion, ids []string, props map[string]string) error
+
+	// Interfaces and interface lists.
+	GetInterfaces(ctx context.Context) ([]Interface, error)
+	GetInterfaceLists(ctx context.Context) ([]InterfaceList, error)
+	GetInterfaceListMembers(ctx context.Context, list string) ([]InterfaceListMember, error)
+	GetInterfaceList(ctx context.Context, id string) (InterfaceList, error)
+	CreateInterfaceList(ctx context.Context, l InterfaceList) (InterfaceList, error)
+	UpdateInterfaceList(ctx context.Context, l InterfaceList) (InterfaceList, error)
+	DeleteInterfaceList(ctx context.Context, id string) error
+	GetInterfaceListMember(ctx context.Context, id string) (InterfaceListMember, error)
+	CreateInterfaceListMember(ctx context.Context, m InterfaceListMember) (InterfaceListMember, error)
+	UpdateInterfaceListMember(ctx context.Context, m InterfaceListMember) (InterfaceListMember, error)
+	DeleteInterfaceListMember(ctx context.Context, id string) error
+
+	// Layer7 protocols.
+	GetLayer7Protocol(ctx context.Context, id string) (Layer7Protocol, error)
+	CreateLayer7Protocol(ctx context.Context, l Layer7Protocol) (Layer7Protocol, error)
+	UpdateLayer7Protocol(ctx context.Context, l Layer7Protocol) (Layer7Protocol, error)
+	DeleteLayer7Protocol(ctx context.Context, id string) error
+
+	// Service ports.
+	GetServicePort(ctx context.Context, name string) (ServicePort, error)
+	UpdateServicePort(ctx context.Context, s ServicePort) (ServicePort, error)
+
+	// System log.
+	GetLog(ctx context.Context) ([]LogEntry, error)
+	FindRuleMoves(ctx context.Context, ruleType string) ([]RuleMove, error)
+
+	// Scheduler.
+	GetSchedulerEntry(ctx context.Context, id string) (SchedulerEntry, error)
+	CreateSchedulerEntry(ctx context.Context, e SchedulerEntry) (SchedulerEntry, error)
+	DeleteSchedulerEntry(ctx context.Context, id string) error
+
+	// Connection tracking.
+	GetConnections(ctx context.Context, filter ConnectionFilter) ([]Connection, error)
+}
+
+// Ensure Client fully implements API.
+var _ API = &Client{}
@@ -0,0 +1,159 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the number of requests which are sent in parallel if
+// no limit is configured.
+const DefaultConcurrency = 4
+
+// forEach calls fn for every index in [0, n), running at most as many calls in
+// parallel as the client's concurrency limit allows. The first error cancels
+// the context passed to all calls which have not yet started and is returned.
+func (c *Client) forEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, c.concurrency)
+	)
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	if firstErr == nil {
+		// the parent context may have been canceled
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+// supportsBulk reports whether the device accepts multiple IDs per `set` and
+// `remove` command via the REST API, which is the case starting with RouterOS
+// 7.16. If the version cannot be determined, the per-object fallback is used.
+func (c *Client) supportsBulk(ctx context.Context) bool {
+	v, err := c.Version(ctx)
+	return err == nil && v.AtLeast(7, 16)
+}
+
+// removeObjects deletes all objects with the given IDs from the menu at path.
+// Objects which no longer exist are skipped when falling back to per-object
+// deletion.
+func (c *Client) removeObjects(ctx context.Context, path string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := validateIDs(ids); err != nil {
+		return err
+	}
+
+	if c.supportsBulk(ctx) {
+		payload := map[string]string{"numbers": strings.Join(ids, ",")}
+		return c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/remove", path), payload, nil)
+	}
+
+	return c.forEach(ctx, len(ids), func(ctx context.Context, i int) error {
+		p, err := objectPath(path, ids[i])
+		if err != nil {
+			return err
+		}
+		err = c.doJSON(ctx, http.MethodDelete, p, nil, nil)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// setObjects applies the same set of properties to all objects with the given
+// IDs in the menu at path.
+func (c *Client) setObjects(ctx context.Context, path string, ids []string, props map[string]string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := validateIDs(ids); err != nil {
+		return err
+	}
+
+	if c.supportsBulk(ctx) {
+		payload := map[string]string{"numbers": strings.Join(ids, ",")}
+		for k, v := range props {
+			payload[k] = v
+		}
+		return c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/set", path), payload, nil)
+	}
+
+	return c.forEach(ctx, len(ids), func(ctx context.Context, i int) error {
+		p, err := objectPath(path, ids[i])
+		if err != nil {
+			return err
+		}
+		return c.doJSON(ctx, http.MethodPatch, p, props, nil)
+	})
+}
+
+// SetRules applies props to all rules of the given type with the given IDs.
+func (c *Client) SetRules(ctx context.Context, ruleType string, ids []string, props map[string]string) error {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return err
+	}
+	return c.setObjects(ctx, p, ids, props)
+}
+
+// RemoveRules removes all rules of the given type with the given IDs.
+func (c *Client) RemoveRules(ctx context.Context, ruleType string, ids []string) error {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return err
+	}
+	return c.removeObjects(ctx, p, ids)
+}
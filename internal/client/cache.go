@@ -0,0 +1,86 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// ruleCacheTTL bounds how long a fetched rule table is reused. It is meant to
+// cover the requests of a single Terraform operation, not to persist across
+// operations.
+const ruleCacheTTL = 5 * time.Second
+
+type cachedRules struct {
+	rules   []FirewallRule
+	fetched time.Time
+}
+
+// ruleCache is a short-lived cache of rule tables keyed by menu path, or by
+// request path for tables restricted to a single chain. It is safe for
+// concurrent use and is invalidated entirely by any write request. Queries
+// sent as a POST request to the `print` command do not count as writes.
+type ruleCache struct {
+	mu     sync.Mutex
+	tables map[string]cachedRules
+}
+
+// get returns a copy of the cached table, if present and not expired.
+func (rc *ruleCache) get(key string) ([]FirewallRule, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	cached, ok := rc.tables[key]
+	if !ok || time.Since(cached.fetched) > ruleCacheTTL {
+		return nil, false
+	}
+	return linkRules(cached.rules), true
+}
+
+func (rc *ruleCache) put(key string, rules []FirewallRule) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.tables == nil {
+		rc.tables = map[string]cachedRules{}
+	}
+	rc.tables[key] = cachedRules{rules: linkRules(rules), fetched: time.Now()}
+}
+
+func (rc *ruleCache) invalidate() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.tables = nil
+}
+
+// linkRules returns a copy of rules with each rule's Next pointing to its
+// successor within the copy.
+func linkRules(rules []FirewallRule) []FirewallRule {
+	linked := make([]FirewallRule, len(rules))
+	copy(linked, rules)
+	for i := range linked {
+		linked[i].Next = nil
+		if i+1 < len(linked) {
+			linked[i].Next = &linked[i+1]
+		}
+	}
+	return linked
+}
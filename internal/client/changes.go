@@ -0,0 +1,100 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// orderHashes records a hash of the ordering of the rule tables as they were
+// last read from the device, keyed by menu path and chain. Unlike the rule
+// cache, it survives write requests, as it is only used to tell whether a
+// table has changed since it was last read.
+type orderHashes struct {
+	mu     sync.Mutex
+	hashes map[string]string
+}
+
+func (h *orderHashes) get(key string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hash, ok := h.hashes[key]
+	return hash, ok
+}
+
+func (h *orderHashes) put(key, hash string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.hashes == nil {
+		h.hashes = map[string]string{}
+	}
+	h.hashes[key] = hash
+}
+
+// orderHashKey returns the key of the table at path p, restricted to chain if
+// non-empty, in orderHashes.
+func orderHashKey(p, chain string) string {
+	return p + "#" + chain
+}
+
+// orderHash returns a hash of the IDs of rules in the order they are given.
+func orderHash(rules []FirewallRule) string {
+	h := sha256.New()
+	for _, rule := range rules {
+		fmt.Fprintf(h, "%s\n", rule.ID)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// TableChanged reports whether the ordering of the rules of the given type,
+// restricted to chain if non-empty, has changed since the table was last read
+// by GetRulesOfType or GetRulesOfChain. Only the rule IDs are fetched for the
+// comparison, so polling loops can use it to skip reading and processing the
+// full table while it is unchanged. Changes to the properties of rules are
+// not detected. A table which has not been read yet is always reported as
+// changed.
+func (c *Client) TableChanged(ctx context.Context, ruleType, chain string) (bool, error) {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return false, err
+	}
+
+	last, ok := c.orderHashes.get(orderHashKey(p, chain))
+	if !ok {
+		return true, nil
+	}
+
+	query := url.Values{".proplist": {".id"}}
+	if chain != "" {
+		query.Set("chain", chain)
+	}
+	rules := []FirewallRule{}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s?%s", p, query.Encode()), nil, &rules); err != nil {
+		return false, err
+	}
+	return orderHash(rules) != last, nil
+}
@@ -0,0 +1,208 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// NormalizeCIDRs canonicalizes the given addresses and networks the way
+// RouterOS reports them in address lists: host routes are written as plain
+// addresses, host bits are cleared and duplicates are removed. Networks which
+// are contained in other networks are dropped and adjacent networks are
+// aggregated into their common supernet. The result is sorted.
+func NormalizeCIDRs(cidrs []string) ([]string, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, s := range cidrs {
+		p, err := parsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p)
+	}
+
+	for merged := true; merged; {
+		prefixes = dropContained(prefixes)
+		prefixes, merged = mergeSiblings(prefixes)
+	}
+
+	normalized := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		if p.IsSingleIP() {
+			normalized = append(normalized, p.Addr().String())
+		} else {
+			normalized = append(normalized, p.String())
+		}
+	}
+	return normalized, nil
+}
+
+// CanonicalAddress returns the canonical form of an address as RouterOS
+// reports it in address matchers: host routes are written as plain addresses
+// and host bits of networks are cleared, e.g. `10.0.0.1/32` becomes
+// `10.0.0.1` and `10.0.0.5/24` becomes `10.0.0.0/24`. Address ranges such as
+// `10.0.0.1-10.0.0.9` and a leading `!` negating the match are preserved.
+func CanonicalAddress(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	negated := strings.HasPrefix(s, "!")
+	s = strings.TrimPrefix(s, "!")
+
+	var canonical string
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		a, errFrom := netip.ParseAddr(strings.TrimSpace(from))
+		b, errTo := netip.ParseAddr(strings.TrimSpace(to))
+		if errFrom != nil || errTo != nil || a.Unmap().Is4() != b.Unmap().Is4() {
+			return "", fmt.Errorf("invalid address range '%s'", s)
+		}
+		canonical = a.Unmap().String() + "-" + b.Unmap().String()
+	} else {
+		p, err := parsePrefix(s)
+		if err != nil {
+			return "", err
+		}
+		canonical = p.String()
+		if p.IsSingleIP() {
+			canonical = p.Addr().String()
+		}
+	}
+
+	if negated {
+		return "!" + canonical, nil
+	}
+	return canonical, nil
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid address '%s', expected an IP address or CIDR", s)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network '%s', expected an IP address or CIDR", s)
+	}
+	return netip.PrefixFrom(p.Addr().Unmap(), p.Bits()).Masked(), nil
+}
+
+// dropContained sorts prefixes and removes all prefixes which are equal to or
+// contained in a preceding one.
+func dropContained(prefixes []netip.Prefix) []netip.Prefix {
+	sort.Slice(prefixes, func(i, j int) bool {
+		if c := prefixes[i].Addr().Compare(prefixes[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return prefixes[i].Bits() < prefixes[j].Bits()
+	})
+
+	kept := prefixes[:0]
+	for _, p := range prefixes {
+		if n := len(kept); n > 0 && kept[n-1].Bits() <= p.Bits() && kept[n-1].Contains(p.Addr()) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// mergeSiblings replaces each pair of adjacent halves of the same supernet by
+// the supernet itself. prefixes must be sorted and free of overlaps.
+func mergeSiblings(prefixes []netip.Prefix) ([]netip.Prefix, bool) {
+	merged := false
+	result := make([]netip.Prefix, 0, len(prefixes))
+	for i := 0; i < len(prefixes); i++ {
+		p := prefixes[i]
+		if i+1 < len(prefixes) && p.Bits() > 0 && p.Bits() == prefixes[i+1].Bits() {
+			parent := netip.PrefixFrom(p.Addr(), p.Bits()-1).Masked()
+			if parent.Addr() == p.Addr() && parent.Contains(prefixes[i+1].Addr()) {
+				result = append(result, parent)
+				merged = true
+				i++
+				continue
+			}
+		}
+		result = append(result, p)
+	}
+	return result, merged
+}
+
+// RangeToCIDRs returns the minimal list of networks covering exactly the
+// addresses of the range s, such as `10.0.0.10-10.0.0.20`, in ascending
+// order. Networks are always written in CIDR notation, including host routes
+// such as `10.0.0.20/32`. A single address or network is accepted as well,
+// and returned as the only network.
+func RangeToCIDRs(s string) ([]string, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		p, err := parsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		return []string{p.String()}, nil
+	}
+
+	a, errFrom := netip.ParseAddr(strings.TrimSpace(from))
+	b, errTo := netip.ParseAddr(strings.TrimSpace(to))
+	if errFrom != nil || errTo != nil {
+		return nil, fmt.Errorf("invalid address range '%s'", s)
+	}
+	a, b = a.Unmap(), b.Unmap()
+	if a.Is4() != b.Is4() {
+		return nil, fmt.Errorf("invalid address range '%s', addresses must be of the same IP version", s)
+	}
+	if b.Less(a) {
+		return nil, fmt.Errorf("invalid address range '%s', the first address must not be greater than the last", s)
+	}
+
+	var cidrs []string
+	for {
+		// the largest network starting at a which does not extend beyond b
+		var p netip.Prefix
+		for bits := 0; bits <= a.BitLen(); bits++ {
+			p = netip.PrefixFrom(a, bits).Masked()
+			if p.Addr() == a && !b.Less(lastAddr(p)) {
+				break
+			}
+		}
+		cidrs = append(cidrs, p.String())
+
+		last := lastAddr(p)
+		if last == b || !last.Next().IsValid() {
+			return cidrs, nil
+		}
+		a = last.Next()
+	}
+}
+
+// lastAddr returns the last address of the network p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
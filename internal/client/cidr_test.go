@@ -0,0 +1,312 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+// prefixesOf parses the given networks without normalizing them.
+func prefixesOf(t *testing.T, cidrs ...string) []netip.Prefix {
+	t.Helper()
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, s := range cidrs {
+		prefixes = append(prefixes, netip.MustParsePrefix(s))
+	}
+	return prefixes
+}
+
+func TestNormalizeCIDRs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidrs   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			cidrs: []string{},
+			want:  []string{},
+		},
+		{
+			name:  "host routes as plain addresses",
+			cidrs: []string{"10.0.0.1/32", "2001:db8::1/128"},
+			want:  []string{"10.0.0.1", "2001:db8::1"},
+		},
+		{
+			name:  "host bits are cleared",
+			cidrs: []string{"10.0.0.5/24", "2001:db8::5/64"},
+			want:  []string{"10.0.0.0/24", "2001:db8::/64"},
+		},
+		{
+			name:  "IPv4-mapped IPv6 addresses",
+			cidrs: []string{"::ffff:10.0.0.1", "::ffff:192.168.1.7/24"},
+			want:  []string{"10.0.0.1", "192.168.1.0/24"},
+		},
+		{
+			name:  "duplicates",
+			cidrs: []string{"10.0.0.1", "10.0.0.1/32", " 10.0.0.1 "},
+			want:  []string{"10.0.0.1"},
+		},
+		{
+			name:  "contained networks are dropped",
+			cidrs: []string{"10.0.1.0/24", "10.0.0.0/16", "10.0.200.7", "10.1.0.0/24"},
+			want:  []string{"10.0.0.0/16", "10.1.0.0/24"},
+		},
+		{
+			name:  "siblings are merged",
+			cidrs: []string{"10.0.1.0/24", "10.0.0.0/24"},
+			want:  []string{"10.0.0.0/23"},
+		},
+		{
+			name:  "adjacent networks of different parents are kept",
+			cidrs: []string{"10.0.1.0/24", "10.0.2.0/24"},
+			want:  []string{"10.0.1.0/24", "10.0.2.0/24"},
+		},
+		{
+			name:  "merges cascade over several levels",
+			cidrs: []string{"10.0.0.3", "10.0.0.0/31", "10.0.0.6/31", "10.0.0.2", "10.0.0.4/31"},
+			want:  []string{"10.0.0.0/29"},
+		},
+		{
+			name:  "merges cascade into contained networks",
+			cidrs: []string{"10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/26", "10.0.0.77", "10.0.1.0/24"},
+			want:  []string{"10.0.0.0/23"},
+		},
+		{
+			name:  "mixed address families",
+			cidrs: []string{"2001:db8::/33", "10.0.0.0/9", "2001:db8:8000::/33", "10.128.0.0/9", "0.0.0.0/0"},
+			want:  []string{"0.0.0.0/0", "2001:db8::/32"},
+		},
+		{
+			name:    "invalid address",
+			cidrs:   []string{"10.0.0.1", "10.0.0.256"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid network",
+			cidrs:   []string{"10.0.0.0/33"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCIDRs(tt.cidrs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeCIDRs(%v) error = %v, wantErr %v", tt.cidrs, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeCIDRs(%v) = %v, want %v", tt.cidrs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropContained(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     []string
+	}{
+		{
+			name:     "sorted by address and length",
+			prefixes: []string{"10.0.2.0/24", "10.0.1.0/24", "2001:db8::/32", "10.0.0.0/24"},
+			want:     []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "2001:db8::/32"},
+		},
+		{
+			name:     "equal networks",
+			prefixes: []string{"10.0.0.0/24", "10.0.0.0/24"},
+			want:     []string{"10.0.0.0/24"},
+		},
+		{
+			name:     "contained after container",
+			prefixes: []string{"10.0.0.128/25", "10.0.0.0/16", "10.0.255.255/32"},
+			want:     []string{"10.0.0.0/16"},
+		},
+		{
+			name:     "same address, longer prefix",
+			prefixes: []string{"10.0.0.0/24", "10.0.0.0/8"},
+			want:     []string{"10.0.0.0/8"},
+		},
+		{
+			name:     "families do not contain each other",
+			prefixes: []string{"::/0", "0.0.0.0/0", "10.0.0.0/8"},
+			want:     []string{"0.0.0.0/0", "::/0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dropContained(prefixesOf(t, tt.prefixes...))
+			if want := prefixesOf(t, tt.want...); !reflect.DeepEqual(got, want) {
+				t.Errorf("dropContained(%v) = %v, want %v", tt.prefixes, got, want)
+			}
+		})
+	}
+}
+
+func TestMergeSiblings(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefixes   []string
+		want       []string
+		wantMerged bool
+	}{
+		{
+			name:       "siblings",
+			prefixes:   []string{"10.0.0.0/25", "10.0.0.128/25"},
+			want:       []string{"10.0.0.0/24"},
+			wantMerged: true,
+		},
+		{
+			name:     "upper half of one and lower half of the next parent",
+			prefixes: []string{"10.0.0.128/25", "10.0.1.0/25"},
+			want:     []string{"10.0.0.128/25", "10.0.1.0/25"},
+		},
+		{
+			name:     "different lengths",
+			prefixes: []string{"10.0.0.0/25", "10.0.0.128/26"},
+			want:     []string{"10.0.0.0/25", "10.0.0.128/26"},
+		},
+		{
+			name:       "a single level per call",
+			prefixes:   []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"},
+			want:       []string{"10.0.0.0/25", "10.0.0.128/25"},
+			wantMerged: true,
+		},
+		{
+			name:     "whole address space is not merged further",
+			prefixes: []string{"0.0.0.0/0", "::/0"},
+			want:     []string{"0.0.0.0/0", "::/0"},
+		},
+		{
+			name:       "IPv6 siblings",
+			prefixes:   []string{"10.0.0.0/24", "2001:db8::/33", "2001:db8:8000::/33"},
+			want:       []string{"10.0.0.0/24", "2001:db8::/32"},
+			wantMerged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, merged := mergeSiblings(prefixesOf(t, tt.prefixes...))
+			if want := prefixesOf(t, tt.want...); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergeSiblings(%v) = %v, want %v", tt.prefixes, got, want)
+			}
+			if merged != tt.wantMerged {
+				t.Errorf("mergeSiblings(%v) merged = %v, want %v", tt.prefixes, merged, tt.wantMerged)
+			}
+		})
+	}
+}
+
+func TestRangeToCIDRs(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "single address",
+			s:    "10.0.0.1",
+			want: []string{"10.0.0.1/32"},
+		},
+		{
+			name: "single address as range",
+			s:    "10.0.0.1-10.0.0.1",
+			want: []string{"10.0.0.1/32"},
+		},
+		{
+			name: "network",
+			s:    "10.0.0.5/24",
+			want: []string{"10.0.0.0/24"},
+		},
+		{
+			name: "aligned range",
+			s:    "10.0.0.0-10.0.0.255",
+			want: []string{"10.0.0.0/24"},
+		},
+		{
+			name: "unaligned range",
+			s:    "10.0.0.10 - 10.0.0.20",
+			want: []string{"10.0.0.10/31", "10.0.0.12/30", "10.0.0.16/30", "10.0.0.20/32"},
+		},
+		{
+			name: "unaligned range across networks",
+			s:    "10.0.0.255-10.0.2.0",
+			want: []string{"10.0.0.255/32", "10.0.1.0/24", "10.0.2.0/32"},
+		},
+		{
+			name: "range ending at the last IPv4 address",
+			s:    "255.255.255.254-255.255.255.255",
+			want: []string{"255.255.255.254/31"},
+		},
+		{
+			name: "whole IPv4 address space",
+			s:    "0.0.0.0-255.255.255.255",
+			want: []string{"0.0.0.0/0"},
+		},
+		{
+			name: "range ending at the last IPv6 address",
+			s:    "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffd-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+			want: []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffd/128", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127"},
+		},
+		{
+			name: "IPv6 range",
+			s:    "2001:db8::1-2001:db8::4",
+			want: []string{"2001:db8::1/128", "2001:db8::2/127", "2001:db8::4/128"},
+		},
+		{
+			name: "IPv4-mapped IPv6 addresses",
+			s:    "::ffff:10.0.0.0-10.0.0.3",
+			want: []string{"10.0.0.0/30"},
+		},
+		{
+			name:    "reversed range",
+			s:       "10.0.0.20-10.0.0.10",
+			wantErr: true,
+		},
+		{
+			name:    "mixed address families",
+			s:       "10.0.0.1-2001:db8::1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid address",
+			s:       "10.0.0.1-10.0.0.300",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RangeToCIDRs(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RangeToCIDRs(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RangeToCIDRs(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
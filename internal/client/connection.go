@@ -0,0 +1,103 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Connection is an entry of the connection tracking table
+// `/ip/firewall/connection`. Addresses include the port for protocols which
+// have one, e.g. `192.168.88.10:51234`.
+type Connection struct {
+	ID              string `json:".id"`
+	Protocol        string `json:"protocol"`
+	SrcAddress      string `json:"src-address"`
+	DstAddress      string `json:"dst-address"`
+	ReplySrcAddress string `json:"reply-src-address"`
+	ReplyDstAddress string `json:"reply-dst-address"`
+	TCPState        string `json:"tcp-state"`
+	Timeout         string `json:"timeout"`
+	SrcNAT          string `json:"srcnat"`
+	DstNAT          string `json:"dstnat"`
+	Assured         string `json:"assured"`
+	ConnectionMark  string `json:"connection-mark"`
+	OrigBytes       string `json:"orig-bytes"`
+	ReplBytes       string `json:"repl-bytes"`
+	OrigPackets     string `json:"orig-packets"`
+	ReplPackets     string `json:"repl-packets"`
+}
+
+// ConnectionFilter restricts which connections are returned by
+// GetConnections. Empty fields match any connection. Addresses match either
+// exactly or by their host part, i.e. `192.168.88.10` matches a connection
+// from `192.168.88.10:51234`.
+type ConnectionFilter struct {
+	SrcAddress string
+	DstAddress string
+	Protocol   string
+	TCPState   string
+}
+
+// GetConnections returns all tracked connections matching filter. Protocol and
+// TCP state are filtered by the device, addresses by the client since RouterOS
+// cannot query them independently of the port.
+func (c *Client) GetConnections(ctx context.Context, filter ConnectionFilter) ([]Connection, error) {
+	conns := []Connection{}
+
+	query := url.Values{}
+	if filter.Protocol != "" {
+		query.Set("protocol", filter.Protocol)
+	}
+	if filter.TCPState != "" {
+		query.Set("tcp-state", filter.TCPState)
+	}
+	p := "/ip/firewall/connection"
+	if len(query) > 0 {
+		p = fmt.Sprintf("%s?%s", p, query.Encode())
+	}
+
+	if err := c.doJSON(ctx, http.MethodGet, p, nil, &conns); err != nil {
+		return conns, err
+	}
+
+	matching := conns[:0]
+	for _, conn := range conns {
+		if matchesAddress(conn.SrcAddress, filter.SrcAddress) && matchesAddress(conn.DstAddress, filter.DstAddress) {
+			matching = append(matching, conn)
+		}
+	}
+	return matching, nil
+}
+
+// matchesAddress reports whether addr, optionally suffixed with a port, matches
+// want. An empty want matches everything.
+func matchesAddress(addr, want string) bool {
+	if want == "" || addr == want {
+		return true
+	}
+	if i := strings.LastIndex(addr, ":"); i != -1 {
+		return addr[:i] == want
+	}
+	return false
+}
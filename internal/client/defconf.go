@@ -0,0 +1,116 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefconfCommentPrefix starts the comments of the rules of the RouterOS
+// default configuration, e.g. `defconf: drop all not coming from LAN`.
+const DefconfCommentPrefix = "defconf:"
+
+// IsDefconf reports whether rule is part of the default configuration.
+func IsDefconf(rule FirewallRule) bool {
+	return strings.HasPrefix(rule.Comment, DefconfCommentPrefix)
+}
+
+// DefconfMoveError is returned if a move would move a rule of the default
+// configuration, or place another rule of the same chain in front of it. The
+// default rules protect the device, e.g. by dropping everything arriving from
+// the WAN, and an ordering which pushes them out of effect is rarely wanted.
+type DefconfMoveError struct {
+	RuleType string
+	// Moved lists the default rules which would be moved themselves.
+	Moved []string
+	// Demoted lists the default rules which other rules would be moved in
+	// front of.
+	Demoted []string
+}
+
+func (e *DefconfMoveError) Error() string {
+	var parts []string
+	if len(e.Moved) > 0 {
+		parts = append(parts, fmt.Sprintf("move the default %s rule(s) [%s]", e.RuleType, strings.Join(e.Moved, ", ")))
+	}
+	if len(e.Demoted) > 0 {
+		parts = append(parts, fmt.Sprintf("place rules in front of the default %s rule(s) [%s]", e.RuleType, strings.Join(e.Demoted, ", ")))
+	}
+	return fmt.Sprintf("refusing to %s, as rules with a comment starting with '%s' belong to the default configuration. "+
+		"Set 'allow_defconf_moves' in the provider configuration to override this check", strings.Join(parts, " and "), DefconfCommentPrefix)
+}
+
+// checkDefconfMove returns a *DefconfMoveError if moving the rules with the
+// given IDs in front of the rule with the ID destination would move or demote
+// a rule of the default configuration, unless the client allows it. A
+// destination which does not exist denotes the end of the table. rules is the
+// current table of ruleType, it is read from the device if nil.
+func (c *Client) checkDefconfMove(ctx context.Context, ruleType string, rules []FirewallRule, ids []string, destination string) error {
+	if c.allowDefconfMoves {
+		return nil
+	}
+
+	if rules == nil {
+		var err error
+		if rules, err = c.GetRulesOfType(ctx, ruleType); err != nil {
+			return err
+		}
+	}
+
+	moved := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		moved[id] = true
+	}
+
+	insertAt := len(rules)
+	for i, rule := range rules {
+		if rule.ID == destination {
+			insertAt = i
+			break
+		}
+	}
+
+	e := &DefconfMoveError{RuleType: ruleType}
+	for i, rule := range rules {
+		if !IsDefconf(rule) {
+			continue
+		}
+		if moved[rule.ID] {
+			e.Moved = append(e.Moved, rule.ID)
+			continue
+		}
+		if i < insertAt {
+			continue
+		}
+		// a moved rule of the same chain currently behind the default rule
+		// ends up in front of it
+		for _, other := range rules[i+1:] {
+			if moved[other.ID] && other.Chain == rule.Chain {
+				e.Demoted = append(e.Demoted, rule.ID)
+				break
+			}
+		}
+	}
+
+	if len(e.Moved) > 0 || len(e.Demoted) > 0 {
+		return e
+	}
+	return nil
+}
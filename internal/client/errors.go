@@ -0,0 +1,145 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrorCategory classifies errors returned by the client by their most
+// likely cause, so that they can be reported along with specific advice on
+// how to resolve them.
+type ErrorCategory int
+
+const (
+	// ErrorOther is any error which does not fall into another category.
+	ErrorOther ErrorCategory = iota
+	// ErrorAuth means that the device rejected the credentials.
+	ErrorAuth
+	// ErrorPermission means that the user lacks the policies required for
+	// the request.
+	ErrorPermission
+	// ErrorTLS means that the certificate of the device was not accepted.
+	ErrorTLS
+	// ErrorAPIUnavailable means that the request did not reach the REST API,
+	// e.g. because it was answered by the plain `www` service.
+	ErrorAPIUnavailable
+	// ErrorUnreachable means that no connection to the device could be
+	// established.
+	ErrorUnreachable
+	// ErrorTimeout means that the device did not respond in time.
+	ErrorTimeout
+	// ErrorOverloaded means that the device kept rejecting requests as it is
+	// rate limiting or too busy.
+	ErrorOverloaded
+	// ErrorNotFound means that a requested object does not exist.
+	ErrorNotFound
+	// ErrorConflict means that the request conflicts with the state of the
+	// device, e.g. because an object of the same name exists already or
+	// rules are moved concurrently.
+	ErrorConflict
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case ErrorAuth:
+		return "auth"
+	case ErrorPermission:
+		return "permission"
+	case ErrorTLS:
+		return "tls"
+	case ErrorAPIUnavailable:
+		return "api-unavailable"
+	case ErrorUnreachable:
+		return "unreachable"
+	case ErrorTimeout:
+		return "timeout"
+	case ErrorOverloaded:
+		return "overloaded"
+	case ErrorNotFound:
+		return "not-found"
+	case ErrorConflict:
+		return "conflict"
+	}
+	return "other"
+}
+
+// Categorize returns the category of err.
+func Categorize(err error) ErrorCategory {
+	var (
+		apiErr      *APIError
+		nonJSON     *NonJSONResponseError
+		fingerprint *FingerprintError
+		policies    *MissingPoliciesError
+		unknownCA   x509.UnknownAuthorityError
+		hostname    x509.HostnameError
+		invalidCert x509.CertificateInvalidError
+		ordering    *OrderingError
+		netErr      net.Error
+		dnsErr      *net.DNSError
+		opErr       *net.OpError
+	)
+
+	switch {
+	case err == nil:
+		return ErrorOther
+	case errors.As(err, &apiErr):
+		return categorizeAPIError(apiErr)
+	case errors.As(err, &policies):
+		return ErrorPermission
+	case errors.As(err, &nonJSON):
+		return ErrorAPIUnavailable
+	case errors.As(err, &fingerprint), errors.As(err, &unknownCA), errors.As(err, &hostname), errors.As(err, &invalidCert):
+		return ErrorTLS
+	case errors.Is(err, ErrRuleNotFound):
+		return ErrorNotFound
+	case errors.As(err, &ordering):
+		return ErrorConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.As(err, &dnsErr), errors.As(err, &opErr):
+		return ErrorUnreachable
+	}
+	return ErrorOther
+}
+
+// categorizeAPIError returns the category of an error response of the REST
+// API. RouterOS reports most failures as `400 Bad Request`, so the detail is
+// taken into account as well.
+func categorizeAPIError(e *APIError) ErrorCategory {
+	detail := strings.ToLower(e.Detail)
+	switch {
+	case e.Status == http.StatusUnauthorized:
+		return ErrorAuth
+	case e.Status == http.StatusForbidden, strings.Contains(detail, "not enough permissions"):
+		return ErrorPermission
+	case e.Status == http.StatusTooManyRequests, e.Status == http.StatusServiceUnavailable:
+		return ErrorOverloaded
+	case e.Status == http.StatusNotFound, strings.Contains(detail, "no such item"):
+		return ErrorNotFound
+	case e.Status == http.StatusConflict, strings.Contains(detail, "already have"), strings.Contains(detail, "already exists"):
+		return ErrorConflict
+	}
+	return ErrorOther
+}
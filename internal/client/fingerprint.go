@@ -0,0 +1,103 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// volatileProperties are rule properties which change without the rule being
+// modified, or which differ between otherwise identical rules, and are thus
+// excluded from fingerprints.
+var volatileProperties = map[string]bool{
+	".id":      true,
+	".nextid":  true,
+	"bytes":    true,
+	"packets":  true,
+	"dynamic":  true,
+	"invalid":  true,
+	"disabled": true,
+}
+
+// RuleFingerprint returns a hash of the properties of a rule which identifies
+// it by its content rather than its ID, so that a rule which was deleted and
+// recreated with the same properties can be found again.
+func RuleFingerprint(props map[string]string) string {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		if !volatileProperties[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// NUL cannot occur in RouterOS strings and thus separates unambiguously
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(props[k]))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:16])
+}
+
+// FindRuleByFingerprint returns the single rule of the given type whose
+// fingerprint equals fingerprint, see RuleFingerprint.
+func (c *Client) FindRuleByFingerprint(ctx context.Context, ruleType, fingerprint string) (FirewallRule, error) {
+	table, err := rulePath(ruleType)
+	if err != nil {
+		return FirewallRule{}, err
+	}
+	rules, err := c.ListRuleProperties(ctx, ruleType)
+	if err != nil {
+		return FirewallRule{}, err
+	}
+
+	var matches []FirewallRule
+	for _, props := range rules {
+		if RuleFingerprint(props) == fingerprint {
+			matches = append(matches, FirewallRule{
+				ID:       props[".id"],
+				Chain:    props["chain"],
+				Comment:  props["comment"],
+				Dynamic:  props["dynamic"],
+				Disabled: props["disabled"],
+				Table:    table,
+			})
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return FirewallRule{}, fmt.Errorf("%w: no rule of type '%s' has the fingerprint '%s'", ErrRuleNotFound, ruleType, fingerprint)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, rule := range matches {
+			ids = append(ids, rule.ID)
+		}
+		return FirewallRule{}, fmt.Errorf("fingerprint '%s' is ambiguous, it matches the rules %s of type '%s'", fingerprint, strings.Join(ids, ", "), ruleType)
+	}
+}
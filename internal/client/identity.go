@@ -0,0 +1,85 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DeviceError attaches the identity of the device to an error returned by
+// the device or while contacting it, see ClientOpts.IdentityInErrors.
+type DeviceError struct {
+	Identity string
+	Err      error
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("router '%s': %s", e.Identity, e.Err)
+}
+
+func (e *DeviceError) Unwrap() error {
+	return e.Err
+}
+
+// GetIdentity returns the identity of the device, as configured in
+// `/system/identity`.
+func (c *Client) GetIdentity(ctx context.Context) (string, error) {
+	var res struct {
+		Name string `json:"name"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/system/identity", nil, &res)
+	return res.Name, err
+}
+
+// LoadIdentity fetches the identity of the device, which is attached to all
+// errors returned afterwards, if ClientOpts.IdentityInErrors is set. It does
+// nothing otherwise.
+func (c *Client) LoadIdentity(ctx context.Context) error {
+	if !c.identityInErrors {
+		return nil
+	}
+	name, err := c.GetIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.identity = name
+	return nil
+}
+
+// withIdentity wraps err in a *DeviceError if the identity of the device is
+// known and err does not carry it already.
+func (c *Client) withIdentity(err error) error {
+	if err == nil {
+		return nil
+	}
+	c.mu.Lock()
+	identity := c.identity
+	c.mu.Unlock()
+
+	var devErr *DeviceError
+	if identity == "" || errors.As(err, &devErr) {
+		return err
+	}
+	return &DeviceError{Identity: identity, Err: err}
+}
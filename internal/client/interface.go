@@ -0,0 +1,74 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Interface is an entry of `/interface`. Boolean properties hold `true` or
+// `false`, as returned by RouterOS.
+type Interface struct {
+	ID          string `json:".id"`
+	Name        string `json:"name"`
+	DefaultName string `json:"default-name"`
+	Type        string `json:"type"`
+	MACAddress  string `json:"mac-address"`
+	Comment     string `json:"comment"`
+	Running     string `json:"running"`
+	Disabled    string `json:"disabled"`
+	Dynamic     string `json:"dynamic"`
+}
+
+// GetInterfaces returns all interfaces of the device.
+func (c *Client) GetInterfaces(ctx context.Context) ([]Interface, error) {
+	interfaces := []Interface{}
+	err := c.doJSON(ctx, http.MethodGet, "/interface", nil, &interfaces)
+	return interfaces, err
+}
+
+// GetInterfaceLists returns all interface lists of the device, including the
+// builtin ones such as `all` and `dynamic`.
+func (c *Client) GetInterfaceLists(ctx context.Context) ([]InterfaceList, error) {
+	lists := []InterfaceList{}
+	err := c.doJSON(ctx, http.MethodGet, "/interface/list", nil, &lists)
+	for i := range lists {
+		lists[i].Comment, lists[i].Owner = c.untagComment(lists[i].Comment)
+	}
+	return lists, err
+}
+
+// GetInterfaceListMembers returns the members of the interface list with the
+// given name, or of all lists if list is empty. Members of builtin lists such
+// as `all` are implicit and therefore not returned.
+func (c *Client) GetInterfaceListMembers(ctx context.Context, list string) ([]InterfaceListMember, error) {
+	members := []InterfaceListMember{}
+	p := "/interface/list/member"
+	if list != "" {
+		p = fmt.Sprintf("%s?%s", p, url.Values{"list": {list}}.Encode())
+	}
+	err := c.doJSON(ctx, http.MethodGet, p, nil, &members)
+	for i := range members {
+		members[i].Comment, members[i].Owner = c.untagComment(members[i].Comment)
+	}
+	return members, err
+}
@@ -0,0 +1,141 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// InterfaceList is an entry of `/interface/list`. Include and Exclude hold
+// comma-separated names of other interface lists, as returned by RouterOS.
+type InterfaceList struct {
+	ID      string `json:".id,omitempty"`
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	Include string `json:"include"`
+	Exclude string `json:"exclude"`
+	Builtin string `json:"builtin,omitempty"`
+	// Owner is the workspace which created the list, if any.
+	Owner string `json:"-"`
+}
+
+// InterfaceListMember is an entry of `/interface/list/member`.
+type InterfaceListMember struct {
+	ID        string `json:".id,omitempty"`
+	Interface string `json:"interface"`
+	List      string `json:"list"`
+	Comment   string `json:"comment"`
+	Disabled  string `json:"disabled"`
+	Dynamic   string `json:"dynamic,omitempty"`
+	// Owner is the workspace which created the member, if any.
+	Owner string `json:"-"`
+}
+
+func (c *Client) GetInterfaceList(ctx context.Context, id string) (InterfaceList, error) {
+	var l InterfaceList
+	p, err := objectPath("/interface/list", id)
+	if err != nil {
+		return l, err
+	}
+	err = c.doJSON(ctx, http.MethodGet, p, nil, &l)
+	l.Comment, l.Owner = c.untagComment(l.Comment)
+	return l, err
+}
+
+func (c *Client) CreateInterfaceList(ctx context.Context, l InterfaceList) (InterfaceList, error) {
+	var created InterfaceList
+	l.Comment = c.tagComment(l.Comment)
+	err := c.doJSON(ctx, http.MethodPut, "/interface/list", l, &created)
+	created.Comment, created.Owner = c.untagComment(created.Comment)
+	return created, err
+}
+
+func (c *Client) UpdateInterfaceList(ctx context.Context, l InterfaceList) (InterfaceList, error) {
+	var updated InterfaceList
+	p, err := objectPath("/interface/list", l.ID)
+	if err != nil {
+		return updated, err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return updated, err
+	}
+	l.ID = ""
+	l.Comment = c.tagComment(l.Comment)
+	err = c.doJSON(ctx, http.MethodPatch, p, l, &updated)
+	updated.Comment, updated.Owner = c.untagComment(updated.Comment)
+	return updated, err
+}
+
+func (c *Client) DeleteInterfaceList(ctx context.Context, id string) error {
+	p, err := objectPath("/interface/list", id)
+	if err != nil {
+		return err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodDelete, p, nil, nil)
+}
+
+func (c *Client) GetInterfaceListMember(ctx context.Context, id string) (InterfaceListMember, error) {
+	var m InterfaceListMember
+	p, err := objectPath("/interface/list/member", id)
+	if err != nil {
+		return m, err
+	}
+	err = c.doJSON(ctx, http.MethodGet, p, nil, &m)
+	m.Comment, m.Owner = c.untagComment(m.Comment)
+	return m, err
+}
+
+func (c *Client) CreateInterfaceListMember(ctx context.Context, m InterfaceListMember) (InterfaceListMember, error) {
+	var created InterfaceListMember
+	m.Comment = c.tagComment(m.Comment)
+	err := c.doJSON(ctx, http.MethodPut, "/interface/list/member", m, &created)
+	created.Comment, created.Owner = c.untagComment(created.Comment)
+	return created, err
+}
+
+func (c *Client) UpdateInterfaceListMember(ctx context.Context, m InterfaceListMember) (InterfaceListMember, error) {
+	var updated InterfaceListMember
+	p, err := objectPath("/interface/list/member", m.ID)
+	if err != nil {
+		return updated, err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return updated, err
+	}
+	m.ID = ""
+	m.Comment = c.tagComment(m.Comment)
+	err = c.doJSON(ctx, http.MethodPatch, p, m, &updated)
+	updated.Comment, updated.Owner = c.untagComment(updated.Comment)
+	return updated, err
+}
+
+func (c *Client) DeleteInterfaceListMember(ctx context.Context, id string) error {
+	p, err := objectPath("/interface/list/member", id)
+	if err != nil {
+		return err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodDelete, p, nil, nil)
+}
@@ -0,0 +1,80 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Layer7Protocol is an entry of `/ip/firewall/layer7-protocol`.
+type Layer7Protocol struct {
+	ID      string `json:".id,omitempty"`
+	Name    string `json:"name"`
+	Regexp  string `json:"regexp"`
+	Comment string `json:"comment"`
+	// Owner is the workspace which created the protocol, if any.
+	Owner string `json:"-"`
+}
+
+func (c *Client) GetLayer7Protocol(ctx context.Context, id string) (Layer7Protocol, error) {
+	var l Layer7Protocol
+	p, err := objectPath("/ip/firewall/layer7-protocol", id)
+	if err != nil {
+		return l, err
+	}
+	err = c.doJSON(ctx, http.MethodGet, p, nil, &l)
+	l.Comment, l.Owner = c.untagComment(l.Comment)
+	return l, err
+}
+
+func (c *Client) CreateLayer7Protocol(ctx context.Context, l Layer7Protocol) (Layer7Protocol, error) {
+	var created Layer7Protocol
+	l.Comment = c.tagComment(l.Comment)
+	err := c.doJSON(ctx, http.MethodPut, "/ip/firewall/layer7-protocol", l, &created)
+	created.Comment, created.Owner = c.untagComment(created.Comment)
+	return created, err
+}
+
+func (c *Client) UpdateLayer7Protocol(ctx context.Context, l Layer7Protocol) (Layer7Protocol, error) {
+	var updated Layer7Protocol
+	p, err := objectPath("/ip/firewall/layer7-protocol", l.ID)
+	if err != nil {
+		return updated, err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return updated, err
+	}
+	l.ID = ""
+	l.Comment = c.tagComment(l.Comment)
+	err = c.doJSON(ctx, http.MethodPatch, p, l, &updated)
+	updated.Comment, updated.Owner = c.untagComment(updated.Comment)
+	return updated, err
+}
+
+func (c *Client) DeleteLayer7Protocol(ctx context.Context, id string) error {
+	p, err := objectPath("/ip/firewall/layer7-protocol", id)
+	if err != nil {
+		return err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodDelete, p, nil, nil)
+}
@@ -0,0 +1,138 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// OrderChains establishes the ordering of the rules of several chains of the
+// same table at once. chains maps the name of every chain to the IDs of its
+// rules in their desired order, see OrderRules for the meaning of opts, whose
+// Chain is ignored. Unlike calling OrderRules once per chain, the table is
+// read once per round, and the moves of all chains are planned from it and
+// performed in one batch. Moves only ever place rules relative to rules of
+// their own chain, so the moves of one chain do not affect the plan of
+// another. It returns the number of moves which were performed.
+func (c *Client) OrderChains(ctx context.Context, ruleType string, chains map[string][]string, opts OrderingOpts) (int, error) {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return 0, err
+	}
+
+	unlock, err := c.lockTable(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	names := make([]string, 0, len(chains))
+	for chain := range chains {
+		names = append(names, chain)
+	}
+	sort.Strings(names)
+
+	moves := 0
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			// give the previous round of moves time to become visible
+			select {
+			case <-time.After(time.Duration(attempt) * orderRetryDelay):
+			case <-ctx.Done():
+				return moves, ctx.Err()
+			}
+			c.cache.invalidate()
+		}
+
+		rules, err := c.GetRulesOfType(ctx, ruleType)
+		if err != nil {
+			return moves, err
+		}
+
+		var planned []Move
+		var failed *OrderingError
+		for _, chain := range names {
+			ids := chains[chain]
+			filtered := opts.Filter(RulesOfChain(rules, chain), ids)
+			observed := ObservedOrdering(ids, filtered, opts.Strict)
+			if stringsEqual(observed, ids) {
+				continue
+			}
+			if failed == nil {
+				failed = &OrderingError{RuleType: ruleType, Attempts: attempt, Expected: ids, Observed: observed}
+			}
+
+			m := PlanMoves(filtered, ids, opts.Strict)
+			if len(m) == 0 {
+				// the ordering is not in place, so something has to move
+				m = []Move{{IDs: ids, Target: End}}
+			}
+			planned = append(planned, m...)
+		}
+
+		if len(planned) == 0 {
+			return moves, nil
+		}
+		if attempt == maxOrderAttempts {
+			return moves, failed
+		}
+		if attempt > 0 {
+			recordRetry()
+			tflog.Warn(ctx, "Rule layout not in place after move, retrying", map[string]interface{}{
+				"rule_type": ruleType,
+				"attempt":   attempt + 1,
+			})
+		}
+
+		// the moves are checked against the table of this round, so that it
+		// is not read again before every move
+		for _, m := range planned {
+			if rules, err = c.moveRules(ctx, ruleType, p, rules, m.IDs, m.Target); err != nil {
+				return moves, err
+			}
+			moves++
+		}
+	}
+}
+
+// RulesOfChain returns the rules of rules which belong to chain.
+func RulesOfChain(rules []FirewallRule, chain string) []FirewallRule {
+	filtered := make([]FirewallRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Chain == chain {
+			filtered = append(filtered, rule)
+		}
+	}
+	return linkRules(filtered)
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+func TestOrderChains(t *testing.T) {
+	server, c := newTestDevice(t)
+	ids := server.Add(filterMenu,
+		map[string]string{"chain": "input", "action": "drop"},
+		map[string]string{"chain": "forward", "action": "accept"},
+		map[string]string{"chain": "input", "action": "accept"},
+		map[string]string{"chain": "forward", "action": "drop"},
+		map[string]string{"chain": "forward", "action": "fasttrack-connection"},
+	)
+
+	chains := map[string][]string{
+		"input":   {ids[2], ids[0]},
+		"forward": {ids[4], ids[1], ids[3]},
+	}
+	moves, err := c.OrderChains(context.Background(), "filter", chains, client.OrderingOpts{Strict: true})
+	if err != nil {
+		t.Fatalf("OrderChains() returned error: %s", err)
+	}
+	if moves != 2 {
+		t.Errorf("OrderChains() performed %d moves, want 2", moves)
+	}
+
+	got := map[string][]string{}
+	for _, o := range server.Objects(filterMenu) {
+		got[o["chain"]] = append(got[o["chain"]], o[".id"])
+	}
+	if !reflect.DeepEqual(got, chains) {
+		t.Errorf("chains = %v, want %v", got, chains)
+	}
+
+	// one round to plan the moves of both chains, and one to verify them
+	if reads := server.Reads(filterMenu); reads != 2 {
+		t.Errorf("table was read %d times, want 2", reads)
+	}
+}
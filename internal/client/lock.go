@@ -0,0 +1,50 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// tableLocks serializes move operations per device and rule table. The locks
+// are shared by all clients of the process, since several provider
+// configurations may well talk to the same device.
+var tableLocks sync.Map
+
+// lockTable acquires the move lock of the rule table at path p, waiting until
+// it is released by any other client or until ctx is done. The returned
+// function releases the lock again.
+func (c *Client) lockTable(ctx context.Context, p string) (func(), error) {
+	if c.disableMoveLock {
+		return func() {}, nil
+	}
+
+	// A buffered channel is used instead of a mutex so that waiting can be
+	// aborted once the context is done.
+	l, _ := tableLocks.LoadOrStore(c.hostURL+p, make(chan struct{}, 1))
+	lock := l.(chan struct{})
+
+	select {
+	case lock <- struct{}{}:
+		return func() { <-lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
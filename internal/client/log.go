@@ -0,0 +1,77 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// LogEntry is an entry of the system log, `/log`.
+type LogEntry struct {
+	ID      string `json:".id"`
+	Time    string `json:"time"`
+	Topics  string `json:"topics"`
+	Message string `json:"message"`
+}
+
+// RuleMove is a move of rules recorded in the system log.
+type RuleMove struct {
+	// Time is the time of the move as reported by RouterOS, e.g.
+	// `2023-10-16 12:00:00` or `12:00:00` for moves of the current day.
+	Time string
+	// By is the user who moved the rules, e.g. `admin`.
+	By string
+}
+
+// GetLog returns all entries of the system log which are still kept in
+// memory, oldest first.
+func (c *Client) GetLog(ctx context.Context) ([]LogEntry, error) {
+	entries := []LogEntry{}
+	err := c.doJSON(ctx, http.MethodGet, "/log", nil, &entries)
+	return entries, err
+}
+
+// FindRuleMoves returns the moves of rules of the given rule table recorded in
+// the system log, oldest first. RouterOS logs configuration changes as e.g.
+// `filter rule moved by admin`, but only if the `system,info` topics are
+// logged, and only keeps a limited number of entries in memory, so the result
+// is best effort. Moves made by this provider show up as well.
+func (c *Client) FindRuleMoves(ctx context.Context, ruleType string) ([]RuleMove, error) {
+	menu, err := rulePath(ruleType)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.GetLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := path.Base(menu) + " rule moved by "
+	var moves []RuleMove
+	for _, e := range entries {
+		if i := strings.Index(e.Message, prefix); i >= 0 {
+			by, _, _ := strings.Cut(e.Message[i+len(prefix):], " ")
+			moves = append(moves, RuleMove{Time: e.Time, By: by})
+		}
+	}
+	return moves, nil
+}
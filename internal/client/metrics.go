@@ -0,0 +1,189 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metricQuantiles are the quantiles of the request latency which are reported.
+var metricQuantiles = []float64{0.5, 0.9, 0.99}
+
+// Metrics collects statistics about the requests sent by all clients of the
+// process. It is written in the Prometheus text exposition format.
+type Metrics struct {
+	mu        sync.Mutex
+	requests  map[string]int64
+	failures  int64
+	throttled int64
+	moves     int64
+	retries   int64
+	latencies []float64
+}
+
+// metrics is the collector in use, or nil if metrics are disabled.
+var metrics atomic.Pointer[Metrics]
+
+// EnableMetrics starts collecting metrics of all clients and returns the
+// collector. Metrics are disabled by default, since every request would be
+// recorded otherwise.
+func EnableMetrics() *Metrics {
+	m := &Metrics{requests: map[string]int64{}}
+	metrics.Store(m)
+	return m
+}
+
+// recordRequest records a request with the given method and latency. err is
+// the transport error if any, status the HTTP status code otherwise.
+func recordRequest(method string, d time.Duration, status int, err error) {
+	m := metrics.Load()
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[method]++
+	if err != nil || status >= 400 {
+		m.failures++
+	}
+	m.latencies = append(m.latencies, d.Seconds())
+}
+
+// recordThrottled records a request which was rejected because the device is
+// overloaded.
+func recordThrottled() {
+	if m := metrics.Load(); m != nil {
+		m.mu.Lock()
+		m.throttled++
+		m.mu.Unlock()
+	}
+}
+
+// recordMove records a move operation.
+func recordMove() {
+	if m := metrics.Load(); m != nil {
+		m.mu.Lock()
+		m.moves++
+		m.mu.Unlock()
+	}
+}
+
+// recordRetry records a repeated attempt of ordering a table.
+func recordRetry() {
+	if m := metrics.Load(); m != nil {
+		m.mu.Lock()
+		m.retries++
+		m.mu.Unlock()
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+
+	fmt.Fprintln(cw, "# HELP routeros_api_requests_total Number of RouterOS API requests sent, by HTTP method.")
+	fmt.Fprintln(cw, "# TYPE routeros_api_requests_total counter")
+	methods := make([]string, 0, len(m.requests))
+	for method := range m.requests {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		fmt.Fprintf(cw, "routeros_api_requests_total{method=%q} %d\n", method, m.requests[method])
+	}
+
+	fmt.Fprintln(cw, "# HELP routeros_api_request_failures_total Number of RouterOS API requests which failed or returned an error status.")
+	fmt.Fprintln(cw, "# TYPE routeros_api_request_failures_total counter")
+	fmt.Fprintf(cw, "routeros_api_request_failures_total %d\n", m.failures)
+
+	fmt.Fprintln(cw, "# HELP routeros_api_throttled_total Number of RouterOS API requests which were rejected as the device was overloaded.")
+	fmt.Fprintln(cw, "# TYPE routeros_api_throttled_total counter")
+	fmt.Fprintf(cw, "routeros_api_throttled_total %d\n", m.throttled)
+
+	fmt.Fprintln(cw, "# HELP routeros_rule_moves_total Number of move operations.")
+	fmt.Fprintln(cw, "# TYPE routeros_rule_moves_total counter")
+	fmt.Fprintf(cw, "routeros_rule_moves_total %d\n", m.moves)
+
+	fmt.Fprintln(cw, "# HELP routeros_ordering_retries_total Number of repeated attempts to establish an ordering.")
+	fmt.Fprintln(cw, "# TYPE routeros_ordering_retries_total counter")
+	fmt.Fprintf(cw, "routeros_ordering_retries_total %d\n", m.retries)
+
+	latencies := append([]float64(nil), m.latencies...)
+	sort.Float64s(latencies)
+	sum := 0.0
+	for _, l := range latencies {
+		sum += l
+	}
+	fmt.Fprintln(cw, "# HELP routeros_api_request_duration_seconds Latency of RouterOS API requests.")
+	fmt.Fprintln(cw, "# TYPE routeros_api_request_duration_seconds summary")
+	for _, q := range metricQuantiles {
+		fmt.Fprintf(cw, "routeros_api_request_duration_seconds{quantile=\"%g\"} %g\n", q, quantile(latencies, q))
+	}
+	fmt.Fprintf(cw, "routeros_api_request_duration_seconds_sum %g\n", sum)
+	fmt.Fprintf(cw, "routeros_api_request_duration_seconds_count %d\n", len(latencies))
+
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics, e.g. on a debug listener.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+// quantile returns the q-quantile of the sorted values using the nearest rank
+// method, or NaN if there are none.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// countingWriter keeps track of the number of bytes written and the first
+// error encountered.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -20,6 +20,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
@@ -27,21 +28,54 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type Client struct {
-	hostURL  string
-	username string
-	password string
-	client   *http.Client
+	hostURL       string
+	basePath      string
+	username      string
+	authorization string
+	client        *http.Client
+
+	workspace           string
+	allowCrossWorkspace bool
+	allowDefconfMoves   bool
+	commentPrefix       string
+	concurrency         int
+	disableMoveLock     bool
+	limiter             *rateLimiter
+	skipReadOnError     bool
+	reportDrift         bool
+	identityInErrors    bool
+	unconfigured        bool
+
+	mu       sync.Mutex
+	version  *Version
+	identity string
+
+	cache       ruleCache
+	orderHashes orderHashes
 }
 
 type FirewallRule struct {
-	ID    string `json:".id"`
-	Chain string `json:"chain"`
+	ID       string `json:".id"`
+	Chain    string `json:"chain"`
+	Comment  string `json:"comment"`
+	Dynamic  string `json:"dynamic,omitempty"`
+	Disabled string `json:"disabled,omitempty"`
+	// Table is the menu path of the rule table the rule was read from, e.g.
+	// `/ip/firewall/filter`.
+	Table string `json:"-"`
 	Next  *FirewallRule
 }
 
@@ -49,39 +83,209 @@ type ClientOpts struct {
 	HostURL  string
 	Username string
 	Password string
-	CA       string
-	Insecure bool
+	// BasePath is the path the REST API is served at, e.g. `/mikrotik/rest`
+	// for a device behind a reverse proxy. Defaults to DefaultBasePath.
+	BasePath string
+	// Authorization is sent as the Authorization header of every request
+	// instead of deriving basic auth credentials from Username and Password,
+	// e.g. to authenticate against a proxy in front of the device.
+	Authorization string
+	CA            string
+	Insecure      bool
+	// ServerName overrides the host name the certificate of the device is
+	// verified against, e.g. if the device is reached by IP address.
+	ServerName string
+	// FingerprintSHA256 pins the certificate of the device by its SHA-256
+	// fingerprint. If set, CA is optional and the certificate is accepted if
+	// and only if it matches.
+	FingerprintSHA256 string
+	// Timeout limits the duration of a single request. Zero means no timeout.
+	Timeout time.Duration
+	// Workspace is used to tag all objects created by the client. Objects
+	// tagged with a different workspace may not be modified unless
+	// AllowCrossWorkspace is set.
+	Workspace           string
+	AllowCrossWorkspace bool
+	// AllowDefconfMoves allows moves which move rules of the RouterOS
+	// default configuration or place other rules in front of them, see
+	// DefconfMoveError.
+	AllowDefconfMoves bool
+	// CommentPrefix is prepended to the comment of every object created by
+	// the client, e.g. `tf:`, and stripped again when reading comments.
+	CommentPrefix string
+	// Concurrency limits the number of parallel requests sent for batched
+	// operations. Defaults to DefaultConcurrency.
+	Concurrency int
+	// Proxy is the URL of the proxy all requests are sent through. If empty,
+	// the proxy is taken from the HTTPS_PROXY and NO_PROXY environment
+	// variables.
+	Proxy string
+	// SSH, if non-nil, forwards all requests through an SSH connection. The
+	// host of the REST API is then resolved and dialed by the SSH server.
+	SSH *SSHTunnelOpts
+	// DisableMoveLock disables serializing move operations on the same rule
+	// table, which otherwise prevents concurrent orderings from interleaving
+	// their moves.
+	DisableMoveLock bool
+	// MaxRate limits the number of requests sent per second. Zero means no
+	// limit.
+	MaxRate int
+	// SkipReadOnError makes consumers keep their prior state instead of
+	// failing if the device cannot be reached while refreshing, see
+	// Client.SkipReadOnError.
+	SkipReadOnError bool
+	// ReportOrderingDrift makes orderings report a summary of drift while
+	// refreshing, see Client.ReportOrderingDrift.
+	ReportOrderingDrift bool
+	// IdentityInErrors makes Client.LoadIdentity fetch the identity of the
+	// device, which is then attached to all errors as a *DeviceError, so that
+	// users of multiple routers can tell which one an error came from.
+	IdentityInErrors bool
+	// MaxIdleConns is the number of idle connections which are kept open for
+	// reuse, so that consecutive requests do not each need a TLS handshake.
+	// Defaults to Concurrency.
+	MaxIdleConns int
+	// IdleConnTimeout is the duration after which idle connections are
+	// closed. Defaults to DefaultIdleConnTimeout.
+	IdleConnTimeout time.Duration
+	// DisableKeepAlives makes every request use a new connection.
+	DisableKeepAlives bool
+	// RecordPath is the path of a file which all requests and responses are
+	// appended to, so that they can be attached to bug reports and replayed.
+	// Credentials are redacted, see Exchange. Nothing is recorded if empty.
+	RecordPath string
 }
 
-func New(opts ClientOpts) (*Client, error) {
-	if opts.CA == "" {
-		return nil, errors.New("No CA cert provided")
+// DefaultIdleConnTimeout is the duration after which idle connections are
+// closed if no timeout is configured.
+const DefaultIdleConnTimeout = 90 * time.Second
+
+// DefaultBasePath is the path the REST API of RouterOS is served at.
+const DefaultBasePath = "/rest"
+
+// normalizeBasePath returns p with a single leading and no trailing slash, or
+// DefaultBasePath if p is empty. The root path is returned as an empty string.
+func normalizeBasePath(p string) string {
+	if p == "" {
+		return DefaultBasePath
+	}
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return ""
 	}
+	return "/" + p
+}
+
+// maxDrainBytes is the number of bytes of an unread response body which are
+// discarded so that its connection can be reused. Connections of larger
+// bodies are closed instead.
+const maxDrainBytes = 64 << 10
 
-	if _, err := os.Stat(opts.CA); err != nil {
-		return nil, fmt.Errorf("Could not open file at provided path %s\n", opts.CA)
+func New(opts ClientOpts) (*Client, error) {
+	if opts.CA == "" && opts.FingerprintSHA256 == "" {
+		return nil, errors.New("No CA cert provided")
 	}
 
 	certPool := x509.NewCertPool()
-	file, err := os.ReadFile(opts.CA)
-	if err != nil {
-		return nil, fmt.Errorf("Could not read file at provided path %s\n", opts.CA)
+	if opts.CA != "" {
+		if _, err := os.Stat(opts.CA); err != nil {
+			return nil, fmt.Errorf("Could not open file at provided path %s\n", opts.CA)
+		}
+
+		file, err := os.ReadFile(opts.CA)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read file at provided path %s\n", opts.CA)
+		}
+
+		certPool.AppendCertsFromPEM(file)
 	}
 
-	certPool.AppendCertsFromPEM(file)
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.MaxIdleConns <= 0 {
+		opts.MaxIdleConns = opts.Concurrency
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = DefaultIdleConnTimeout
+	}
 
 	tls := &tls.Config{
 		InsecureSkipVerify: opts.Insecure,
 		RootCAs:            certPool,
+		ServerName:         opts.ServerName,
+	}
+	if opts.FingerprintSHA256 != "" {
+		fingerprint, err := parseFingerprint(opts.FingerprintSHA256)
+		if err != nil {
+			return nil, err
+		}
+		// the default verification is replaced by the pinned fingerprint,
+		// which VerifyConnection checks even if Insecure is set
+		tls.InsecureSkipVerify = true
+		tls.VerifyConnection = verifyFingerprint(fingerprint)
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("Invalid proxy URL %s, expected a URL such as 'http://proxy.example.com:3128'", opts.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	// all requests go to the same device, so every idle connection may be
+	// kept for it rather than only the two which are kept by default
+	transport := &http.Transport{
+		TLSClientConfig:     tls,
+		Proxy:               proxy,
+		MaxIdleConns:        opts.MaxIdleConns,
+		MaxIdleConnsPerHost: opts.MaxIdleConns,
+		IdleConnTimeout:     opts.IdleConnTimeout,
+		DisableKeepAlives:   opts.DisableKeepAlives,
+	}
+	if opts.SSH != nil {
+		tunnel, err := newSSHTunnel(*opts.SSH, opts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		transport.DialContext = tunnel.DialContext
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if opts.RecordPath != "" {
+		recorder, err := newRecorder(opts.RecordPath, transport)
+		if err != nil {
+			return nil, err
+		}
+		roundTripper = recorder
+	}
+
+	authorization := opts.Authorization
+	if authorization == "" {
+		authorization = basicAuth(opts.Username, opts.Password)
 	}
 
 	return &Client{
-		hostURL:  opts.HostURL,
-		username: opts.Username,
-		password: opts.Password,
+		hostURL:       strings.TrimRight(opts.HostURL, "/"),
+		basePath:      normalizeBasePath(opts.BasePath),
+		username:      opts.Username,
+		authorization: authorization,
 		client: &http.Client{
-			Transport: &http.Transport{TLSClientConfig: tls},
+			Transport: roundTripper,
+			Timeout:   opts.Timeout,
 		},
+		workspace:           opts.Workspace,
+		allowCrossWorkspace: opts.AllowCrossWorkspace,
+		allowDefconfMoves:   opts.AllowDefconfMoves,
+		commentPrefix:       opts.CommentPrefix,
+		concurrency:         opts.Concurrency,
+		disableMoveLock:     opts.DisableMoveLock,
+		limiter:             newRateLimiter(opts.MaxRate),
+		skipReadOnError:     opts.SkipReadOnError,
+		reportDrift:         opts.ReportOrderingDrift,
+		identityInErrors:    opts.IdentityInErrors,
 	}, nil
 }
 
@@ -90,117 +294,520 @@ func basicAuth(username, password string) string {
 	return fmt.Sprintf("Basic %s", auth)
 }
 
-func (c *Client) MakeRequest(method, cmd string, body []byte) (*http.Response, error) {
+// MakeRequest sends a request to the REST API. Requests which the device
+// rejects because it is overloaded are retried with an increasing delay, up
+// to maxThrottleRetries times.
+func (c *Client) MakeRequest(ctx context.Context, method, cmd string, body []byte) (*http.Response, error) {
+	if c.unconfigured {
+		return nil, ErrConfigUnknown
+	}
+
+	// any write may change the order or content of rule tables
+	if method != http.MethodGet && !isReadCommand(cmd) {
+		c.cache.invalidate()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doRequest(ctx, method, cmd, body)
+		if err != nil || !isThrottled(resp.StatusCode) || attempt == maxThrottleRetries {
+			return resp, err
+		}
+
+		delay := retryDelay(resp, attempt)
+		drainAndClose(resp.Body)
+		recordThrottled()
+		tflog.Warn(ctx, "RouterOS API request throttled, retrying", map[string]interface{}{
+			"method":  method,
+			"path":    cmd,
+			"status":  resp.StatusCode,
+			"attempt": attempt + 1,
+			"delay":   delay.String(),
+		})
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isReadCommand reports whether cmd only reads from the device, although it
+// is sent as a POST request, such as the `print` command, see Client.print.
+func isReadCommand(cmd string) bool {
+	return strings.HasSuffix(cmd, "/print")
+}
+
+// drainAndClose discards the remainder of a response body before closing it,
+// which allows the underlying connection to be reused for the next request.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// doRequest sends a single request to the REST API, respecting the rate limit.
+func (c *Client) doRequest(ctx context.Context, method, cmd string, body []byte) (*http.Response, error) {
 	var (
 		req *http.Request
 		err error
-		url string = fmt.Sprintf("%s/rest/%s", c.hostURL, strings.TrimPrefix(cmd, "/"))
+		url string = fmt.Sprintf("%s%s/%s", c.hostURL, c.basePath, strings.TrimPrefix(cmd, "/"))
 	)
 
 	if body == nil {
-		req, err = http.NewRequest(method, url, nil)
+		req, err = http.NewRequestWithContext(ctx, method, url, nil)
 	} else {
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(body))
+		req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", basicAuth(c.username, c.password))
+	req.Header.Add("Authorization", c.authorization)
 	req.Header.Add("Content-Type", "application/json")
 
-	return c.client.Do(req)
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	// credentials are deliberately never logged, only the user they belong to
+	tflog.Debug(ctx, "Sending RouterOS API request", map[string]interface{}{
+		"method":   method,
+		"path":     cmd,
+		"username": c.username,
+	})
+	tflog.Trace(ctx, "RouterOS API request body", map[string]interface{}{
+		"body": string(body),
+	})
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	recordRequest(method, time.Since(start), status, err)
+	if err != nil {
+		tflog.Debug(ctx, "RouterOS API request failed", map[string]interface{}{
+			"method":   method,
+			"path":     cmd,
+			"duration": time.Since(start).String(),
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	tflog.Debug(ctx, "Received RouterOS API response", map[string]interface{}{
+		"method":   method,
+		"path":     cmd,
+		"status":   resp.StatusCode,
+		"duration": time.Since(start).String(),
+	})
+
+	return resp, nil
+}
+
+// APIError is returned whenever the REST API responds with a non-successful
+// status code. RouterOS encodes errors as JSON objects of this form.
+type APIError struct {
+	Status  int    `json:"error"`
+	Message string `json:"message"`
+	Detail  string `json:"detail"`
+}
+
+func (e *APIError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Message, e.Detail)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+// NonJSONResponseError is returned if the device responds with something other
+// than JSON, most commonly an HTML page.
+type NonJSONResponseError struct {
+	Status      int
+	ContentType string
+	Body        string
+}
+
+func (e *NonJSONResponseError) Error() string {
+	if e.IsHTML() {
+		return fmt.Sprintf("expected a JSON response from the RouterOS REST API, got an HTML page with status %d instead, "+
+			"most likely the WebFig login page of the plain 'www' service. The REST API is only served by the 'www-ssl' service "+
+			"of RouterOS 7.1 and newer. To enable it, import or create a certificate and run "+
+			"'/ip service set www-ssl certificate=<name> disabled=no', then point 'hosturl' and 'port' at the www-ssl service, "+
+			"which listens on port 443 by default. Beginning of the response body: %q", e.Status, e.Body)
+	}
+	return fmt.Sprintf("expected a JSON response from the RouterOS REST API, got status %d with content type '%s' instead. "+
+		"This usually means that the request did not reach the REST API, e.g. because the configured port is served by the "+
+		"plain 'www' service instead of 'www-ssl', or because a proxy or captive portal intercepted the request. "+
+		"Beginning of the response body: %q", e.Status, e.ContentType, e.Body)
+}
+
+// IsHTML reports whether the response was an HTML page, such as the WebFig
+// login page served by the 'www' service.
+func (e *NonJSONResponseError) IsHTML() bool {
+	mediaType, _, _ := mime.ParseMediaType(e.ContentType)
+	return mediaType == "text/html" || strings.HasPrefix(strings.TrimSpace(e.Body), "<")
+}
+
+// nonJSONSnippetLength is the number of body bytes included in a
+// NonJSONResponseError.
+const nonJSONSnippetLength = 128
+
+// checkContentType returns a *NonJSONResponseError if a non-empty response
+// body is not JSON.
+func checkContentType(r *http.Response, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" {
+		return nil
+	}
+
+	snippet := body
+	if len(snippet) > nonJSONSnippetLength {
+		snippet = snippet[:nonJSONSnippetLength]
+	}
+	return &NonJSONResponseError{Status: r.StatusCode, ContentType: contentType, Body: string(snippet)}
+}
+
+// IsNotFound reports whether err signals that the requested object does not
+// exist on the device.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// IsUnreachable reports whether err signals that the device could not be
+// contacted at all, as opposed to the device rejecting the request.
+func IsUnreachable(err error) bool {
+	var (
+		netErr       net.Error
+		dnsErr       *net.DNSError
+		connectError *net.OpError
+	)
+	return errors.As(err, &dnsErr) || errors.As(err, &connectError) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// SkipReadOnError reports whether reads which fail because the device is
+// unreachable should keep the prior state rather than fail, so that plans
+// against devices which are temporarily offline do not report any drift.
+func (c *Client) SkipReadOnError() bool {
+	return c.skipReadOnError
 }
 
-func (c *Client) GetOrderingFrom(ruleType string, start FirewallRule, length int) ([]FirewallRule, error) {
-	var ordering []FirewallRule
+// ReportOrderingDrift reports whether orderings which drifted are to report
+// a summary of the drift, including who moved the rules according to the
+// system log, while refreshing. Refreshing never changes the device, the drift
+// is corrected by the next apply either way.
+func (c *Client) ReportOrderingDrift() bool {
+	return c.reportDrift
+}
+
+// doJSON performs a request with an optional JSON encoded payload and decodes
+// the response into out, if non-nil. Non-successful responses are returned as
+// an *APIError.
+func (c *Client) doJSON(ctx context.Context, method, cmd string, in, out any) (err error) {
+	defer func() {
+		err = c.withIdentity(err)
+	}()
+
+	var body []byte
+	if in != nil {
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
 
-	ordering, err := c.GetRulesOfType(ruleType)
+	r, err := c.MakeRequest(ctx, method, cmd, body)
 	if err != nil {
-		return ordering, err
+		return err
 	}
 
-	return ordering, nil
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := checkContentType(r, b); err != nil {
+		return err
+	}
+
+	if r.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{Status: r.StatusCode, Message: http.StatusText(r.StatusCode)}
+		// the body is best effort, we still want to surface the status code if
+		// the device responded with something other than JSON
+		_ = json.Unmarshal(b, apiErr)
+		return apiErr
+	}
+
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
 }
 
-// TODO: allow for gaps in subsequence. So if real_state=[1,2,X,3,4] and
-// desired_state=[1,2,3,4], this should still return true. Or make a resource
-// option to allow for toggling between these two behaviors?
-func (c *Client) RuleOrderExists(ruleType string, seq []FirewallRule) (bool, error) {
-	var subSeq string
-	var ruleSequenceStr string
+// GetOrderingFrom returns the window of rules of the given type which starts
+// at the rule start and spans up to length rules, in the order in which they
+// appear in the table. A length of zero or less returns all rules from start
+// to the end of the table. If start has a chain, only the rules of that chain
+// are fetched and counted, so anchor-based checks do not need to download the
+// entire table. ErrRuleNotFound is returned if start is not part of the table.
+func (c *Client) GetOrderingFrom(ctx context.Context, ruleType string, start FirewallRule, length int) ([]FirewallRule, error) {
+	rules, err := c.GetRulesOfChain(ctx, ruleType, start.Chain)
+	if err != nil {
+		return []FirewallRule{}, err
+	}
 
-	for _, rule := range seq {
-		subSeq += rule.ID
+	for i, rule := range rules {
+		if rule.ID != start.ID {
+			continue
+		}
+		end := len(rules)
+		if length > 0 && i+length < end {
+			end = i + length
+		}
+		return linkRules(rules[i:end]), nil
 	}
+	return []FirewallRule{}, fmt.Errorf("%w: unable to find rule of type '%s' with id: '%s'", ErrRuleNotFound, ruleType, start.ID)
+}
 
-	rules, err := c.GetRulesOfType(ruleType)
+// RuleOrderExists reports whether the rules in seq appear in the given order
+// within the rule table, taking only the rules selected by opts into account.
+// If opts.Strict is set, the rules must be directly adjacent to each other,
+// i.e. real_state=[1,2,X,3,4] does not contain desired_state=[1,2,3,4].
+// Otherwise, other rules may be placed in between.
+func (c *Client) RuleOrderExists(ctx context.Context, ruleType string, seq []FirewallRule, opts OrderingOpts) (bool, error) {
+	rules, err := c.GetRulesOfChain(ctx, ruleType, opts.Chain)
 	if err != nil {
 		return false, err
 	}
 
+	needle := make([]string, 0, len(seq))
+	for _, rule := range seq {
+		needle = append(needle, rule.ID)
+	}
+
+	rules = opts.Filter(rules, needle)
+	haystack := make([]string, 0, len(rules))
 	for _, rule := range rules {
-		ruleSequenceStr += rule.ID
+		haystack = append(haystack, rule.ID)
 	}
 
-	return strings.Contains(ruleSequenceStr, subSeq), nil
+	if opts.Strict {
+		return ContainsSequence(haystack, needle), nil
+	}
+	return ContainsSubsequence(haystack, needle), nil
 }
 
-func (c *Client) GetRulesOfType(ruleType string) ([]FirewallRule, error) {
-	rules := []FirewallRule{}
+// ContainsSequence reports whether needle appears as a contiguous run of
+// elements within haystack.
+func ContainsSequence(haystack, needle []string) bool {
+	if len(needle) == 0 {
+		return true
+	}
 
-	r, err := c.MakeRequest(http.MethodGet, fmt.Sprintf("/ip/firewall/%s", ruleType), nil)
-	if err != nil {
-		return rules, err
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
 	}
+	return false
+}
 
-	defer r.Body.Close()
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		return rules, err
+// ContainsSubsequence reports whether all elements of needle appear within
+// haystack in the same relative order, possibly with other elements in
+// between.
+func ContainsSubsequence(haystack, needle []string) bool {
+	j := 0
+	for i := 0; i < len(haystack) && j < len(needle); i++ {
+		if haystack[i] == needle[j] {
+			j++
+		}
 	}
+	return j == len(needle)
+}
 
-	err = json.Unmarshal(body, &rules)
+// ruleProperties are the only properties fetched when reading rule tables.
+// Rules carry dozens of properties, which makes fetching all of them slow on
+// devices with large tables.
+const ruleProperties = ".id,chain,comment,dynamic,disabled"
+
+func (c *Client) GetRulesOfType(ctx context.Context, ruleType string) ([]FirewallRule, error) {
+	p, err := rulePath(ruleType)
 	if err != nil {
+		return []FirewallRule{}, err
+	}
+	return c.fetchRules(ctx, ruleType, p, "")
+}
+
+// GetRulesOfChain returns all rules of the given type which belong to chain,
+// in the order in which they appear in the table. An empty chain returns the
+// entire table. Unless the entire table is cached already, only the rules of
+// the chain are fetched from the device.
+func (c *Client) GetRulesOfChain(ctx context.Context, ruleType, chain string) ([]FirewallRule, error) {
+	p, err := rulePath(ruleType)
+	if err != nil || chain == "" {
+		return c.GetRulesOfType(ctx, ruleType)
+	}
+
+	if rules, ok := c.cache.get(p); ok {
+		filtered := []FirewallRule{}
+		for _, rule := range rules {
+			if rule.Chain == chain {
+				filtered = append(filtered, rule)
+			}
+		}
+		return linkRules(filtered), nil
+	}
+
+	return c.fetchRules(ctx, ruleType, p, chain)
+}
+
+// fetchRules reads the rules of the table at path p, restricted to chain if
+// non-empty, using the cache if possible.
+func (c *Client) fetchRules(ctx context.Context, ruleType, p, chain string) ([]FirewallRule, error) {
+	rules := []FirewallRule{}
+
+	query := url.Values{".proplist": {ruleProperties}}
+	if chain != "" {
+		query.Set("chain", chain)
+	}
+	cmd := fmt.Sprintf("%s?%s", p, query.Encode())
+
+	key := p
+	if chain != "" {
+		key = cmd
+	}
+
+	if cached, ok := c.cache.get(key); ok {
+		tflog.Trace(ctx, "Using cached firewall rules", map[string]interface{}{
+			"rule_type": ruleType,
+			"chain":     chain,
+		})
+		return cached, nil
+	}
+
+	if err := c.doJSON(ctx, http.MethodGet, cmd, nil, &rules); err != nil {
 		return rules, err
 	}
 
 	for i := range rules {
-		if i+1 < len(rules) {
-			rules[i].Next = &rules[i+1]
-		}
+		rules[i].Comment, _ = c.untagComment(rules[i].Comment)
+		rules[i].Table = p
 	}
 
+	rules = linkRules(rules)
+	c.cache.put(key, rules)
+	c.orderHashes.put(orderHashKey(p, chain), orderHash(rules))
+
+	tflog.Trace(ctx, "Fetched firewall rules", map[string]interface{}{
+		"rule_type": ruleType,
+		"chain":     chain,
+		"count":     len(rules),
+	})
+
 	return rules, nil
 }
 
-func (c *Client) GetRule(ruleType, id string) (FirewallRule, error) {
+func (c *Client) GetRule(ctx context.Context, ruleType, id string) (FirewallRule, error) {
 	// Yes, we can also just call the GET endpoint for a single rule, but since
 	// we want to augment the return value with the `Next` firewall rule, we need
 	// to be able to easily lookup the rule's follower. The console *does* expose
 	// the `.nextid` field, however this is not available from in the REST API.
 	// And although you can get the output of arbitrary console, parsing it back
 	// into a usable struct is a pain.
-	rules, err := c.GetRulesOfType(ruleType)
+	rules, err := c.GetRulesOfType(ctx, ruleType)
 	if err != nil {
-		return FirewallRule{}, fmt.Errorf("unable to find rule of type '%s' with id: '%s'", ruleType, id)
+		return FirewallRule{}, fmt.Errorf("unable to find rule of type '%s' with id: '%s': %w", ruleType, id, err)
 	}
 	for _, rule := range rules {
 		if rule.ID == id {
 			return rule, nil
 		}
 	}
-	return FirewallRule{}, fmt.Errorf("unable to find rule of type '%s' with id: '%s'", ruleType, id)
+	return FirewallRule{}, fmt.Errorf("%w: unable to find rule of type '%s' with id: '%s'", ErrRuleNotFound, ruleType, id)
 }
 
-func (c *Client) OrderRules(ruleType string, rs ...FirewallRule) error {
-	ids := []string{}
-	for _, v := range rs {
-		ids = append(ids, v.ID)
+// MoveRules moves the rules identified by ids, in the order given, to the
+// passed target position within the rule table. Moves on the same table are
+// serialized, see ClientOpts.DisableMoveLock. Rules of a NAT table must all
+// belong to the same chain, see ChainMismatchError.
+func (c *Client) MoveRules(ctx context.Context, ruleType string, ids []string, target Position) error {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := c.lockTable(ctx, p)
+	if err != nil {
+		return err
 	}
-	payload := strings.Join(ids, ",")
+	defer unlock()
 
-	b := []byte(fmt.Sprintf(`{"numbers":"%s","destination":"%s"}`, payload, "*ffffff"))
-	_, err := c.MakeRequest(http.MethodPost, fmt.Sprintf("/ip/firewall/%s/move", ruleType), b)
+	_, err = c.moveRules(ctx, ruleType, p, nil, ids, target)
 	return err
 }
+
+// moveRules implements MoveRules for the rule table at path p without
+// acquiring its move lock. rules is the current table of ruleType, which is
+// read from the device as needed if nil. Otherwise, the table is returned
+// with the move applied, so that several moves can be based on a single read
+// of the table.
+func (c *Client) moveRules(ctx context.Context, ruleType, p string, rules []FirewallRule, ids []string, target Position) ([]FirewallRule, error) {
+	if err := validateIDs(ids); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkSameChain(ctx, ruleType, rules, ids); err != nil {
+		return nil, err
+	}
+
+	destination, err := c.resolvePosition(ctx, ruleType, rules, ids, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkDefconfMove(ctx, ruleType, rules, ids, destination); err != nil {
+		return nil, err
+	}
+
+	req := struct {
+		Numbers     string `json:"numbers"`
+		Destination string `json:"destination"`
+	}{
+		Numbers:     strings.Join(ids, ","),
+		Destination: destination,
+	}
+
+	tflog.Debug(ctx, "Moving firewall rules", map[string]interface{}{
+		"rule_type":   ruleType,
+		"ids":         ids,
+		"target":      target.String(),
+		"destination": destination,
+	})
+
+	// a rejected move must not be mistaken for a successful one, so the
+	// response is checked like that of any other write
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/move", p), req, nil); err != nil {
+		return nil, err
+	}
+	recordMove()
+
+	if rules == nil {
+		return nil, nil
+	}
+	return applyMove(rules, ids, destination), nil
+}
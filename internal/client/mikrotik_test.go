@@ -0,0 +1,81 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+// sequenceTests are shared by the tests of ContainsSequence and
+// ContainsSubsequence, which must agree on every case but those in which the
+// needle is interrupted by other elements.
+var sequenceTests = []struct {
+	name        string
+	haystack    string
+	needle      string
+	sequence    bool
+	subsequence bool
+}{
+	{name: "empty needle", haystack: "*1,*2", needle: "", sequence: true, subsequence: true},
+	{name: "empty haystack and needle", haystack: "", needle: "", sequence: true, subsequence: true},
+	{name: "empty haystack", haystack: "", needle: "*1", sequence: false, subsequence: false},
+	{name: "single element", haystack: "*1", needle: "*1", sequence: true, subsequence: true},
+	{name: "single element missing", haystack: "*1", needle: "*2", sequence: false, subsequence: false},
+	{name: "single element within", haystack: "*1,*2,*3", needle: "*2", sequence: true, subsequence: true},
+	{name: "equal", haystack: "*1,*2,*3", needle: "*1,*2,*3", sequence: true, subsequence: true},
+	{name: "prefix", haystack: "*1,*2,*3", needle: "*1,*2", sequence: true, subsequence: true},
+	{name: "suffix", haystack: "*1,*2,*3", needle: "*2,*3", sequence: true, subsequence: true},
+	{name: "interrupted", haystack: "*1,*2,*3", needle: "*1,*3", sequence: false, subsequence: true},
+	{name: "reversed", haystack: "*1,*2,*3", needle: "*3,*1", sequence: false, subsequence: false},
+	{name: "needle longer than haystack", haystack: "*1,*2", needle: "*1,*2,*3", sequence: false, subsequence: false},
+	{name: "repeated element", haystack: "*1,*1,*2", needle: "*1,*2", sequence: true, subsequence: true},
+	{name: "IDs sharing digits", haystack: "*12,*3", needle: "*1,*23", sequence: false, subsequence: false},
+	{name: "IDs sharing digits reversed", haystack: "*1,*23", needle: "*12,*3", sequence: false, subsequence: false},
+	{name: "ID prefix of another", haystack: "*1A,*2", needle: "*1,*2", sequence: false, subsequence: false},
+}
+
+// splitIDs splits a comma-separated list of IDs, the empty string being the
+// empty list.
+func splitIDs(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func TestContainsSequence(t *testing.T) {
+	for _, tt := range sequenceTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsSequence(splitIDs(tt.haystack), splitIDs(tt.needle)); got != tt.sequence {
+				t.Errorf("ContainsSequence([%s], [%s]) = %v, want %v", tt.haystack, tt.needle, got, tt.sequence)
+			}
+		})
+	}
+}
+
+func TestContainsSubsequence(t *testing.T) {
+	for _, tt := range sequenceTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsSubsequence(splitIDs(tt.haystack), splitIDs(tt.needle)); got != tt.subsequence {
+				t.Errorf("ContainsSubsequence([%s], [%s]) = %v, want %v", tt.haystack, tt.needle, got, tt.subsequence)
+			}
+		})
+	}
+}
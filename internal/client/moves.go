@@ -0,0 +1,168 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Move describes a single move command, which places the rules with the given
+// IDs, in order, at Target.
+type Move struct {
+	IDs    []string
+	Target Position
+}
+
+func (m Move) String() string {
+	ids := strings.Join(m.IDs, ", ")
+	if m.Target.kind == positionStart || m.Target.kind == positionEnd {
+		return fmt.Sprintf("%s to %s", ids, m.Target)
+	}
+	return fmt.Sprintf("%s %s", ids, m.Target)
+}
+
+// PlanMoves returns a minimal sequence of moves which establishes the given
+// order of ids within rules, the table as currently found on the device,
+// such that as few rules as possible are touched. Rules which are already in
+// place relative to each other are left alone, which keeps unmanaged rules
+// and their neighbours where they are.
+//
+// In strict mode the rules additionally have to be adjacent, see
+// OrderingOpts. The longest run of rules which is already adjacent and in
+// order stays in place, and the remaining rules are moved in front of and
+// behind it, requiring at most two moves. Otherwise, the rules forming the
+// longest increasing subsequence of their current positions stay in place,
+// and every other run of rules is moved directly behind its predecessor.
+func PlanMoves(rules []FirewallRule, ids []string, strict bool) []Move {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pos := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		pos[rule.ID] = i
+	}
+
+	if strict {
+		return planStrictMoves(pos, ids)
+	}
+	return planRelativeMoves(pos, ids)
+}
+
+func planStrictMoves(pos map[string]int, ids []string) []Move {
+	// find the longest run of ids which is already adjacent and in order
+	bestStart, bestLen := 0, 0
+	for start := 0; start < len(ids); {
+		if _, ok := pos[ids[start]]; !ok {
+			start++
+			continue
+		}
+		end := start + 1
+		for end < len(ids) {
+			p, ok := pos[ids[end]]
+			if !ok || p != pos[ids[end-1]]+1 {
+				break
+			}
+			end++
+		}
+		if end-start > bestLen {
+			bestStart, bestLen = start, end-start
+		}
+		start = end
+	}
+
+	if bestLen == 0 {
+		return []Move{{IDs: ids, Target: End}}
+	}
+
+	var moves []Move
+	if before := ids[:bestStart]; len(before) > 0 {
+		moves = append(moves, Move{IDs: before, Target: Before(ids[bestStart])})
+	}
+	if after := ids[bestStart+bestLen:]; len(after) > 0 {
+		moves = append(moves, Move{IDs: after, Target: After(ids[bestStart+bestLen-1])})
+	}
+	return moves
+}
+
+func planRelativeMoves(pos map[string]int, ids []string) []Move {
+	fixed := stableRules(pos, ids)
+	if len(fixed) == 0 {
+		return []Move{{IDs: ids, Target: End}}
+	}
+
+	var moves []Move
+	for i := 0; i < len(ids); {
+		if fixed[ids[i]] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(ids) && !fixed[ids[j]] {
+			j++
+		}
+		group := ids[i:j]
+		if i > 0 {
+			moves = append(moves, Move{IDs: group, Target: After(ids[i-1])})
+		} else {
+			// j < len(ids), as at least one rule is fixed
+			moves = append(moves, Move{IDs: group, Target: Before(ids[j])})
+		}
+		i = j
+	}
+	return moves
+}
+
+// stableRules returns the largest set of ids whose current positions are
+// already in the desired order, i.e. the longest increasing subsequence of
+// their positions. IDs which are not part of the table are never stable.
+func stableRules(pos map[string]int, ids []string) map[string]bool {
+	// tails[k] is the index into ids of the smallest tail of all increasing
+	// subsequences of length k+1 found so far
+	var tails []int
+	prev := make([]int, len(ids))
+	for i, id := range ids {
+		p, ok := pos[id]
+		if !ok {
+			prev[i] = -1
+			continue
+		}
+		k := sort.Search(len(tails), func(k int) bool { return pos[ids[tails[k]]] >= p })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	stable := make(map[string]bool, len(tails))
+	if len(tails) == 0 {
+		return stable
+	}
+	for i := tails[len(tails)-1]; i != -1; i = prev[i] {
+		stable[ids[i]] = true
+	}
+	return stable
+}
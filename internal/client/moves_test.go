@@ -0,0 +1,220 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+// tableOf returns a rule table holding rules with the given IDs in order.
+func tableOf(ids ...string) []FirewallRule {
+	rules := make([]FirewallRule, 0, len(ids))
+	for _, id := range ids {
+		rules = append(rules, FirewallRule{ID: id})
+	}
+	return rules
+}
+
+// applyMoves performs moves on the IDs of a table like the `move` command of
+// the device, which places the moved rules in order in front of their
+// destination.
+func applyMoves(t *testing.T, table []string, moves []Move) []string {
+	t.Helper()
+
+	for _, m := range moves {
+		moved := map[string]bool{}
+		for _, id := range m.IDs {
+			moved[id] = true
+		}
+		rest := make([]string, 0, len(table))
+		for _, id := range table {
+			if !moved[id] {
+				rest = append(rest, id)
+			}
+		}
+
+		at := -1
+		switch m.Target.kind {
+		case positionStart:
+			at = 0
+		case positionEnd:
+			at = len(rest)
+		default:
+			for i, id := range rest {
+				if id == m.Target.id {
+					at = i
+				}
+			}
+			if at == -1 {
+				t.Fatalf("move %s: destination is part of the move or not in the table", m)
+			}
+			if m.Target.kind == positionAfter {
+				at++
+			}
+		}
+
+		table = append(append(append([]string{}, rest[:at]...), m.IDs...), rest[at:]...)
+	}
+	return table
+}
+
+// without returns ids without the elements of drop.
+func without(ids []string, drop map[string]bool) []string {
+	var rest []string
+	for _, id := range ids {
+		if !drop[id] {
+			rest = append(rest, id)
+		}
+	}
+	return rest
+}
+
+func TestPlanMoves(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		ids    string
+		strict bool
+		// moves and moved are the expected number of moves and of moved
+		// rules. In relative mode both are minimal, as only rules outside
+		// of the longest increasing subsequence are moved, while strict
+		// mode keeps the longest adjacent run and needs at most two moves.
+		moves int
+		moved int
+	}{
+		{name: "nothing to order", table: "*1,*2", ids: "", moves: 0, moved: 0},
+		{name: "single rule", table: "*1,*2,*3", ids: "*2", moves: 0, moved: 0},
+		{name: "single rule strict", table: "*1,*2,*3", ids: "*2", strict: true, moves: 0, moved: 0},
+		{name: "already ordered", table: "*1,*2,*3,*4,*5", ids: "*1,*3,*5", moves: 0, moved: 0},
+		{name: "already adjacent", table: "*1,*2,*3,*4,*5", ids: "*2,*3,*4", strict: true, moves: 0, moved: 0},
+		{name: "ordered but not adjacent", table: "*1,*2,*3,*4,*5", ids: "*1,*3,*5", strict: true, moves: 1, moved: 2},
+		{name: "reversed", table: "*1,*2,*3", ids: "*3,*2,*1", moves: 1, moved: 2},
+		{name: "reversed strict", table: "*1,*2,*3", ids: "*3,*2,*1", strict: true, moves: 1, moved: 2},
+		{name: "last rule to the front", table: "*1,*2,*3", ids: "*3,*1,*2", moves: 1, moved: 1},
+		{name: "first rule to the back", table: "*1,*2,*3", ids: "*2,*3,*1", moves: 1, moved: 1},
+		{name: "swapped pairs", table: "*1,*2,*3,*4,*5,*6", ids: "*2,*1,*4,*3,*6,*5", moves: 3, moved: 3},
+		{name: "swapped pairs strict", table: "*1,*2,*3,*4,*5,*6", ids: "*2,*1,*4,*3,*6,*5", strict: true, moves: 1, moved: 5},
+		{name: "unmanaged rules in between", table: "*1,*A,*2,*B,*3", ids: "*1,*3,*2", moves: 1, moved: 1},
+		{name: "unmanaged rules in between strict", table: "*1,*A,*2,*3", ids: "*1,*2,*3", strict: true, moves: 1, moved: 1},
+		{name: "unmanaged rules around strict", table: "*3,*A,*1,*2,*B", ids: "*1,*2,*3", strict: true, moves: 1, moved: 1},
+		{name: "run kept in place", table: "*5,*1,*2,*3,*4", ids: "*1,*2,*3,*4,*5", moves: 1, moved: 1},
+		{name: "run kept in place strict", table: "*5,*A,*1,*2,*3,*B,*4", ids: "*1,*2,*3,*4,*5", strict: true, moves: 1, moved: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, ids := splitIDs(tt.table), splitIDs(tt.ids)
+			moves := PlanMoves(tableOf(table...), ids, tt.strict)
+
+			movedIDs := map[string]bool{}
+			for _, m := range moves {
+				for _, id := range m.IDs {
+					movedIDs[id] = true
+				}
+			}
+			if len(moves) != tt.moves || len(movedIDs) != tt.moved {
+				t.Errorf("PlanMoves() = %v, want %d moves of %d rules", moves, tt.moves, tt.moved)
+			}
+
+			if !tt.strict {
+				pos := map[string]int{}
+				for i, id := range table {
+					pos[id] = i
+				}
+				positions := make([]int, 0, len(ids))
+				for _, id := range ids {
+					positions = append(positions, pos[id])
+				}
+				if want := len(ids) - longestIncreasing(positions); len(movedIDs) != want {
+					t.Errorf("PlanMoves() = %v, which moves %d rules instead of %d", moves, len(movedIDs), want)
+				}
+			}
+
+			result := applyMoves(t, table, moves)
+			if tt.strict && !ContainsSequence(result, ids) || !ContainsSubsequence(result, ids) {
+				t.Errorf("PlanMoves() = %v, which yields [%s] instead of the ordering [%s]", moves, strings.Join(result, ","), tt.ids)
+			}
+			// rules which are not moved, managed or not, keep their order
+			if got, want := without(result, movedIDs), without(table, movedIDs); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("PlanMoves() = %v, which reorders rules which are not moved: [%s], want [%s]", moves, strings.Join(got, ","), strings.Join(want, ","))
+			}
+		})
+	}
+}
+
+// longestIncreasing returns the length of the longest increasing subsequence
+// of positions, computed the slow and obvious way.
+func longestIncreasing(positions []int) int {
+	best := 0
+	lengths := make([]int, len(positions))
+	for i := range positions {
+		lengths[i] = 1
+		for j := 0; j < i; j++ {
+			if positions[j] < positions[i] && lengths[j]+1 > lengths[i] {
+				lengths[i] = lengths[j] + 1
+			}
+		}
+		if lengths[i] > best {
+			best = lengths[i]
+		}
+	}
+	return best
+}
+
+func TestStableRules(t *testing.T) {
+	tests := []struct {
+		table string
+		ids   string
+	}{
+		{table: "", ids: ""},
+		{table: "*1,*2,*3", ids: "*1,*2,*3"},
+		{table: "*1,*2,*3", ids: "*3,*2,*1"},
+		{table: "*1,*2,*3,*4,*5,*6", ids: "*2,*1,*4,*3,*6,*5"},
+		{table: "*1,*2,*3,*4,*5,*6,*7", ids: "*7,*1,*6,*2,*5,*3,*4"},
+		{table: "*1,*2,*3,*4,*5,*6,*7,*8", ids: "*4,*8,*1,*5,*2,*6,*3,*7"},
+		{table: "*1,*2", ids: "*3,*1,*4,*2"},
+	}
+	for _, tt := range tests {
+		pos := map[string]int{}
+		for i, id := range splitIDs(tt.table) {
+			pos[id] = i
+		}
+		ids := splitIDs(tt.ids)
+
+		stable := stableRules(pos, ids)
+
+		var positions, stablePositions []int
+		for _, id := range ids {
+			p, ok := pos[id]
+			if !ok {
+				if stable[id] {
+					t.Errorf("stableRules([%s]) holds %s, which is not part of the table", tt.ids, id)
+				}
+				continue
+			}
+			positions = append(positions, p)
+			if stable[id] {
+				stablePositions = append(stablePositions, p)
+			}
+		}
+		if want := longestIncreasing(positions); len(stable) != want || longestIncreasing(stablePositions) != len(stablePositions) {
+			t.Errorf("stableRules([%s]) = %v, want %d rules in increasing order", tt.ids, stable, want)
+		}
+	}
+}
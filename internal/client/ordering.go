@@ -0,0 +1,362 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// maxOrderAttempts bounds how often OrderRules moves rules before giving up.
+const maxOrderAttempts = 3
+
+// orderRetryDelay is how long OrderRules waits for the first round of moves to
+// become visible before moving the rules again. It grows linearly with each
+// further attempt.
+const orderRetryDelay = 500 * time.Millisecond
+
+// orderPollInterval is the interval after which WaitForRuleOrder polls the
+// rule table for the first time. It doubles with every further poll, up to
+// maxOrderPollInterval.
+const orderPollInterval = 100 * time.Millisecond
+
+// maxOrderPollInterval bounds the interval between two polls of
+// WaitForRuleOrder.
+const maxOrderPollInterval = 1600 * time.Millisecond
+
+// OrderingOpts control which rules are taken into account when comparing the
+// ordering of a rule table with the desired ordering.
+type OrderingOpts struct {
+	// Chain restricts the comparison to the rules of a single chain if
+	// non-empty.
+	Chain string
+	// Strict requires the rules to be directly adjacent to each other.
+	Strict bool
+	// IgnoreDynamic excludes dynamic rules, e.g. those added by UPnP or
+	// hotspot, which come and go outside of Terraform's control.
+	IgnoreDynamic bool
+	// IgnoreDisabled excludes disabled rules which are not part of the
+	// ordering, as rules are commonly disabled temporarily.
+	IgnoreDisabled bool
+}
+
+// Filter returns the rules which are taken into account according to opts.
+// The rules with the given IDs are always kept. Rules of other chains are
+// expected to be excluded by the caller already.
+func (o OrderingOpts) Filter(rules []FirewallRule, ids []string) []FirewallRule {
+	if !o.IgnoreDynamic && !o.IgnoreDisabled {
+		return rules
+	}
+
+	managed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+
+	filtered := make([]FirewallRule, 0, len(rules))
+	for _, rule := range rules {
+		ignored := (o.IgnoreDynamic && rule.Dynamic == "true") || (o.IgnoreDisabled && rule.Disabled == "true")
+		if managed[rule.ID] || !ignored {
+			filtered = append(filtered, rule)
+		}
+	}
+	return linkRules(filtered)
+}
+
+// OrderingError is returned if the desired ordering could not be established,
+// e.g. because rules are concurrently being moved by someone else.
+type OrderingError struct {
+	RuleType string
+	Attempts int
+	Expected []string
+	Observed []string
+}
+
+func (e *OrderingError) Error() string {
+	return fmt.Sprintf("ordering of %s rules did not converge after %d attempt(s): expected [%s], observed [%s]",
+		e.RuleType, e.Attempts, strings.Join(e.Expected, ", "), strings.Join(e.Observed, ", "))
+}
+
+// ChainMismatchError is returned if the rules passed to a single move or
+// ordering of a NAT table belong to different chains. Ordering srcnat and
+// dstnat rules relative to each other has no effect on how packets are
+// processed, and RouterOS rejects some of the resulting moves.
+type ChainMismatchError struct {
+	RuleType string
+	// Chains maps the ID of every passed rule to its chain.
+	Chains map[string]string
+	// IDs lists the passed rules in their original order.
+	IDs []string
+}
+
+func (e *ChainMismatchError) Error() string {
+	rules := make([]string, 0, len(e.IDs))
+	for _, id := range e.IDs {
+		rules = append(rules, fmt.Sprintf("%s (%s)", id, e.Chains[id]))
+	}
+	return fmt.Sprintf("all %s rules of an ordering must belong to the same chain, got [%s]. "+
+		"Split the rules into one ordering per chain", e.RuleType, strings.Join(rules, ", "))
+}
+
+// isNATTable reports whether ruleType refers to a NAT table, whose chains are
+// evaluated independently of each other.
+func isNATTable(ruleType string) bool {
+	return ruleType == "nat" || ruleType == "bridge-nat" || strings.HasSuffix(ruleType, "/nat")
+}
+
+// checkSameChain returns a *ChainMismatchError if ruleType is a NAT table and
+// the rules with the given IDs do not all belong to the same chain. Rules
+// which do not exist are left for the subsequent move to report. rules is the
+// current table of ruleType, it is read from the device if nil.
+func (c *Client) checkSameChain(ctx context.Context, ruleType string, rules []FirewallRule, ids []string) error {
+	if !isNATTable(ruleType) || len(ids) < 2 {
+		return nil
+	}
+
+	if rules == nil {
+		var err error
+		if rules, err = c.GetRulesOfType(ctx, ruleType); err != nil {
+			return err
+		}
+	}
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	chains := make(map[string]string, len(ids))
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if wanted[rule.ID] {
+			chains[rule.ID] = rule.Chain
+			seen[rule.Chain] = true
+		}
+	}
+
+	if len(seen) > 1 {
+		return &ChainMismatchError{RuleType: ruleType, Chains: chains, IDs: ids}
+	}
+	return nil
+}
+
+// OrderRules moves the rules with the given IDs so that they appear in the
+// given order, see RuleOrderExists for the meaning of opts. Only the moves
+// planned by PlanMoves are performed, so rules which are already in place are
+// not touched. After every round of moves, the table is read again to verify
+// the result. As RouterOS may not reflect moves immediately, the table is
+// polled for a while before the rules are moved again, up to maxOrderAttempts
+// times. It returns
+// the number of moves which were performed. If the ordering still does not
+// match afterwards, an *OrderingError describing the observed ordering is
+// returned.
+//
+// The move lock of the table is held throughout, so that concurrent orderings
+// of the same table do not interleave their moves.
+func (c *Client) OrderRules(ctx context.Context, ruleType string, ids []string, opts OrderingOpts) (int, error) {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return 0, err
+	}
+
+	unlock, err := c.lockTable(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	seq := make([]FirewallRule, 0, len(ids))
+	for _, id := range ids {
+		seq = append(seq, FirewallRule{ID: id})
+	}
+
+	moves := 0
+	for attempt := 0; ; attempt++ {
+		var match bool
+		if attempt == 0 {
+			match, err = c.RuleOrderExists(ctx, ruleType, seq, opts)
+		} else {
+			match, err = c.WaitForRuleOrder(ctx, ruleType, ids, opts, time.Duration(attempt)*orderRetryDelay)
+		}
+		if err != nil {
+			return moves, err
+		}
+		if match {
+			return moves, nil
+		}
+
+		if attempt == maxOrderAttempts {
+			rules, err := c.GetRulesOfChain(ctx, ruleType, opts.Chain)
+			if err != nil {
+				return moves, err
+			}
+			return moves, &OrderingError{
+				RuleType: ruleType,
+				Attempts: attempt,
+				Expected: ids,
+				Observed: ObservedOrdering(ids, opts.Filter(rules, ids), opts.Strict),
+			}
+		}
+
+		if attempt > 0 {
+			recordRetry()
+			tflog.Warn(ctx, "Rule ordering not in place after move, retrying", map[string]interface{}{
+				"rule_type": ruleType,
+				"attempt":   attempt + 1,
+			})
+		}
+
+		rules, err := c.GetRulesOfChain(ctx, ruleType, opts.Chain)
+		if err != nil {
+			return moves, err
+		}
+
+		planned := PlanMoves(opts.Filter(rules, ids), ids, opts.Strict)
+		if len(planned) == 0 {
+			// the ordering is not in place, so something has to move
+			planned = []Move{{IDs: ids, Target: End}}
+		}
+		for _, m := range planned {
+			if _, err := c.moveRules(ctx, ruleType, p, nil, m.IDs, m.Target); err != nil {
+				return moves, err
+			}
+			moves++
+		}
+	}
+}
+
+// ObservedOrdering returns the order of the rules with the given IDs as found
+// in rules. IDs which are not part of rules are omitted.
+//
+// In strict mode, the result is the slice of the rule table spanning from the
+// first to the last of the given rules, i.e. any other rules which have been
+// inserted in between are included. Otherwise, only the given rules are
+// returned. If the desired ordering is intact, the result is therefore
+// identical to ids in both modes.
+func ObservedOrdering(ids []string, rules []FirewallRule, strict bool) []string {
+	managed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+
+	first, last := -1, -1
+	for i, rule := range rules {
+		if managed[rule.ID] {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	actual := []string{}
+	if first == -1 {
+		return actual
+	}
+	for _, rule := range rules[first : last+1] {
+		if strict || managed[rule.ID] {
+			actual = append(actual, rule.ID)
+		}
+	}
+	return actual
+}
+
+// UnmanagedBetween returns the IDs of the rules which are placed between the
+// first and the last of the rules with the given IDs, but are not among them.
+// Only rules of the chains of the given rules are taken into account, as
+// rules of other chains do not affect how packets traverse them.
+func UnmanagedBetween(ids []string, rules []FirewallRule) []string {
+	managed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+
+	chains := map[string]bool{}
+	first, last := -1, -1
+	for i, rule := range rules {
+		if managed[rule.ID] {
+			chains[rule.Chain] = true
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	unmanaged := []string{}
+	if first == -1 {
+		return unmanaged
+	}
+	for _, rule := range rules[first : last+1] {
+		if !managed[rule.ID] && chains[rule.Chain] {
+			unmanaged = append(unmanaged, rule.ID)
+		}
+	}
+	return unmanaged
+}
+
+// WaitForRuleOrder polls the rule table until the rules with the given IDs
+// appear in the given order, see RuleOrderExists, or until timeout elapses.
+// The table is read from the device on every poll, bypassing the cache, so
+// that moves which RouterOS applies with a delay are observed. The interval
+// between polls grows exponentially, and after the first poll the full table
+// is only read again once TableChanged reports that its ordering changed. It
+// reports whether the ordering appeared in time.
+func (c *Client) WaitForRuleOrder(ctx context.Context, ruleType string, ids []string, opts OrderingOpts, timeout time.Duration) (bool, error) {
+	seq := make([]FirewallRule, 0, len(ids))
+	for _, id := range ids {
+		seq = append(seq, FirewallRule{ID: id})
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := orderPollInterval
+	for poll := 0; ; poll++ {
+		changed := true
+		if poll > 0 {
+			var err error
+			changed, err = c.TableChanged(ctx, ruleType, opts.Chain)
+			if err != nil {
+				return false, err
+			}
+		}
+		if changed {
+			c.cache.invalidate()
+			match, err := c.RuleOrderExists(ctx, ruleType, seq, opts)
+			if err != nil || match {
+				return match, err
+			}
+		}
+		if !time.Now().Before(deadline) {
+			return false, nil
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		if interval *= 2; interval > maxOrderPollInterval {
+			interval = maxOrderPollInterval
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/rostest"
+)
+
+const filterMenu = "/ip/firewall/filter"
+
+// newTestDevice starts a fake device which is closed at the end of the test,
+// and returns it along with a client connected to it.
+func newTestDevice(t *testing.T) (*rostest.Server, *client.Client) {
+	t.Helper()
+
+	server, err := rostest.NewServer()
+	if err != nil {
+		t.Fatalf("unable to start fake device: %s", err)
+	}
+	t.Cleanup(server.Close)
+
+	c, err := client.New(server.ClientOpts())
+	if err != nil {
+		t.Fatalf("unable to create client: %s", err)
+	}
+	return server, c
+}
+
+// newOrderingTest starts a fake device holding three filter rules and returns
+// it along with a client connected to it and the IDs of the rules in order.
+func newOrderingTest(t *testing.T) (*rostest.Server, *client.Client, []string) {
+	t.Helper()
+
+	server, c := newTestDevice(t)
+	ids := server.Add(filterMenu,
+		map[string]string{"chain": "input", "action": "accept"},
+		map[string]string{"chain": "input", "action": "accept"},
+		map[string]string{"chain": "input", "action": "drop"},
+	)
+	return server, c, ids
+}
+
+func TestOrderRulesRetriesLaggingMove(t *testing.T) {
+	server, c, ids := newOrderingTest(t)
+	// moves only become visible once they have been made a second time, as
+	// the stale table served in the meantime is taken before every move
+	server.SetMoveLag(time.Hour)
+
+	want := []string{ids[2], ids[0], ids[1]}
+	moves, err := c.OrderRules(context.Background(), "filter", want, client.OrderingOpts{Strict: true})
+	if err != nil {
+		t.Fatalf("OrderRules() returned error: %s", err)
+	}
+	if moves != 2 {
+		t.Errorf("OrderRules() performed %d moves, want 2", moves)
+	}
+	if got := server.IDs(filterMenu); !reflect.DeepEqual(got, want) {
+		t.Errorf("table = %v, want %v", got, want)
+	}
+}
+
+func TestOrderRulesMoveNeverLands(t *testing.T) {
+	server, c, ids := newOrderingTest(t)
+	server.DropCommand(filterMenu, "move", true)
+
+	want := []string{ids[2], ids[0], ids[1]}
+	moves, err := c.OrderRules(context.Background(), "filter", want, client.OrderingOpts{Strict: true})
+
+	var orderErr *client.OrderingError
+	if !errors.As(err, &orderErr) {
+		t.Fatalf("OrderRules() returned %v, want *OrderingError", err)
+	}
+	if orderErr.Attempts != client.MaxOrderAttempts {
+		t.Errorf("Attempts = %d, want %d", orderErr.Attempts, client.MaxOrderAttempts)
+	}
+	if !reflect.DeepEqual(orderErr.Expected, want) {
+		t.Errorf("Expected = %v, want %v", orderErr.Expected, want)
+	}
+	if !reflect.DeepEqual(orderErr.Observed, ids) {
+		t.Errorf("Observed = %v, want %v", orderErr.Observed, ids)
+	}
+	if moves != client.MaxOrderAttempts {
+		t.Errorf("OrderRules() performed %d moves, want %d", moves, client.MaxOrderAttempts)
+	}
+}
+
+func TestOrderRulesContextCanceled(t *testing.T) {
+	server, c, ids := newOrderingTest(t)
+	server.DropCommand(filterMenu, "move", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// cancel while OrderRules waits for the first round of moves to land
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.OrderRules(ctx, "filter", []string{ids[2], ids[0], ids[1]}, client.OrderingOpts{Strict: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("OrderRules() returned %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("OrderRules() returned after %s, want it to return once canceled", elapsed)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ownerTagRegexp matches the workspace tag which is appended to the comment of
+// every object created by this provider, e.g. `my comment [tf:production]`.
+var ownerTagRegexp = regexp.MustCompile(`\s*\[tf:([^\]]+)\]$`)
+
+// OwnershipError is returned when attempting to modify or delete an object
+// which was created from a different workspace.
+type OwnershipError struct {
+	Owner     string
+	Workspace string
+}
+
+func (e *OwnershipError) Error() string {
+	return fmt.Sprintf("object is owned by workspace '%s' and may not be modified from workspace '%s'. "+
+		"Set 'allow_cross_workspace' in the provider configuration to override this check", e.Owner, e.Workspace)
+}
+
+// tagComment prepends the client's comment prefix to comment and appends its
+// workspace tag.
+func (c *Client) tagComment(comment string) string {
+	if c.workspace == "" {
+		return c.prefixComment(comment)
+	}
+	comment, _ = splitOwnerTag(comment)
+	comment = c.prefixComment(comment)
+	if comment == "" {
+		return fmt.Sprintf("[tf:%s]", c.workspace)
+	}
+	return fmt.Sprintf("%s [tf:%s]", comment, c.workspace)
+}
+
+// prefixComment prepends the client's comment prefix to comment, unless it is
+// prefixed already.
+func (c *Client) prefixComment(comment string) string {
+	return c.commentPrefix + strings.TrimPrefix(comment, c.commentPrefix)
+}
+
+// untagComment separates the user supplied part of a comment from the comment
+// prefix and the workspace tag, see splitOwnerTag.
+func (c *Client) untagComment(comment string) (string, string) {
+	comment, owner := splitOwnerTag(comment)
+	return strings.TrimPrefix(comment, c.commentPrefix), owner
+}
+
+// isManagedComment reports whether comment marks an object as created by a
+// client of the same configuration, i.e. it carries the client's comment
+// prefix or its workspace tag.
+func (c *Client) isManagedComment(comment string) bool {
+	comment, owner := splitOwnerTag(comment)
+	if c.commentPrefix != "" && strings.HasPrefix(comment, c.commentPrefix) {
+		return true
+	}
+	return owner != "" && owner == c.workspace
+}
+
+// splitOwnerTag separates the workspace tag from the user supplied part of a
+// comment. The returned owner is empty if the comment is not tagged.
+func splitOwnerTag(comment string) (string, string) {
+	m := ownerTagRegexp.FindStringSubmatchIndex(comment)
+	if m == nil {
+		return comment, ""
+	}
+	return comment[:m[0]], comment[m[2]:m[3]]
+}
+
+// checkOwnership verifies that the object at path p is either untagged or
+// tagged with the client's workspace. Objects which do not exist pass the
+// check.
+func (c *Client) checkOwnership(ctx context.Context, p string) error {
+	if c.allowCrossWorkspace {
+		return nil
+	}
+
+	var obj struct {
+		Comment string `json:"comment"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, p, nil, &obj)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, owner := splitOwnerTag(obj.Comment); owner != "" && owner != c.workspace {
+		return &OwnershipError{Owner: owner, Workspace: c.workspace}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RequiredPolicies lists the policies the group of the configured user must
+// grant to manage the firewall through the REST API. RouterOS versions which
+// predate the `rest-api` policy authorize REST requests by the `api` policy
+// instead, see missingPolicies.
+var RequiredPolicies = []string{"read", "write", "api", "rest-api"}
+
+// MissingPoliciesError is returned if the group of the configured user does
+// not grant all of RequiredPolicies.
+type MissingPoliciesError struct {
+	User    string
+	Group   string
+	Missing []string
+}
+
+func (e *MissingPoliciesError) Error() string {
+	return fmt.Sprintf("user '%s' belongs to the group '%s', which lacks the %s policies required to manage firewall rules. "+
+		"Grant them to the group, see '/user group print where name=%s', or assign the user to a group which has them",
+		e.User, e.Group, strings.Join(e.Missing, ", "), e.Group)
+}
+
+// CheckPolicies returns a *MissingPoliciesError if the group of the
+// configured user does not grant all of RequiredPolicies. Policies which the
+// device does not know are not required, so that `rest-api` is only demanded
+// of versions which have it, and `api` only of those which do not. The check
+// is skipped if the user is not known, e.g. as credentials are passed as an
+// authorization header, or is not a local user of the device, e.g. as it is
+// authenticated by RADIUS.
+func (c *Client) CheckPolicies(ctx context.Context) error {
+	if c.username == "" {
+		return nil
+	}
+
+	var users []struct {
+		Group string `json:"group"`
+	}
+	if err := c.print(ctx, "/user", []string{"name=" + c.username}, []string{"group"}, &users); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	var groups []struct {
+		Policy string `json:"policy"`
+	}
+	if err := c.print(ctx, "/user/group", []string{"name=" + users[0].Group}, []string{"policy"}, &groups); err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	missing := missingPolicies(groups[0].Policy)
+	if len(missing) == 0 {
+		return nil
+	}
+	return &MissingPoliciesError{User: c.username, Group: users[0].Group, Missing: missing}
+}
+
+// missingPolicies returns those of RequiredPolicies which are not granted by
+// policy, the comma-separated policies of a group as reported by RouterOS,
+// e.g. `read,write,!api,!rest-api`. Denied policies are prefixed with `!`.
+func missingPolicies(policy string) []string {
+	known := map[string]bool{}
+	for _, p := range strings.Split(policy, ",") {
+		p = strings.TrimSpace(p)
+		known[strings.TrimPrefix(p, "!")] = !strings.HasPrefix(p, "!")
+	}
+	_, hasRESTPolicy := known["rest-api"]
+
+	var missing []string
+	for _, p := range RequiredPolicies {
+		if p == "api" && hasRESTPolicy {
+			continue
+		}
+		if granted, ok := known[p]; ok && !granted || !ok && p == "api" {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,167 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// endOfTable is a rule ID which never exists on the device. RouterOS places
+// moved rules at the very end of the table when given an unknown destination.
+const endOfTable = "*ffffff"
+
+type positionKind int
+
+const (
+	positionEnd positionKind = iota
+	positionStart
+	positionBefore
+	positionAfter
+)
+
+// Position describes where a set of moved rules should be placed within their
+// rule table.
+type Position struct {
+	kind positionKind
+	id   string
+}
+
+var (
+	// Start places rules at the beginning of the table.
+	Start = Position{kind: positionStart}
+	// End places rules at the end of the table.
+	End = Position{kind: positionEnd}
+)
+
+// Before places rules directly in front of the rule with the given ID.
+func Before(id string) Position {
+	return Position{kind: positionBefore, id: id}
+}
+
+// After places rules directly behind the rule with the given ID.
+func After(id string) Position {
+	return Position{kind: positionAfter, id: id}
+}
+
+func (p Position) String() string {
+	switch p.kind {
+	case positionStart:
+		return "start"
+	case positionBefore:
+		return fmt.Sprintf("before %s", p.id)
+	case positionAfter:
+		return fmt.Sprintf("after %s", p.id)
+	default:
+		return "end"
+	}
+}
+
+// resolvePosition translates a position into the rule ID which RouterOS
+// expects as the `destination` of a move command. Rules which are part of the
+// move itself are never used as a destination. rules is the current table of
+// ruleType, it is read from the device if nil and needed.
+func (c *Client) resolvePosition(ctx context.Context, ruleType string, rules []FirewallRule, ids []string, p Position) (string, error) {
+	if p.kind == positionBefore || p.kind == positionAfter {
+		if err := ValidateID(p.id); err != nil {
+			return "", err
+		}
+	}
+
+	switch p.kind {
+	case positionEnd:
+		return endOfTable, nil
+	case positionBefore:
+		return p.id, nil
+	}
+
+	moved := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		moved[id] = true
+	}
+
+	if rules == nil {
+		var err error
+		if rules, err = c.GetRulesOfType(ctx, ruleType); err != nil {
+			return "", err
+		}
+	}
+
+	start := 0
+	if p.kind == positionAfter {
+		start = -1
+		for i, rule := range rules {
+			if rule.ID == p.id {
+				start = i + 1
+				break
+			}
+		}
+		if start == -1 {
+			return "", fmt.Errorf("unable to find rule of type '%s' with id: '%s'", ruleType, p.id)
+		}
+	}
+
+	for _, rule := range rules[start:] {
+		if !moved[rule.ID] {
+			return rule.ID, nil
+		}
+	}
+
+	return endOfTable, nil
+}
+
+// applyMove returns rules as they are ordered after moving the rules with the
+// given IDs in front of the rule with the ID destination, like RouterOS does.
+// A destination which does not exist, or which is moved itself, denotes the
+// end of the table.
+func applyMove(rules []FirewallRule, ids []string, destination string) []FirewallRule {
+	byID := make(map[string]FirewallRule, len(rules))
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+	}
+	isMoved := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		isMoved[id] = true
+	}
+
+	rest := make([]FirewallRule, 0, len(rules))
+	for _, rule := range rules {
+		if !isMoved[rule.ID] {
+			rest = append(rest, rule)
+		}
+	}
+
+	at := len(rest)
+	for i, rule := range rest {
+		if rule.ID == destination {
+			at = i
+			break
+		}
+	}
+
+	result := make([]FirewallRule, 0, len(rules))
+	result = append(result, rest[:at]...)
+	for _, id := range ids {
+		if rule, ok := byID[id]; ok {
+			result = append(result, rule)
+		}
+	}
+	result = append(result, rest[at:]...)
+	return linkRules(result)
+}
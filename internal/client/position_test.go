@@ -0,0 +1,74 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyMove(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []string
+		destination string
+		want        []string
+	}{
+		{
+			name:        "in front of a rule",
+			ids:         []string{"*4", "*2"},
+			destination: "*1",
+			want:        []string{"*4", "*2", "*1", "*3", "*5"},
+		},
+		{
+			name:        "to the end",
+			ids:         []string{"*1", "*3"},
+			destination: endOfTable,
+			want:        []string{"*2", "*4", "*5", "*1", "*3"},
+		},
+		{
+			name:        "in front of a moved rule",
+			ids:         []string{"*2", "*3"},
+			destination: "*3",
+			want:        []string{"*1", "*4", "*5", "*2", "*3"},
+		},
+		{
+			name:        "unknown rules are skipped",
+			ids:         []string{"*9", "*5"},
+			destination: "*2",
+			want:        []string{"*1", "*5", "*2", "*3", "*4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyMove(tableOf("*1", "*2", "*3", "*4", "*5"), tt.ids, tt.destination)
+			ids := make([]string, 0, len(got))
+			for _, rule := range got {
+				ids = append(ids, rule.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("applyMove(%v, %q) = %v, want %v", tt.ids, tt.destination, ids, tt.want)
+			}
+			if len(got) > 1 && got[len(got)-2].Next != &got[len(got)-1] {
+				t.Errorf("applyMove(%v, %q) returned unlinked rules", tt.ids, tt.destination)
+			}
+		})
+	}
+}
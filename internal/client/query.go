@@ -0,0 +1,75 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// printRequest is the payload of the `print` command, which lists the objects
+// of a menu like a GET request, but additionally accepts a query.
+type printRequest struct {
+	Query    []string `json:".query,omitempty"`
+	Proplist []string `json:".proplist,omitempty"`
+}
+
+// print decodes the objects of the menu at p which match query into out. The
+// query is a list of RouterOS query words, e.g. `chain=forward`,
+// `dst-port=22`, `#|`, which is evaluated by the device, so that only
+// matching objects are transferred. If the words leave more than one
+// condition, all of them must match. Only the properties in proplist are
+// returned, or all of them if it is empty.
+func (c *Client) print(ctx context.Context, p string, query, proplist []string, out any) error {
+	req := printRequest{Query: query, Proplist: proplist}
+	return c.doJSON(ctx, http.MethodPost, p+"/print", req, out)
+}
+
+// QueryRules returns the properties of the rules of the given type which
+// match query, see print, in the order in which they appear in the table.
+// Values are compared to the properties as stored on the device, so comments
+// of managed rules carry their comment prefix and workspace tag.
+func (c *Client) QueryRules(ctx context.Context, ruleType string, query []string) ([]map[string]string, error) {
+	rules := []map[string]string{}
+
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return rules, err
+	}
+
+	err = c.print(ctx, p, query, nil, &rules)
+	for _, rule := range rules {
+		rule["comment"], _ = c.untagComment(rule["comment"])
+	}
+	return rules, err
+}
+
+// QueryAddressList returns the entries, including dynamic ones, of the
+// address list of IP version v with the given name which additionally match
+// query, see print.
+func (c *Client) QueryAddressList(ctx context.Context, v IPVersion, list string, query []string) ([]AddressListEntry, error) {
+	entries := []AddressListEntry{}
+
+	words := append([]string{"list=" + list}, query...)
+	err := c.print(ctx, AddressListMenu(v), words, nil, &entries)
+	for i := range entries {
+		entries[i].Comment, entries[i].Owner = c.untagComment(entries[i].Comment)
+	}
+	return entries, err
+}
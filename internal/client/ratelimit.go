@@ -0,0 +1,96 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxThrottleRetries bounds how often a request is repeated after the device
+// responded that it is overloaded.
+const maxThrottleRetries = 3
+
+// throttleRetryDelay is the delay before retrying a throttled request if the
+// device does not specify one. It doubles with each further attempt.
+const throttleRetryDelay = time.Second
+
+// maxRetryAfter caps the delay requested by the device via Retry-After.
+const maxRetryAfter = 30 * time.Second
+
+// isThrottled reports whether status indicates that the device is rate
+// limiting requests or too busy to handle them.
+func isThrottled(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// retryDelay returns how long to wait before retrying a throttled request,
+// honoring the Retry-After header if present.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+		d := time.Duration(s) * time.Second
+		if d > maxRetryAfter {
+			d = maxRetryAfter
+		}
+		return d
+	}
+	return throttleRetryDelay << attempt
+}
+
+// rateLimiter spaces requests evenly so that no more than a fixed number of
+// requests is sent per second. The zero value does not limit at all.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Second / time.Duration(perSecond)}
+}
+
+// wait blocks until the next request may be sent or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(at)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordEnv is the environment variable holding the path of the file which
+// requests and responses are recorded to, see ClientOpts.RecordPath.
+const RecordEnv = "ROS_FWFL_RECORD"
+
+// redacted replaces the values of sensitive properties in recordings.
+const redacted = "REDACTED"
+
+// redactedHost replaces the host of the device in recorded errors.
+const redactedHost = "DEVICE"
+
+// sensitiveProperties are substrings of the names of properties whose values
+// are never recorded, e.g. the password of a user or the secret of a PPP
+// profile.
+var sensitiveProperties = []string{"password", "secret", "passphrase", "private-key", "psk"}
+
+// Exchange is a single request to the REST API and the response to it, as
+// recorded to a transcript. Credentials are never part of an exchange: the
+// Authorization header and the host of the device are not recorded, the host
+// is replaced in transport errors, and the values of sensitive properties
+// are redacted from bodies.
+type Exchange struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	Status       int       `json:"status,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	// Error is the transport error of requests which did not receive a
+	// response.
+	Error string `json:"error,omitempty"`
+}
+
+// ReadTranscript reads the exchanges of a transcript written by a client with
+// ClientOpts.RecordPath set, in the order in which they were recorded.
+func ReadTranscript(r io.Reader) ([]Exchange, error) {
+	var exchanges []Exchange
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Exchange
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("malformed exchange in line %d of transcript: %w", line, err)
+		}
+		exchanges = append(exchanges, e)
+	}
+	return exchanges, scanner.Err()
+}
+
+// recorder is an http.RoundTripper which appends every exchange to a
+// transcript in the JSON Lines format, see Exchange.
+type recorder struct {
+	next http.RoundTripper
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// newRecorder returns a recorder appending to the file at path, which is
+// created if it does not exist. The file may be shared by several clients.
+func newRecorder(path string, next http.RoundTripper) (*recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open recording file at provided path %s: %w", path, err)
+	}
+	return &recorder{next: next, out: f}, nil
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := Exchange{Time: time.Now().UTC(), Method: req.Method, Path: req.URL.RequestURI()}
+
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			b, _ := io.ReadAll(body)
+			body.Close()
+			e.RequestBody = redactBody(b)
+		}
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		e.Error = redactHost(err, req.URL)
+		r.write(e)
+		return resp, err
+	}
+
+	// the body is buffered so that it can be recorded and still be read by
+	// the caller
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		e.Error = redactHost(err, req.URL)
+	}
+	e.Status = resp.StatusCode
+	e.ContentType = resp.Header.Get("Content-Type")
+	e.ResponseBody = redactBody(b)
+	r.write(e)
+	return resp, err
+}
+
+// write appends e to the transcript. Recording is best effort and never fails
+// the request.
+func (r *recorder) write(e Exchange) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.out.Write(append(b, '\n'))
+}
+
+// redactHost returns the message of the transport error err with every
+// mention of the device replaced, e.g. the URL of the request or the address
+// which could not be dialed, so that the transcript does not reveal where it
+// was recorded.
+func redactHost(err error, u *url.URL) string {
+	hosts := []string{u.Host, u.Hostname()}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Addr != nil {
+		addr := opErr.Addr.String()
+		hosts = append(hosts, addr)
+		if host, _, splitErr := net.SplitHostPort(addr); splitErr == nil {
+			hosts = append(hosts, host)
+		}
+	}
+	// longer hosts first, so that an address is not replaced before the
+	// address and port it is part of
+	sort.Slice(hosts, func(i, j int) bool { return len(hosts[i]) > len(hosts[j]) })
+
+	msg := err.Error()
+	for _, h := range hosts {
+		if h != "" {
+			msg = strings.ReplaceAll(msg, h, redactedHost)
+		}
+	}
+	return msg
+}
+
+// redactBody returns b with the values of all sensitive properties replaced.
+// Bodies which are not JSON are returned as-is.
+func redactBody(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return string(b)
+	}
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(b)
+	}
+	return string(redacted)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if isSensitive(k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child)
+		}
+	}
+	return v
+}
+
+func isSensitive(property string) bool {
+	property = strings.ToLower(property)
+	for _, s := range sensitiveProperties {
+		if strings.Contains(property, s) {
+			return true
+		}
+	}
+	return false
+}
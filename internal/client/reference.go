@@ -0,0 +1,201 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CommentReferencePrefix marks a rule reference which identifies a rule by its
+// comment instead of its ID, e.g. `comment:allow ssh`.
+const CommentReferencePrefix = "comment:"
+
+// ChainReferencePrefix marks a comment reference which only matches rules of
+// one chain, e.g. `chain:input,comment:allow ssh`. RouterOS separates list
+// values with commas, so chain names never contain one.
+const ChainReferencePrefix = "chain:"
+
+// CommentReference returns the reference to the rule with the given comment.
+func CommentReference(comment string) string {
+	return CommentReferencePrefix + comment
+}
+
+// ChainCommentReference returns the reference to the rule of the given chain
+// with the given comment.
+func ChainCommentReference(chain, comment string) string {
+	return ChainReferencePrefix + chain + "," + CommentReference(comment)
+}
+
+// ParseCommentReference splits a comment reference into its chain, which is
+// empty unless the reference is chain-qualified, and its comment. ok is false
+// if ref is no comment reference.
+func ParseCommentReference(ref string) (chain, comment string, ok bool) {
+	if strings.HasPrefix(ref, CommentReferencePrefix) {
+		return "", strings.TrimPrefix(ref, CommentReferencePrefix), true
+	}
+	if !strings.HasPrefix(ref, ChainReferencePrefix) {
+		return "", "", false
+	}
+	chain, comment, found := strings.Cut(strings.TrimPrefix(ref, ChainReferencePrefix), ","+CommentReferencePrefix)
+	if !found || chain == "" {
+		return "", "", false
+	}
+	return chain, comment, true
+}
+
+// IsCommentReference reports whether ref references a rule by its comment.
+func IsCommentReference(ref string) bool {
+	_, _, ok := ParseCommentReference(ref)
+	return ok
+}
+
+// ValidateReferenceComment returns an error if comment can not be referenced
+// by a comment reference.
+func ValidateReferenceComment(comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return errors.New("comment must not be empty")
+	}
+	return nil
+}
+
+// ValidateReferenceChain returns an error if chain can not qualify a comment
+// reference, see ChainCommentReference.
+func ValidateReferenceChain(chain string) error {
+	switch {
+	case chain == "":
+		return errors.New("chain must not be empty")
+	case strings.Contains(chain, ","):
+		return fmt.Errorf("chain '%s' must not contain a comma", chain)
+	case strings.TrimSpace(chain) != chain:
+		return fmt.Errorf("chain '%s' must not begin or end with whitespace", chain)
+	}
+	return nil
+}
+
+// ErrRuleNotFound is returned if a rule reference does not match any rule.
+var ErrRuleNotFound = errors.New("rule not found")
+
+// TableMismatchError is returned if a rule was looked up in one rule table,
+// but belongs to another. It wraps ErrRuleNotFound.
+type TableMismatchError struct {
+	ID string
+	// RuleType is the rule type the rule was looked up in.
+	RuleType string
+	// Table is the menu path of the rule table the rule belongs to.
+	Table string
+}
+
+func (e *TableMismatchError) Error() string {
+	return fmt.Sprintf("rule '%s' is not a %s rule, it belongs to the table '%s'. RouterOS IDs are only unique within a table, "+
+		"so rules of different tables cannot be ordered together", e.ID, e.RuleType, e.Table)
+}
+
+func (e *TableMismatchError) Unwrap() error {
+	return ErrRuleNotFound
+}
+
+// ResolveRuleReference returns the rule identified by ref, which is either a
+// RouterOS ID or a comment reference. A comment reference must match exactly
+// one rule of the table. If no rule of the table has the referenced ID, but a
+// rule of a related table does, e.g. a NAT rule for a filter table, a
+// *TableMismatchError naming that table is returned.
+func (c *Client) ResolveRuleReference(ctx context.Context, ruleType, ref string) (FirewallRule, error) {
+	rule, err := c.resolveRuleReference(ctx, ruleType, ref)
+	if errors.Is(err, ErrRuleNotFound) && !IsCommentReference(ref) {
+		for _, other := range relatedRuleTypes(ruleType) {
+			if found, e := c.GetRule(ctx, other, ref); e == nil {
+				return FirewallRule{}, &TableMismatchError{ID: ref, RuleType: ruleType, Table: found.Table}
+			}
+		}
+	}
+	return rule, err
+}
+
+func (c *Client) resolveRuleReference(ctx context.Context, ruleType, ref string) (FirewallRule, error) {
+	if !IsCommentReference(ref) {
+		return c.GetRule(ctx, ruleType, ref)
+	}
+
+	rules, err := c.GetRulesOfType(ctx, ruleType)
+	if err != nil {
+		return FirewallRule{}, err
+	}
+	return MatchRuleReference(rules, ruleType, ref)
+}
+
+// MatchRuleReference returns the rule identified by ref within rules, a rule
+// table of the given type which was read already, see ResolveRuleReference.
+func MatchRuleReference(rules []FirewallRule, ruleType, ref string) (FirewallRule, error) {
+	if !IsCommentReference(ref) {
+		for _, rule := range rules {
+			if rule.ID == ref {
+				return rule, nil
+			}
+		}
+		return FirewallRule{}, fmt.Errorf("%w: no rule of type '%s' has the id '%s'", ErrRuleNotFound, ruleType, ref)
+	}
+	chain, comment, _ := ParseCommentReference(ref)
+
+	var matches []FirewallRule
+	for _, rule := range rules {
+		if rule.Comment == comment && (chain == "" || rule.Chain == chain) {
+			matches = append(matches, rule)
+		}
+	}
+	where := fmt.Sprintf("of type '%s'", ruleType)
+	if chain != "" {
+		where = fmt.Sprintf("of type '%s' in chain '%s'", ruleType, chain)
+	}
+
+	switch len(matches) {
+	case 0:
+		return FirewallRule{}, fmt.Errorf("%w: no rule %s has the comment '%s'", ErrRuleNotFound, where, comment)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, rule := range matches {
+			ids = append(ids, rule.ID)
+		}
+		return FirewallRule{}, fmt.Errorf("comment '%s' is ambiguous, it matches the rules %s %s", comment, strings.Join(ids, ", "), where)
+	}
+}
+
+// relatedRuleTypes returns the other rule types of the group ruleType belongs
+// to, e.g. the other firewall tables for `filter`. Menu paths have none.
+func relatedRuleTypes(ruleType string) []string {
+	for _, group := range [][]string{RuleTypes, BridgeRuleTypes} {
+		for _, t := range group {
+			if t != ruleType {
+				continue
+			}
+			related := make([]string, 0, len(group)-1)
+			for _, other := range group {
+				if other != ruleType {
+					related = append(related, other)
+				}
+			}
+			return related
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GetRuleProperties returns all properties of a single rule, keyed by their
+// RouterOS name.
+func (c *Client) GetRuleProperties(ctx context.Context, ruleType, id string) (map[string]string, error) {
+	props := map[string]string{}
+
+	p, err := ruleObjectPath(ruleType, id)
+	if err != nil {
+		return props, err
+	}
+
+	err = c.doJSON(ctx, http.MethodGet, p, nil, &props)
+	props["comment"], _ = c.untagComment(props["comment"])
+	return props, err
+}
+
+// PlaceBeforeProperty may be passed to CreateRule with the ID of an existing
+// rule, which the new rule is then inserted in front of instead of being
+// added to the end of the table. RouterOS performs the insertion as part of
+// the creation, so the rule never takes effect at any other position.
+const PlaceBeforeProperty = "place-before"
+
+// CreateRule adds a new rule of the given type to the end of the table, or in
+// front of the rule given by PlaceBeforeProperty, and returns its properties
+// as reported by the device.
+func (c *Client) CreateRule(ctx context.Context, ruleType string, props map[string]string) (map[string]string, error) {
+	created := map[string]string{}
+
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return created, err
+	}
+
+	payload := copyProps(props)
+	payload["comment"] = c.tagComment(props["comment"])
+
+	err = c.doJSON(ctx, http.MethodPut, p, payload, &created)
+	created["comment"], _ = c.untagComment(created["comment"])
+	return created, err
+}
+
+// UpdateRule sets the passed properties on an existing rule. Properties which
+// are not part of props are left untouched.
+func (c *Client) UpdateRule(ctx context.Context, ruleType, id string, props map[string]string) (map[string]string, error) {
+	updated := map[string]string{}
+
+	p, err := ruleObjectPath(ruleType, id)
+	if err != nil {
+		return updated, err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return updated, err
+	}
+
+	payload := copyProps(props)
+	if comment, ok := props["comment"]; ok {
+		payload["comment"] = c.tagComment(comment)
+	}
+
+	err = c.doJSON(ctx, http.MethodPatch, p, payload, &updated)
+	updated["comment"], _ = c.untagComment(updated["comment"])
+	return updated, err
+}
+
+// DeleteRule removes a single rule.
+func (c *Client) DeleteRule(ctx context.Context, ruleType, id string) error {
+	p, err := ruleObjectPath(ruleType, id)
+	if err != nil {
+		return err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodDelete, p, nil, nil)
+}
+
+func ruleObjectPath(ruleType, id string) (string, error) {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return "", err
+	}
+	return objectPath(p, id)
+}
+
+func copyProps(props map[string]string) map[string]string {
+	c := make(map[string]string, len(props))
+	for k, v := range props {
+		c[k] = v
+	}
+	return c
+}
+
+// ListRuleProperties returns the properties of all rules of the given type, in
+// the order in which they appear in the table.
+func (c *Client) ListRuleProperties(ctx context.Context, ruleType string) ([]map[string]string, error) {
+	rules := []map[string]string{}
+
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return rules, err
+	}
+
+	err = c.doJSON(ctx, http.MethodGet, p, nil, &rules)
+	for _, rule := range rules {
+		rule["comment"], _ = c.untagComment(rule["comment"])
+	}
+	return rules, err
+}
+
+// ManagedRuleIDs returns the IDs of all rules of the given type which were
+// created by a client of the same configuration, i.e. whose comment carries
+// the comment prefix or the workspace tag of c.
+func (c *Client) ManagedRuleIDs(ctx context.Context, ruleType string) (map[string]bool, error) {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []FirewallRule
+	query := url.Values{".proplist": {".id,comment"}}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s?%s", p, query.Encode()), nil, &rules); err != nil {
+		return nil, err
+	}
+
+	managed := map[string]bool{}
+	for _, rule := range rules {
+		if c.isManagedComment(rule.Comment) {
+			managed[rule.ID] = true
+		}
+	}
+	return managed, nil
+}
+
+// Table is the content of a rule table, see GetTables.
+type Table struct {
+	// Rules holds the properties of all rules in their order within the
+	// table, see ListRuleProperties.
+	Rules []map[string]string
+	// Managed holds the IDs of the managed rules, see ManagedRuleIDs.
+	Managed map[string]bool
+}
+
+// GetTables returns the contents of the rule tables of the given types, keyed
+// by rule type. The tables are fetched in parallel, bounded by the client's
+// concurrency limit, which saves several round trips to devices with a high
+// latency.
+func (c *Client) GetTables(ctx context.Context, ruleTypes []string) (map[string]Table, error) {
+	tables := make([]Table, len(ruleTypes))
+	err := c.forEach(ctx, len(ruleTypes), func(ctx context.Context, i int) error {
+		rules, err := c.ListRuleProperties(ctx, ruleTypes[i])
+		if err != nil {
+			return fmt.Errorf("unable to read %s rules: %w", ruleTypes[i], err)
+		}
+		managed, err := c.ManagedRuleIDs(ctx, ruleTypes[i])
+		if err != nil {
+			return fmt.Errorf("unable to read %s rules: %w", ruleTypes[i], err)
+		}
+		tables[i] = Table{Rules: rules, Managed: managed}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]Table, len(ruleTypes))
+	for i, ruleType := range ruleTypes {
+		byType[ruleType] = tables[i]
+	}
+	return byType, nil
+}
+
+// FindRuleByComment returns the properties of the single rule of the given
+// type whose comment equals comment.
+func (c *Client) FindRuleByComment(ctx context.Context, ruleType, comment string) (map[string]string, error) {
+	rules, err := c.ListRuleProperties(ctx, ruleType)
+	if err != nil {
+		return nil, err
+	}
+
+	var match map[string]string
+	for _, rule := range rules {
+		if rule["comment"] != comment {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("found more than one rule of type '%s' with comment '%s'", ruleType, comment)
+		}
+		match = rule
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("unable to find rule of type '%s' with comment: '%s'", ruleType, comment)
+	}
+	return match, nil
+}
+
+// FindRuleByProperties returns the properties of the single rule of the given
+// type which has all properties of match with exactly the given values, e.g.
+// `{"chain": "forward", "action": "drop", "src-address": "10.0.0.0/8"}`.
+func (c *Client) FindRuleByProperties(ctx context.Context, ruleType string, match map[string]string) (map[string]string, error) {
+	rules, err := c.ListRuleProperties(ctx, ruleType)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []map[string]string
+	for _, rule := range rules {
+		if hasProperties(rule, match) {
+			matches = append(matches, rule)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: no rule of type '%s' has the properties %v", ErrRuleNotFound, ruleType, match)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, rule := range matches {
+			ids = append(ids, rule[".id"])
+		}
+		return nil, fmt.Errorf("the properties %v are ambiguous, they match the rules %s of type '%s'", match, strings.Join(ids, ", "), ruleType)
+	}
+}
+
+// hasProperties reports whether props contains all properties of match with
+// the same values.
+func hasProperties(props, match map[string]string) bool {
+	for k, v := range match {
+		if actual, ok := props[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SchedulerEntry is an entry of `/system/scheduler`.
+type SchedulerEntry struct {
+	ID   string `json:".id,omitempty"`
+	Name string `json:"name"`
+	// Interval is the time between runs of OnEvent. If StartTime is not set,
+	// the device uses the time of creation, so OnEvent first runs one
+	// interval after the entry was added.
+	Interval  string `json:"interval,omitempty"`
+	StartTime string `json:"start-time,omitempty"`
+	OnEvent   string `json:"on-event"`
+	Policy    string `json:"policy,omitempty"`
+	Comment   string `json:"comment"`
+	Disabled  string `json:"disabled,omitempty"`
+	NextRun   string `json:"next-run,omitempty"`
+}
+
+func (c *Client) GetSchedulerEntry(ctx context.Context, id string) (SchedulerEntry, error) {
+	var e SchedulerEntry
+	p, err := objectPath("/system/scheduler", id)
+	if err != nil {
+		return e, err
+	}
+	err = c.doJSON(ctx, http.MethodGet, p, nil, &e)
+	e.Comment, _ = c.untagComment(e.Comment)
+	return e, err
+}
+
+func (c *Client) CreateSchedulerEntry(ctx context.Context, e SchedulerEntry) (SchedulerEntry, error) {
+	var created SchedulerEntry
+	e.ID = ""
+	e.NextRun = ""
+	e.Comment = c.tagComment(e.Comment)
+	err := c.doJSON(ctx, http.MethodPut, "/system/scheduler", e, &created)
+	created.Comment, _ = c.untagComment(created.Comment)
+	return created, err
+}
+
+func (c *Client) DeleteSchedulerEntry(ctx context.Context, id string) error {
+	p, err := objectPath("/system/scheduler", id)
+	if err != nil {
+		return err
+	}
+	if err := c.checkOwnership(ctx, p); err != nil {
+		return err
+	}
+	return c.doJSON(ctx, http.MethodDelete, p, nil, nil)
+}
+
+// RuleCommand returns the console command which runs action, e.g. `disable`,
+// on the rule with the given ID, for use in scripts such as the OnEvent of a
+// SchedulerEntry.
+func RuleCommand(ruleType, action, id string) (string, error) {
+	p, err := rulePath(ruleType)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateID(id); err != nil {
+		return "", err
+	}
+	menu := strings.ReplaceAll(p, "/", " ")
+	return fmt.Sprintf("/%s %s [find where .id=%s]", strings.TrimSpace(menu), action, id), nil
+}
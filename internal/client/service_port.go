@@ -0,0 +1,68 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ServicePort is an entry of `/ip/firewall/service-port`, i.e. a connection
+// tracking helper. Service ports are built into RouterOS and can only be
+// enabled, disabled and have their ports changed.
+type ServicePort struct {
+	ID       string `json:".id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Ports    string `json:"ports,omitempty"`
+	Disabled string `json:"disabled,omitempty"`
+}
+
+// GetServicePort returns the service port with the given name. If there is no
+// such service port, an *APIError with status 404 is returned.
+func (c *Client) GetServicePort(ctx context.Context, name string) (ServicePort, error) {
+	ports := []ServicePort{}
+	query := url.Values{"name": {name}}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/ip/firewall/service-port?%s", query.Encode()), nil, &ports); err != nil {
+		return ServicePort{}, err
+	}
+	if len(ports) == 0 {
+		return ServicePort{}, &APIError{
+			Status:  http.StatusNotFound,
+			Message: "Not Found",
+			Detail:  fmt.Sprintf("no service port named '%s'", name),
+		}
+	}
+	return ports[0], nil
+}
+
+// UpdateServicePort sets the non-empty fields of s on the service port with
+// the ID s.ID.
+func (c *Client) UpdateServicePort(ctx context.Context, s ServicePort) (ServicePort, error) {
+	var updated ServicePort
+	p, err := objectPath("/ip/firewall/service-port", s.ID)
+	if err != nil {
+		return updated, err
+	}
+	// the name is read-only
+	s.ID, s.Name = "", ""
+	err = c.doJSON(ctx, http.MethodPatch, p, s, &updated)
+	return updated, err
+}
@@ -0,0 +1,153 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHTunnelOpts configures an SSH connection through which all requests to
+// the REST API are forwarded.
+type SSHTunnelOpts struct {
+	// Host is the address of the SSH server, optionally including the port.
+	// The port defaults to 22.
+	Host string
+	User string
+	// Key is the path to an unencrypted private key.
+	Key string
+	// KnownHosts is the path to a known_hosts file which the host key of the
+	// SSH server is verified against. Defaults to ~/.ssh/known_hosts.
+	KnownHosts string
+}
+
+// sshTunnel dials connections through a lazily established SSH connection.
+type sshTunnel struct {
+	addr   string
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func newSSHTunnel(opts SSHTunnelOpts, timeout time.Duration) (*sshTunnel, error) {
+	key, err := os.ReadFile(opts.Key)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read SSH key at provided path %s: %w", opts.Key, err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse SSH key at provided path %s: %w", opts.Key, err)
+	}
+
+	knownHostsFile := opts.KnownHosts
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("Could not determine default known_hosts file: %w", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read known_hosts file at path %s: %w", knownHostsFile, err)
+	}
+
+	addr := opts.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	return &sshTunnel{
+		addr: addr,
+		config: &ssh.ClientConfig{
+			User:            opts.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         timeout,
+		},
+	}, nil
+}
+
+// connect returns the SSH connection, establishing it if necessary.
+func (t *sshTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	tflog.Debug(ctx, "Establishing SSH tunnel", map[string]interface{}{
+		"ssh_host": t.addr,
+		"ssh_user": t.config.User,
+	})
+
+	dialer := net.Dialer{Timeout: t.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to SSH host %s: %w", t.addr, err)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, t.addr, t.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to establish SSH connection to %s: %w", t.addr, err)
+	}
+	t.client = ssh.NewClient(c, chans, reqs)
+	return t.client, nil
+}
+
+// reset drops the SSH connection c, unless it has already been replaced.
+func (t *sshTunnel) reset(c *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == c {
+		t.client.Close()
+		t.client = nil
+	}
+}
+
+// DialContext opens a connection to addr as seen from the SSH server. If the
+// SSH connection has been dropped in the meantime, it is re-established once.
+func (t *sshTunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		c, err := t.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := c.Dial(network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		t.reset(c)
+	}
+	return nil, fmt.Errorf("unable to forward connection to %s through SSH host %s: %w", addr, t.addr, lastErr)
+}
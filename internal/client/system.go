@@ -0,0 +1,44 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// SystemResource is the status of the device as reported by
+// `/system/resource`. Numeric properties are returned as reported by RouterOS.
+type SystemResource struct {
+	Version          string `json:"version"`
+	BoardName        string `json:"board-name"`
+	ArchitectureName string `json:"architecture-name"`
+	Uptime           string `json:"uptime"`
+	CPULoad          string `json:"cpu-load"`
+	FreeMemory       string `json:"free-memory"`
+	TotalMemory      string `json:"total-memory"`
+}
+
+// GetSystemResource returns the current status of the device. Unlike Version,
+// the device is asked on every call, so that it can serve as a health check.
+func (c *Client) GetSystemResource(ctx context.Context) (SystemResource, error) {
+	var res SystemResource
+	err := c.doJSON(ctx, http.MethodGet, "/system/resource", nil, &res)
+	return res, err
+}
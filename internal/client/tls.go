@@ -0,0 +1,72 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// parseFingerprint decodes a SHA-256 certificate fingerprint given as hex,
+// optionally separated by colons, e.g. as printed by
+// `openssl x509 -noout -fingerprint -sha256`.
+func parseFingerprint(s string) ([]byte, error) {
+	fingerprint, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(s), ":", ""))
+	if err != nil || len(fingerprint) != sha256.Size {
+		return nil, fmt.Errorf("Invalid certificate fingerprint %s, expected %d hex-encoded bytes", s, sha256.Size)
+	}
+	return fingerprint, nil
+}
+
+// verifyFingerprint returns a tls.Config.VerifyConnection callback which
+// accepts only connections whose leaf certificate matches fingerprint. Chain
+// and host name verification are skipped, as the certificate is pinned
+// exactly.
+func verifyFingerprint(fingerprint []byte) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return &FingerprintError{Want: fingerprint}
+		}
+		sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+		if !bytes.Equal(sum[:], fingerprint) {
+			return &FingerprintError{Got: sum[:], Want: fingerprint}
+		}
+		return nil
+	}
+}
+
+// FingerprintError is returned if the certificate presented by the device
+// does not match the pinned fingerprint, see ClientOpts.FingerprintSHA256.
+type FingerprintError struct {
+	// Got is the fingerprint of the presented certificate, or nil if the
+	// device presented none.
+	Got  []byte
+	Want []byte
+}
+
+func (e *FingerprintError) Error() string {
+	if e.Got == nil {
+		return "the server did not present a certificate"
+	}
+	return fmt.Sprintf("the server certificate's SHA-256 fingerprint %X does not match the pinned fingerprint %X", e.Got, e.Want)
+}
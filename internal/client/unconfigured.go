@@ -0,0 +1,38 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import "errors"
+
+// ErrConfigUnknown is returned by all requests of a client created by
+// NewUnconfigured.
+var ErrConfigUnknown = errors.New("the provider configuration depends on values which are only known after apply")
+
+// NewUnconfigured returns a client for a provider configuration which cannot
+// be determined yet, e.g. because the address of the device is the output of
+// another resource. The client never contacts any device, all of its requests
+// fail with ErrConfigUnknown. Consumers are expected to plan without the
+// device in this case, the configuration is always known during apply.
+func NewUnconfigured() *Client {
+	return &Client{
+		unconfigured: true,
+		concurrency:  DefaultConcurrency,
+		limiter:      newRateLimiter(0),
+	}
+}
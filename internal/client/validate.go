@@ -0,0 +1,104 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// IDRegexp matches RouterOS internal object IDs, e.g. `*1A`.
+var IDRegexp = regexp.MustCompile(`^\*[0-9A-Fa-f]+$`)
+
+// RuleTypes lists all firewall tables which rules can be read from and moved
+// within.
+var RuleTypes = []string{"filter", "nat", "mangle", "raw"}
+
+// BridgeRuleTypes lists the rule tables of bridges, which live at
+// `/interface/bridge/filter` and `/interface/bridge/nat`.
+var BridgeRuleTypes = []string{"bridge-filter", "bridge-nat"}
+
+// OrderableTables lists all tables whose items can be moved by their short
+// name. Besides rules, RouterOS keeps the order of address list entries and
+// layer7 protocols.
+var OrderableTables = append(append(append([]string{}, RuleTypes...), "address-list", "layer7-protocol"), BridgeRuleTypes...)
+
+// MenuPathRegexp matches absolute RouterOS menu paths, e.g.
+// `/ipv6/firewall/filter`. Any table with movable items can be addressed by
+// its menu path instead of one of OrderableTables.
+var MenuPathRegexp = regexp.MustCompile(`^(/[a-z0-9][a-z0-9-]*)+$`)
+
+// ValidateID returns an error if id is not a well-formed RouterOS object ID.
+// IDs are interpolated into request paths and comma-separated command
+// arguments, so anything else must never reach the device.
+func ValidateID(id string) error {
+	if !IDRegexp.MatchString(id) {
+		return fmt.Errorf("invalid RouterOS id '%s', expected an id of the form '*1A'", id)
+	}
+	return nil
+}
+
+func validateIDs(ids []string) error {
+	for _, id := range ids {
+		if err := ValidateID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateRuleType returns an error if ruleType is neither one of
+// OrderableTables nor an absolute menu path.
+func ValidateRuleType(ruleType string) error {
+	if MenuPathRegexp.MatchString(ruleType) {
+		return nil
+	}
+	for _, t := range OrderableTables {
+		if t == ruleType {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid rule type '%s', expected one of %v or a menu path such as '/ipv6/firewall/filter'", ruleType, OrderableTables)
+}
+
+// rulePath returns the REST path of the given rule table. Short table names
+// are relative to `/ip/firewall`, except for bridge tables which are relative
+// to `/interface/bridge`. Menu paths are used as-is.
+func rulePath(ruleType string) (string, error) {
+	if err := ValidateRuleType(ruleType); err != nil {
+		return "", err
+	}
+	if MenuPathRegexp.MatchString(ruleType) {
+		return ruleType, nil
+	}
+	if strings.HasPrefix(ruleType, "bridge-") {
+		return fmt.Sprintf("/interface/bridge/%s", strings.TrimPrefix(ruleType, "bridge-")), nil
+	}
+	return fmt.Sprintf("/ip/firewall/%s", ruleType), nil
+}
+
+// objectPath returns the REST path of the object with the given id in menu.
+func objectPath(menu, id string) (string, error) {
+	if err := ValidateID(id); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", menu, url.PathEscape(id)), nil
+}
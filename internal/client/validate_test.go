@@ -0,0 +1,323 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testRules is the rule table served by newRecordingClient for every firewall
+// table.
+var testRules = []map[string]string{
+	{".id": "*1", "chain": "input", "action": "accept", "comment": "allow ssh"},
+	{".id": "*2", "chain": "forward", "action": "accept", "comment": "allow ssh"},
+	{".id": "*A", "chain": "input", "action": "drop", "comment": "drop invalid"},
+}
+
+// recordedRequest is a request received by the server of newRecordingClient.
+type recordedRequest struct {
+	Method string
+	URL    *url.URL
+	Body   []byte
+}
+
+// requestLog keeps the requests received by a test device.
+type requestLog struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+// take returns and forgets the requests recorded so far.
+func (r *requestLog) take() []recordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	requests := r.requests
+	r.requests = nil
+	return requests
+}
+
+// newRecordingClient returns a client connected to a test device, which
+// serves testRules for every table and records all requests it receives.
+func newRecordingClient(t testing.TB) (*Client, *requestLog) {
+	t.Helper()
+
+	log := &requestLog{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.mu.Lock()
+		log.requests = append(log.requests, recordedRequest{Method: r.Method, URL: r.URL, Body: body})
+		log.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/rest/system/resource":
+			_ = json.NewEncoder(w).Encode(map[string]string{"version": "7.16 (stable)"})
+		case strings.HasSuffix(r.URL.Path, "/move"):
+			_ = json.NewEncoder(w).Encode([]string{})
+		default:
+			_ = json.NewEncoder(w).Encode(testRules)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(ClientOpts{HostURL: server.URL, Username: "admin", Password: "password", CA: ca})
+	if err != nil {
+		t.Fatalf("New() error = %s", err)
+	}
+	return c, log
+}
+
+// bodyKeys are the JSON keys which requests to firewall tables may carry.
+var bodyKeys = map[string]bool{
+	"numbers":     true,
+	"destination": true,
+	".proplist":   true,
+	".query":      true,
+}
+
+// checkRequests fails the test if a request left the given tables, addressed
+// anything but a command or a well-formed object of them, or carried
+// unexpected query parameters or JSON keys.
+func checkRequests(t *testing.T, menus []string, requests []recordedRequest) {
+	t.Helper()
+
+	for _, req := range requests {
+		p := req.URL.Path
+		if p == "/rest/system/resource" {
+			continue
+		}
+		if req.URL.RawPath != "" && req.URL.RawPath != req.URL.EscapedPath() {
+			t.Errorf("%s %s: path was escaped unexpectedly", req.Method, req.URL)
+		}
+		if path.Clean(p) != p {
+			t.Errorf("%s %s: path is not clean", req.Method, req.URL)
+		}
+		if !inTable(p, menus) {
+			t.Errorf("%s %s: request left the tables %v", req.Method, req.URL, menus)
+		}
+
+		if req.URL.Fragment != "" {
+			t.Errorf("%s %s: unexpected fragment", req.Method, req.URL)
+		}
+		for k, v := range req.URL.Query() {
+			switch {
+			case k == ".proplist" && len(v) == 1 && v[0] == ruleProperties:
+			case k == "chain" && len(v) == 1:
+			default:
+				t.Errorf("%s %s: unexpected query parameter %s=%v", req.Method, req.URL, k, v)
+			}
+		}
+
+		if len(req.Body) == 0 {
+			continue
+		}
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			t.Errorf("%s %s: body is not a JSON object: %s", req.Method, req.URL, req.Body)
+			continue
+		}
+		for k := range body {
+			if !bodyKeys[k] {
+				t.Errorf("%s %s: unexpected JSON key %q in %s", req.Method, req.URL, k, req.Body)
+			}
+		}
+		if raw, ok := body["numbers"]; ok {
+			var numbers string
+			if err := json.Unmarshal(raw, &numbers); err != nil {
+				t.Errorf("%s %s: numbers is not a string: %s", req.Method, req.URL, raw)
+				continue
+			}
+			for _, id := range strings.Split(numbers, ",") {
+				if !IDRegexp.MatchString(id) {
+					t.Errorf("%s %s: numbers holds the malformed id %q", req.Method, req.URL, id)
+				}
+			}
+		}
+	}
+}
+
+// inTable reports whether the request path p addresses one of the tables at
+// menus, a command of it or a well-formed object of it.
+func inTable(p string, menus []string) bool {
+	for _, menu := range menus {
+		if !strings.HasPrefix(p, "/rest"+menu) {
+			continue
+		}
+		switch rest := strings.TrimPrefix(p, "/rest"+menu); {
+		case rest == "", rest == "/print", rest == "/move":
+			return true
+		case strings.HasPrefix(rest, "/") && IDRegexp.MatchString(rest[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+func FuzzValidateID(f *testing.F) {
+	for _, id := range []string{"*1", "*1A", "*ffff", "1A", "*", "*1/../../system", "*1,*2", "*1?x=y", "*1#", "*1%2F", "*1\n", `*1","x":"`, "*G"} {
+		f.Add(id)
+	}
+
+	c, log := newRecordingClient(f)
+	f.Fuzz(func(t *testing.T, id string) {
+		valid := ValidateID(id) == nil
+		if valid != IDRegexp.MatchString(id) {
+			t.Fatalf("ValidateID(%q) = %v, disagrees with IDRegexp", id, valid)
+		}
+
+		p, err := objectPath("/ip/firewall/filter", id)
+		if valid {
+			if err != nil || p != "/ip/firewall/filter/"+url.PathEscape(id) {
+				t.Fatalf("objectPath(%q) = %q, %v", id, p, err)
+			}
+			if strings.ContainsAny(id, `,/?#%"\ `) {
+				t.Fatalf("ValidateID(%q) accepted a separator", id)
+			}
+		} else if err == nil {
+			t.Fatalf("objectPath(%q) = %q, want error", id, p)
+		}
+
+		err = c.MoveRules(context.Background(), "filter", []string{id}, Before("*A"))
+		requests := log.take()
+		if !valid {
+			if err == nil {
+				t.Fatalf("MoveRules(%q) succeeded", id)
+			}
+			for _, req := range requests {
+				if strings.HasSuffix(req.URL.Path, "/move") {
+					t.Fatalf("MoveRules(%q) sent %s %s", id, req.Method, req.URL)
+				}
+			}
+		}
+		checkRequests(t, []string{"/ip/firewall/filter"}, requests)
+	})
+}
+
+func FuzzRulePath(f *testing.F) {
+	for _, ruleType := range append(append([]string{"/ipv6/firewall/filter", "/ip/firewall/../../system", "filter/../nat", "filter?x", "/ip//firewall", "/IP/firewall", "nat#", "bridge-", "bridge-../x", ""}, OrderableTables...), RuleTypes...) {
+		f.Add(ruleType)
+	}
+
+	c, log := newRecordingClient(f)
+	f.Fuzz(func(t *testing.T, ruleType string) {
+		p, err := rulePath(ruleType)
+		if err != nil {
+			if _, err := c.GetRulesOfType(context.Background(), ruleType); err == nil {
+				t.Fatalf("GetRulesOfType(%q) succeeded", ruleType)
+			}
+			if requests := log.take(); len(requests) > 0 {
+				t.Fatalf("GetRulesOfType(%q) sent %d requests", ruleType, len(requests))
+			}
+			return
+		}
+
+		switch {
+		case p == ruleType && MenuPathRegexp.MatchString(ruleType):
+		case p == "/ip/firewall/"+ruleType, p == "/interface/bridge/"+strings.TrimPrefix(ruleType, "bridge-"):
+		default:
+			t.Fatalf("rulePath(%q) = %q", ruleType, p)
+		}
+
+		u, err := url.Parse("https://router.lan/rest" + p)
+		if err != nil {
+			t.Fatalf("rulePath(%q) = %q, which is no valid path: %s", ruleType, p, err)
+		}
+		if u.Path != "/rest"+p || u.RawQuery != "" || u.Fragment != "" || path.Clean(p) != p {
+			t.Fatalf("rulePath(%q) = %q, which does not address a single menu", ruleType, p)
+		}
+
+		if _, err := c.GetRulesOfType(context.Background(), ruleType); err != nil {
+			t.Fatalf("GetRulesOfType(%q) error = %s", ruleType, err)
+		}
+		checkRequests(t, []string{p}, log.take())
+	})
+}
+
+// firewallMenus are the menus of RuleTypes, which are looked up when a rule
+// is not found in the table it was referenced in.
+var firewallMenus = []string{"/ip/firewall/filter", "/ip/firewall/nat", "/ip/firewall/mangle", "/ip/firewall/raw"}
+
+func FuzzCommentReference(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"input", "allow ssh"},
+		{"forward", "allow ssh"},
+		{"", "drop invalid"},
+		{"input,comment:x", "y"},
+		{"a,b", "c"},
+		{"chain:input", "comment:allow ssh"},
+		{"*1", "*A"},
+		{"input", `","numbers":"*1,*2`},
+		{"../../system", "/../x?y#z"},
+		{" input ", ""},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	c, log := newRecordingClient(f)
+	f.Fuzz(func(t *testing.T, chain, comment string) {
+		ref := CommentReference(comment)
+		if gotChain, gotComment, ok := ParseCommentReference(ref); !ok || gotChain != "" || gotComment != comment {
+			t.Fatalf("ParseCommentReference(%q) = %q, %q, %v", ref, gotChain, gotComment, ok)
+		}
+
+		chained := ChainCommentReference(chain, comment)
+		gotChain, gotComment, ok := ParseCommentReference(chained)
+		if ValidateReferenceChain(chain) == nil && (!ok || gotChain != chain || gotComment != comment) {
+			t.Fatalf("ParseCommentReference(%q) = %q, %q, %v", chained, gotChain, gotComment, ok)
+		}
+		if ok && ChainCommentReference(gotChain, gotComment) != chained {
+			t.Fatalf("ParseCommentReference(%q) = %q, %q, which does not build the reference again", chained, gotChain, gotComment)
+		}
+
+		// Whatever is referenced, resolving it must only ever read the
+		// firewall tables, and arbitrary input must be handled like a
+		// reference as well.
+		for _, r := range []string{ref, chained, chain, comment} {
+			rule, err := c.ResolveRuleReference(context.Background(), "filter", r)
+			if err == nil && rule.ID == "" {
+				t.Fatalf("ResolveRuleReference(%q) resolved to no rule", r)
+			}
+			if _, _, isRef := ParseCommentReference(r); err == nil && !isRef && rule.ID != r {
+				t.Fatalf("ResolveRuleReference(%q) = %s", r, rule.ID)
+			}
+			checkRequests(t, firewallMenus, log.take())
+		}
+	})
+}
@@ -0,0 +1,122 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Version is a RouterOS firmware version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses versions as reported by `/system/resource`, e.g.
+// "7.16.1 (stable)" or "7.17beta2 (testing)".
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return v, fmt.Errorf("unable to parse empty RouterOS version")
+	}
+
+	parts := strings.Split(fields[0], ".")
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i := 0; i < len(parts) && i < len(nums); i++ {
+		// strip pre-release suffixes such as "beta2" or "rc1"
+		digits := strings.IndexFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+		if digits == -1 {
+			digits = len(parts[i])
+		}
+		n, err := strconv.Atoi(parts[i][:digits])
+		if err != nil {
+			return v, fmt.Errorf("unable to parse RouterOS version '%s'", s)
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+// AtLeast reports whether v is equal to or newer than major.minor.
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Version returns the firmware version of the device. The version is
+// only requested once and cached for the lifetime of the client.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version != nil {
+		return *c.version, nil
+	}
+
+	var res struct {
+		Version string `json:"version"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/system/resource", nil, &res); err != nil {
+		return Version{}, err
+	}
+
+	v, err := ParseVersion(res.Version)
+	if err != nil {
+		return v, err
+	}
+	c.version = &v
+
+	return v, nil
+}
+
+// versionIssue describes a known limitation of all versions older than
+// before.
+type versionIssue struct {
+	before  Version
+	warning string
+}
+
+var versionIssues = []versionIssue{
+	{
+		before:  Version{Major: 7, Minor: 1},
+		warning: "The REST API is only available starting with RouterOS 7.1, requests made by this provider are expected to fail",
+	},
+}
+
+// Warnings returns human readable descriptions of known issues affecting v.
+func (v Version) Warnings() []string {
+	var warnings []string
+	for _, issue := range versionIssues {
+		if !v.AtLeast(issue.before.Major, issue.before.Minor) {
+			warnings = append(warnings, issue.warning)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,86 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// addressListTypeName returns the type name suffix of the address list
+// resources and data sources of the given IP version, e.g. `_address_list`
+// or `_ipv6_address_list`.
+func addressListTypeName(v client.IPVersion) string {
+	if v == client.IPv6 {
+		return "_ipv6_address_list"
+	}
+	return "_address_list"
+}
+
+// addressFamilyName returns the human readable name of the IP version.
+func addressFamilyName(v client.IPVersion) string {
+	if v == client.IPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+// addressFamilyValidator rejects addresses, networks and ranges of the other
+// IP version, which RouterOS only reports with a generic error when the
+// entries are written. Values which are not an address, such as DNS names,
+// are left to RouterOS.
+type addressFamilyValidator struct {
+	version client.IPVersion
+}
+
+func (v addressFamilyValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be an %s address, network or range, or a DNS name", addressFamilyName(v.version))
+}
+
+func (v addressFamilyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v addressFamilyValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	s := req.ConfigValue.ValueString()
+	// the first address of a network or range determines its IP version
+	first := strings.TrimPrefix(s, "!")
+	first, _, _ = strings.Cut(first, "-")
+	first, _, _ = strings.Cut(first, "/")
+	addr, err := netip.ParseAddr(first)
+	if err != nil {
+		return
+	}
+	if addr.Is4() != (v.version == client.IPv4) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Address Family",
+			fmt.Sprintf("Expected an %s address, network or range, got: %s", addressFamilyName(v.version), s),
+		)
+	}
+}
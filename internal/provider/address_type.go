@@ -0,0 +1,238 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure the address types fully satisfy framework interfaces.
+var _ basetypes.StringTypable = IPAddressType{}
+var _ xattr.TypeWithValidate = IPAddressType{}
+var _ basetypes.StringValuableWithSemanticEquals = IPAddressValue{}
+var _ basetypes.StringTypable = CIDRType{}
+var _ xattr.TypeWithValidate = CIDRType{}
+var _ basetypes.StringValuableWithSemanticEquals = CIDRValue{}
+
+// IPAddressType is a string attribute type for a single IPv4 or IPv6
+// address. A host route such as `10.0.0.1/32` is accepted as well, and is
+// semantically equal to the plain address.
+type IPAddressType struct {
+	basetypes.StringType
+}
+
+func (t IPAddressType) Equal(o attr.Type) bool {
+	other, ok := o.(IPAddressType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t IPAddressType) String() string {
+	return "IPAddressType"
+}
+
+func (t IPAddressType) ValueType(ctx context.Context) attr.Value {
+	return IPAddressValue{}
+}
+
+func (t IPAddressType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return IPAddressValue{StringValue: in}, nil
+}
+
+func (t IPAddressType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	s, err := stringFromTerraform(ctx, t.StringType, in)
+	if err != nil {
+		return nil, err
+	}
+	return IPAddressValue{StringValue: s}, nil
+}
+
+// Validate rejects values which are not a single address.
+func (t IPAddressType) Validate(ctx context.Context, in tftypes.Value, p path.Path) diag.Diagnostics {
+	return validateAddress(in, p, "Invalid IP Address", "an IP address such as '10.0.0.1'", canonicalIPAddress)
+}
+
+// IPAddressValue is a value of IPAddressType.
+type IPAddressValue struct {
+	basetypes.StringValue
+}
+
+func (v IPAddressValue) Equal(o attr.Value) bool {
+	other, ok := o.(IPAddressValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v IPAddressValue) Type(ctx context.Context) attr.Type {
+	return IPAddressType{}
+}
+
+// StringSemanticEquals reports whether both values denote the same address.
+func (v IPAddressValue) StringSemanticEquals(ctx context.Context, o basetypes.StringValuable) (bool, diag.Diagnostics) {
+	other, ok := o.(IPAddressValue)
+	if !ok {
+		return false, nil
+	}
+	return semanticallyEqual(v.StringValue, other.StringValue, canonicalIPAddress), nil
+}
+
+// CIDRType is a string attribute type for the address matchers of RouterOS,
+// i.e. an IPv4 or IPv6 address, a network such as `10.0.0.0/24` or an
+// address range such as `10.0.0.1-10.0.0.9`, optionally negated by a leading
+// `!`. Values are semantically equal if RouterOS treats them the same, see
+// client.CanonicalAddress, e.g. `10.0.0.1/32` and `10.0.0.1`.
+type CIDRType struct {
+	basetypes.StringType
+}
+
+func (t CIDRType) Equal(o attr.Type) bool {
+	other, ok := o.(CIDRType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t CIDRType) String() string {
+	return "CIDRType"
+}
+
+func (t CIDRType) ValueType(ctx context.Context) attr.Value {
+	return CIDRValue{}
+}
+
+func (t CIDRType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return CIDRValue{StringValue: in}, nil
+}
+
+func (t CIDRType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	s, err := stringFromTerraform(ctx, t.StringType, in)
+	if err != nil {
+		return nil, err
+	}
+	return CIDRValue{StringValue: s}, nil
+}
+
+// Validate rejects values which are not an address, network or range.
+func (t CIDRType) Validate(ctx context.Context, in tftypes.Value, p path.Path) diag.Diagnostics {
+	return validateAddress(in, p, "Invalid Address", "an IP address, network or range such as '10.0.0.0/24'", client.CanonicalAddress)
+}
+
+// CIDRValue is a value of CIDRType.
+type CIDRValue struct {
+	basetypes.StringValue
+}
+
+func (v CIDRValue) Equal(o attr.Value) bool {
+	other, ok := o.(CIDRValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v CIDRValue) Type(ctx context.Context) attr.Type {
+	return CIDRType{}
+}
+
+// StringSemanticEquals reports whether RouterOS treats both values the same.
+func (v CIDRValue) StringSemanticEquals(ctx context.Context, o basetypes.StringValuable) (bool, diag.Diagnostics) {
+	other, ok := o.(CIDRValue)
+	if !ok {
+		return false, nil
+	}
+	return semanticallyEqual(v.StringValue, other.StringValue, client.CanonicalAddress), nil
+}
+
+// canonicalIPAddress returns the canonical form of a single address, which
+// may be given as a host route.
+func canonicalIPAddress(s string) (string, error) {
+	canonical, err := client.CanonicalAddress(s)
+	if err != nil {
+		return "", err
+	}
+	if _, err := netip.ParseAddr(canonical); err != nil || strings.HasPrefix(s, "!") {
+		return "", fmt.Errorf("'%s' is not a single IP address", s)
+	}
+	return canonical, nil
+}
+
+// semanticallyEqual reports whether a and b have the same canonical form.
+// Values which cannot be canonicalized are only equal to themselves.
+func semanticallyEqual(a, b basetypes.StringValue, canonical func(string) (string, error)) bool {
+	if a.IsNull() || a.IsUnknown() || b.IsNull() || b.IsUnknown() {
+		return a.Equal(b)
+	}
+	x, err := canonical(a.ValueString())
+	if err != nil {
+		return a.Equal(b)
+	}
+	y, err := canonical(b.ValueString())
+	if err != nil {
+		return a.Equal(b)
+	}
+	return x == y
+}
+
+// validateAddress adds an error to the returned diagnostics if in is known
+// and cannot be canonicalized.
+func validateAddress(in tftypes.Value, p path.Path, summary, expected string, canonical func(string) (string, error)) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !in.IsKnown() || in.IsNull() {
+		return diags
+	}
+
+	var s string
+	if err := in.As(&s); err != nil {
+		diags.AddAttributeError(p, summary, fmt.Sprintf("Unable to convert value to a string, got error: %s", err))
+		return diags
+	}
+	if _, err := canonical(s); err != nil {
+		diags.AddAttributeError(p, summary, fmt.Sprintf("Expected %s, got: %s", expected, s))
+	}
+	return diags
+}
+
+// stringFromTerraform converts in to a string value of t.
+func stringFromTerraform(ctx context.Context, t basetypes.StringType, in tftypes.Value) (basetypes.StringValue, error) {
+	v, err := t.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return basetypes.StringValue{}, err
+	}
+	s, ok := v.(basetypes.StringValue)
+	if !ok {
+		return basetypes.StringValue{}, fmt.Errorf("unexpected value type of %T", v)
+	}
+	return s, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCIDRValueStringSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.1/32", "10.0.0.1", true},
+		{"10.0.0.1", "10.0.0.1", true},
+		{"10.0.0.5/24", "10.0.0.0/24", true},
+		{"10.0.0.0/24", "10.0.0.0/25", false},
+		{"10.0.0.1/32", "10.0.0.2", false},
+		{"2001:db8::1/128", "2001:db8::1", true},
+		{"2001:db8:0:0::1", "2001:db8::1", true},
+		{"::ffff:10.0.0.1", "10.0.0.1", true},
+		{"!10.0.0.1/32", "!10.0.0.1", true},
+		{"!10.0.0.5/24", "!10.0.0.0/24", true},
+		{"!10.0.0.1", "10.0.0.1", false},
+		{"10.0.0.1-10.0.0.9", "10.0.0.1 - 10.0.0.9", true},
+		{"::ffff:10.0.0.1-10.0.0.9", "10.0.0.1-10.0.0.9", true},
+		{"!10.0.0.1-10.0.0.9", "!10.0.0.1-10.0.0.9", true},
+		{"10.0.0.1-10.0.0.9", "10.0.0.1-10.0.0.8", false},
+		{"10.0.0.1-10.0.0.1", "10.0.0.1", false},
+		{"not an address", "not an address", true},
+		{"not an address", "10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a := CIDRValue{StringValue: types.StringValue(tt.a)}
+			b := CIDRValue{StringValue: types.StringValue(tt.b)}
+			got, diags := a.StringSemanticEquals(context.Background(), b)
+			if diags.HasError() {
+				t.Fatalf("StringSemanticEquals() returned diagnostics: %v", diags)
+			}
+			if got != tt.want {
+				t.Errorf("StringSemanticEquals(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPAddressValueStringSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.1/32", "10.0.0.1", true},
+		{"2001:db8::1/128", "2001:db8::1", true},
+		{"::ffff:10.0.0.1", "10.0.0.1", true},
+		{"10.0.0.1/32", "10.0.0.2", false},
+		// only single addresses are canonicalized
+		{"10.0.0.0/24", "10.0.0.5/24", false},
+		{"!10.0.0.1", "10.0.0.1", false},
+		{"10.0.0.1-10.0.0.1", "10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a := IPAddressValue{StringValue: types.StringValue(tt.a)}
+			b := IPAddressValue{StringValue: types.StringValue(tt.b)}
+			got, diags := a.StringSemanticEquals(context.Background(), b)
+			if diags.HasError() {
+				t.Fatalf("StringSemanticEquals() returned diagnostics: %v", diags)
+			}
+			if got != tt.want {
+				t.Errorf("StringSemanticEquals(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddressValueStringSemanticEqualsNullAndUnknown(t *testing.T) {
+	values := []CIDRValue{
+		{StringValue: types.StringNull()},
+		{StringValue: types.StringUnknown()},
+		{StringValue: types.StringValue("10.0.0.1")},
+	}
+	for i, a := range values {
+		for j, b := range values {
+			got, _ := a.StringSemanticEquals(context.Background(), b)
+			if want := i == j; got != want {
+				t.Errorf("StringSemanticEquals(%s, %s) = %v, want %v", a, b, got, want)
+			}
+		}
+	}
+}
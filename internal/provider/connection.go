@@ -0,0 +1,144 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// errorRemediation is the summary and the advice on how to resolve it which
+// errors of a client.ErrorCategory are reported with.
+type errorRemediation struct {
+	summary string
+	hint    string
+}
+
+var errorRemediations = map[client.ErrorCategory]errorRemediation{
+	client.ErrorAuth: {"Authentication Failed",
+		"The device rejected the configured credentials. Check 'username' and 'password', or 'authorization_header' if set."},
+	client.ErrorPermission: {"Insufficient Permissions",
+		"The user lacks a policy required for the request. Firewall rules can only be managed by users whose group grants " +
+			"the 'read', 'write' and 'rest-api' policies, or 'api' on RouterOS versions without 'rest-api'. Set " +
+			"'validate_connection' to detect missing policies while configuring the provider."},
+	client.ErrorTLS: {"TLS Verification Failed",
+		"The certificate presented by the device could not be verified. Check that 'ca_certificate' points to the CA which " +
+			"signed the certificate of the www-ssl service, that 'hosturl' or 'tls_server_name' matches the certificate's name, " +
+			"and that 'tls_fingerprint_sha256' is up to date if the certificate was renewed."},
+	client.ErrorAPIUnavailable: {"REST API Not Available",
+		"The REST API is only served by the www-ssl service of RouterOS 7.1 and newer. Enable it with " +
+			"'/ip service set www-ssl certificate=<name> disabled=no' and point 'hosturl' and 'port' at it."},
+	client.ErrorUnreachable: {"Host Unreachable",
+		"Could not connect to the device. Check 'hosturl' and 'port', and that the www-ssl service is enabled and " +
+			"allowed to be accessed from this host, see '/ip service print'."},
+	client.ErrorTimeout: {"Request Timed Out",
+		"The device did not respond in time. Consider raising 'timeout' in the provider configuration, or the timeouts " +
+			"of the resource if the device is slow to apply large changes."},
+	client.ErrorOverloaded: {"Device Overloaded",
+		"The device kept rejecting requests as it is rate limiting or too busy to handle them. Consider lowering " +
+			"'max_api_rate' or 'concurrency'."},
+	client.ErrorNotFound: {"Object Not Found",
+		"The object does not exist on the device, e.g. because it was removed outside of Terraform. Refresh the state, " +
+			"or remove the object from the configuration."},
+	client.ErrorConflict: {"Conflicting Change",
+		"The change conflicts with the configuration of the device, e.g. because an object of the same name exists " +
+			"already, or because rules are concurrently being moved by someone else. Import the existing object, or enable " +
+			"'serialize_moves' if several orderings of the same table are applied at once."},
+}
+
+// clientError returns the summary and detail of a diagnostic for an error
+// returned by the client while attempting action, e.g. `Unable to read
+// address list`. Errors of a known client.ErrorCategory get a specific summary
+// and advice on how to resolve them, all others are summarized by the action,
+// e.g. `Unable To Read Address List`.
+func clientError(err error, action string) (string, string) {
+	return diagnoseError(err, titleCase(action), action)
+}
+
+// describeConnectionError maps errors which occur while contacting the device
+// to a diagnostic summary and an actionable detail.
+func describeConnectionError(err error) (string, string) {
+	return diagnoseError(err, "Connection Validation Failed", "Unable to contact the RouterOS REST API")
+}
+
+// diagnoseError returns the summary and detail of a diagnostic for err, using
+// fallback as the summary of errors of no known client.ErrorCategory.
+func diagnoseError(err error, fallback, action string) (string, string) {
+	detail := fmt.Sprintf("%s, got error: %s", action, err)
+	r, ok := errorRemediations[client.Categorize(err)]
+	if !ok {
+		return fallback, detail
+	}
+	return r.summary, fmt.Sprintf("%s\n\n%s", detail, r.hint)
+}
+
+// keepStateOnUnreachable reports whether a read which failed with err should
+// leave the prior state untouched, which is the case if the device could not be
+// reached and 'skip_read_on_error' is enabled, or if the provider configuration
+// is not known yet. A warning is added to diags instead of an error.
+func keepStateOnUnreachable(c client.API, err error, diags *diag.Diagnostics) bool {
+	// the provider already warned about its unknown configuration
+	if errors.Is(err, client.ErrConfigUnknown) {
+		return true
+	}
+	if !c.SkipReadOnError() || !client.IsUnreachable(err) {
+		return false
+	}
+	diags.AddWarning("Device Unreachable",
+		fmt.Sprintf("Unable to refresh the state from the device, assuming that nothing has changed as 'skip_read_on_error' is enabled. Got error: %s", err))
+	return true
+}
+
+// versionDiagnostics returns warnings for known issues of the firmware running
+// on the device. Failing to determine the version is not considered an error,
+// any actual connection issue surfaces in the subsequent requests anyway.
+func versionDiagnostics(ctx context.Context, c client.API) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	v, err := c.Version(ctx)
+	if err != nil {
+		tflog.Debug(ctx, "Unable to determine RouterOS version", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return diags
+	}
+
+	for _, w := range v.Warnings() {
+		diags.AddWarning(fmt.Sprintf("Known Issue In RouterOS %s", v), w)
+	}
+	return diags
+}
+
+// titleCase capitalizes the first letter of every word of s, which is how
+// diagnostic summaries are written.
+func titleCase(s string) string {
+	words := strings.Split(s, " ")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
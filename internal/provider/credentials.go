@@ -0,0 +1,116 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+	"gopkg.in/yaml.v3"
+)
+
+// credentials are the values which a credentials command may print as a JSON
+// object on stdout, or which a credentials file may contain. Any field which
+// is omitted keeps the value configured otherwise.
+type credentials struct {
+	HostURL       string `json:"hosturl" yaml:"hosturl"`
+	Username      string `json:"username" yaml:"username"`
+	Password      string `json:"password" yaml:"password"`
+	Authorization string `json:"authorization" yaml:"authorization"`
+}
+
+// apply overrides host and the credentials in opts with all non-empty values
+// of c.
+func (c credentials) apply(host *string, opts *client.ClientOpts) {
+	if c.HostURL != "" {
+		*host = c.HostURL
+	}
+	if c.Username != "" {
+		opts.Username = c.Username
+	}
+	if c.Password != "" {
+		opts.Password = c.Password
+	}
+	if c.Authorization != "" {
+		opts.Authorization = c.Authorization
+	}
+}
+
+// fill sets host and the credentials in opts which are still empty to the
+// values of c, so that c only serves as a fallback for values which are
+// neither configured nor set via the environment. The authorization is only
+// filled if neither a username nor a password is configured, as it would
+// otherwise take precedence over them.
+func (c credentials) fill(host *string, opts *client.ClientOpts) {
+	basicAuth := opts.Username != "" || opts.Password != ""
+	if *host == "" {
+		*host = c.HostURL
+	}
+	if opts.Username == "" {
+		opts.Username = c.Username
+	}
+	if opts.Password == "" {
+		opts.Password = c.Password
+	}
+	if opts.Authorization == "" && !basicAuth {
+		opts.Authorization = c.Authorization
+	}
+}
+
+// readCredentialsFile decodes the credentials file at path. As YAML is a
+// superset of JSON, both formats are accepted.
+func readCredentialsFile(path string) (credentials, error) {
+	var creds credentials
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return creds, fmt.Errorf("could not read credentials file: %w", err)
+	}
+	// the error is deliberately not wrapped, it may quote parts of the file
+	if err := yaml.Unmarshal(b, &creds); err != nil {
+		return creds, fmt.Errorf("credentials file %s is neither valid JSON nor YAML", path)
+	}
+	return creds, nil
+}
+
+// runCredentialsCommand executes command via the shell and decodes the
+// credentials it prints on stdout. Stderr is included in the returned error,
+// stdout never is, as it may contain a partial secret.
+func runCredentialsCommand(ctx context.Context, command string) (credentials, error) {
+	var creds credentials
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return creds, fmt.Errorf("credentials command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return creds, fmt.Errorf("credentials command did not print a JSON object of the form {\"username\": ..., \"password\": ...} or {\"authorization\": ...}")
+	}
+	return creds, nil
+}
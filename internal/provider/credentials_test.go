@@ -0,0 +1,98 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+func TestCredentialsFill(t *testing.T) {
+	creds := credentials{
+		HostURL:       "router.example.com",
+		Username:      "file-user",
+		Password:      "file-password",
+		Authorization: "Bearer file-token",
+	}
+
+	tests := []struct {
+		name  string
+		opts  client.ClientOpts
+		creds credentials
+		want  client.ClientOpts
+	}{
+		{
+			name:  "nothing configured",
+			creds: creds,
+			want:  client.ClientOpts{Username: "file-user", Password: "file-password", Authorization: "Bearer file-token"},
+		},
+		{
+			name:  "username and password configured",
+			opts:  client.ClientOpts{Username: "admin", Password: "secret"},
+			creds: creds,
+			want:  client.ClientOpts{Username: "admin", Password: "secret"},
+		},
+		{
+			name:  "only username configured",
+			opts:  client.ClientOpts{Username: "admin"},
+			creds: creds,
+			want:  client.ClientOpts{Username: "admin", Password: "file-password"},
+		},
+		{
+			name:  "only password configured",
+			opts:  client.ClientOpts{Password: "secret"},
+			creds: creds,
+			want:  client.ClientOpts{Username: "file-user", Password: "secret"},
+		},
+		{
+			name:  "authorization configured",
+			opts:  client.ClientOpts{Authorization: "Bearer token"},
+			creds: creds,
+			want:  client.ClientOpts{Username: "file-user", Password: "file-password", Authorization: "Bearer token"},
+		},
+		{
+			name:  "empty values are not filled",
+			opts:  client.ClientOpts{Username: "admin"},
+			creds: credentials{Authorization: "Bearer file-token"},
+			want:  client.ClientOpts{Username: "admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, opts := "", tt.opts
+			tt.creds.fill(&host, &opts)
+			if opts != tt.want {
+				t.Errorf("fill() = %+v, want %+v", opts, tt.want)
+			}
+			if host != tt.creds.HostURL {
+				t.Errorf("fill() host = %q, want %q", host, tt.creds.HostURL)
+			}
+		})
+	}
+
+	t.Run("configured host is kept", func(t *testing.T) {
+		host, opts := "10.0.0.1", client.ClientOpts{}
+		creds.fill(&host, &opts)
+		if host != "10.0.0.1" {
+			t.Errorf("fill() host = %q, want %q", host, "10.0.0.1")
+		}
+	})
+}
@@ -0,0 +1,202 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AddressListDataSource{}
+
+func NewAddressListDataSource() datasource.DataSource {
+	return &AddressListDataSource{ipVersion: client.IPv4}
+}
+
+func NewIPv6AddressListDataSource() datasource.DataSource {
+	return &AddressListDataSource{ipVersion: client.IPv6}
+}
+
+// AddressListDataSource defines the data source implementation. It reads
+// the IPv4 or IPv6 address lists depending on ipVersion.
+type AddressListDataSource struct {
+	client    client.API
+	ipVersion client.IPVersion
+}
+
+// AddressListDataSourceModel describes the data source data model.
+type AddressListDataSourceModel struct {
+	ID      types.String            `tfsdk:"id"`
+	List    types.String            `tfsdk:"list"`
+	Query   types.List              `tfsdk:"query"`
+	Entries []AddressListEntryModel `tfsdk:"entries"`
+}
+
+// AddressListEntryModel describes a single entry of an address list.
+type AddressListEntryModel struct {
+	ID           types.String `tfsdk:"id"`
+	Address      types.String `tfsdk:"address"`
+	Timeout      types.String `tfsdk:"timeout"`
+	Comment      types.String `tfsdk:"comment"`
+	Disabled     types.Bool   `tfsdk:"disabled"`
+	Dynamic      types.Bool   `tfsdk:"dynamic"`
+	CreationTime types.String `tfsdk:"creation_time"`
+}
+
+func (d *AddressListDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + addressListTypeName(d.ipVersion)
+}
+
+func (d *AddressListDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *AddressListDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: fmt.Sprintf("Entries of a firewall address list (`%s`), including dynamically added ones", client.AddressListMenu(d.ipVersion)),
+		Description:         fmt.Sprintf("Entries of a firewall address list (%s), including dynamically added ones", client.AddressListMenu(d.ipVersion)),
+		Attributes: map[string]schema.Attribute{
+			"list": schema.StringAttribute{
+				MarkdownDescription: "Name of the address list",
+				Description:         "Name of the address list",
+				Required:            true,
+			},
+			"query": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "RouterOS query words which entries must additionally match, e.g. `[\"dynamic=false\"]`. Words such as `#|` and `#!` combine or negate the preceding conditions, all remaining conditions must match. The query is evaluated by the device, so that only matching entries are transferred, which speeds up reading large lists",
+				Description:         "RouterOS query words which entries must additionally match, e.g. [\"dynamic=false\"]. Words such as '#|' and '#!' combine or negate the preceding conditions, all remaining conditions must match. The query is evaluated by the device, so that only matching entries are transferred, which speeds up reading large lists",
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"entries": schema.ListNestedAttribute{
+				MarkdownDescription: "Entries of the address list",
+				Description:         "Entries of the address list",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Identifier of the entry",
+							Description:         "Identifier of the entry",
+							Computed:            true,
+						},
+						"address": schema.StringAttribute{
+							MarkdownDescription: "Address, range, subnet or DNS name of the entry",
+							Description:         "Address, range, subnet or DNS name of the entry",
+							Computed:            true,
+						},
+						"timeout": schema.StringAttribute{
+							MarkdownDescription: "Time remaining until the entry expires, if any",
+							Description:         "Time remaining until the entry expires, if any",
+							Computed:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Comment attached to the entry",
+							Description:         "Comment attached to the entry",
+							Computed:            true,
+						},
+						"disabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the entry is disabled",
+							Description:         "Whether the entry is disabled",
+							Computed:            true,
+						},
+						"dynamic": schema.BoolAttribute{
+							MarkdownDescription: "Whether the entry was added dynamically, e.g. by a firewall rule",
+							Description:         "Whether the entry was added dynamically, e.g. by a firewall rule",
+							Computed:            true,
+						},
+						"creation_time": schema.StringAttribute{
+							MarkdownDescription: "Time at which the entry was created",
+							Description:         "Time at which the entry was created",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *AddressListDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AddressListDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var entries []client.AddressListEntry
+	var err error
+	if data.Query.IsNull() {
+		entries, err = d.client.GetAddressList(ctx, d.ipVersion, data.List.ValueString())
+	} else {
+		var query []string
+		resp.Diagnostics.Append(data.Query.ElementsAs(ctx, &query, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		entries, err = d.client.QueryAddressList(ctx, d.ipVersion, data.List.ValueString(), query)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read address list"))
+		return
+	}
+
+	data.ID = data.List
+	data.Entries = make([]AddressListEntryModel, 0, len(entries))
+	for _, e := range entries {
+		data.Entries = append(data.Entries, AddressListEntryModel{
+			ID:           types.StringValue(e.ID),
+			Address:      types.StringValue(e.Address),
+			Timeout:      stringOrNull(e.Timeout),
+			Comment:      stringOrNull(e.Comment),
+			Disabled:     types.BoolValue(e.Disabled == "true"),
+			Dynamic:      types.BoolValue(e.Dynamic == "true"),
+			CreationTime: stringOrNull(e.CreationTime),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,192 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &APIStatusDataSource{}
+
+func NewAPIStatusDataSource() datasource.DataSource {
+	return &APIStatusDataSource{}
+}
+
+// APIStatusDataSource defines the data source implementation.
+type APIStatusDataSource struct {
+	client client.API
+}
+
+// APIStatusDataSourceModel describes the data source data model.
+type APIStatusDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Healthy       types.Bool   `tfsdk:"healthy"`
+	Reachable     types.Bool   `tfsdk:"reachable"`
+	RESTAPI       types.Bool   `tfsdk:"rest_api"`
+	Authenticated types.Bool   `tfsdk:"authenticated"`
+	Error         types.String `tfsdk:"error"`
+	Version       types.String `tfsdk:"version"`
+	BoardName     types.String `tfsdk:"board_name"`
+	Architecture  types.String `tfsdk:"architecture"`
+	Uptime        types.String `tfsdk:"uptime"`
+	CPULoad       types.String `tfsdk:"cpu_load"`
+}
+
+func (d *APIStatusDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_api_status"
+}
+
+func (d *APIStatusDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *APIStatusDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Health of the device and its REST API. Unlike other data sources, reading it does not fail if the device cannot be contacted, so that modules can gate changes on `healthy`, e.g. in a `precondition`",
+		Description:         "Health of the device and its REST API. Unlike other data sources, reading it does not fail if the device cannot be contacted, so that modules can gate changes on 'healthy', e.g. in a 'precondition'",
+		Attributes: map[string]schema.Attribute{
+			"healthy": schema.BoolAttribute{
+				MarkdownDescription: "Whether the REST API could be queried successfully",
+				Description:         "Whether the REST API could be queried successfully",
+				Computed:            true,
+			},
+			"reachable": schema.BoolAttribute{
+				MarkdownDescription: "Whether a connection to the device could be established",
+				Description:         "Whether a connection to the device could be established",
+				Computed:            true,
+			},
+			"rest_api": schema.BoolAttribute{
+				MarkdownDescription: "Whether the device responded like the REST API does. This is `false` if e.g. the configured port is served by the plain `www` service",
+				Description:         "Whether the device responded like the REST API does. This is 'false' if e.g. the configured port is served by the plain 'www' service",
+				Computed:            true,
+			},
+			"authenticated": schema.BoolAttribute{
+				MarkdownDescription: "Whether the device accepted the configured credentials",
+				Description:         "Whether the device accepted the configured credentials",
+				Computed:            true,
+			},
+			"error": schema.StringAttribute{
+				MarkdownDescription: "Description of the problem if the device is not `healthy`",
+				Description:         "Description of the problem if the device is not 'healthy'",
+				Computed:            true,
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: "RouterOS version running on the device, e.g. `7.16 (stable)`",
+				Description:         "RouterOS version running on the device, e.g. '7.16 (stable)'",
+				Computed:            true,
+			},
+			"board_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the board, e.g. `hAP ax^3`",
+				Description:         "Name of the board, e.g. 'hAP ax^3'",
+				Computed:            true,
+			},
+			"architecture": schema.StringAttribute{
+				MarkdownDescription: "CPU architecture of the device, e.g. `arm64`",
+				Description:         "CPU architecture of the device, e.g. 'arm64'",
+				Computed:            true,
+			},
+			"uptime": schema.StringAttribute{
+				MarkdownDescription: "Time since the device booted, e.g. `1w2d03:04:05`",
+				Description:         "Time since the device booted, e.g. '1w2d03:04:05'",
+				Computed:            true,
+			},
+			"cpu_load": schema.StringAttribute{
+				MarkdownDescription: "CPU load of the device in percent",
+				Description:         "CPU load of the device in percent",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *APIStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	data := APIStatusDataSourceModel{
+		ID:           types.StringValue("api_status"),
+		Error:        types.StringNull(),
+		Version:      types.StringNull(),
+		BoardName:    types.StringNull(),
+		Architecture: types.StringNull(),
+		Uptime:       types.StringNull(),
+		CPULoad:      types.StringNull(),
+	}
+
+	res, err := d.client.GetSystemResource(ctx)
+	var (
+		apiErr  *client.APIError
+		nonJSON *client.NonJSONResponseError
+	)
+	switch {
+	case err == nil:
+		data.Reachable = types.BoolValue(true)
+		data.RESTAPI = types.BoolValue(true)
+		data.Authenticated = types.BoolValue(true)
+		data.Version = stringOrNull(res.Version)
+		data.BoardName = stringOrNull(res.BoardName)
+		data.Architecture = stringOrNull(res.ArchitectureName)
+		data.Uptime = stringOrNull(res.Uptime)
+		data.CPULoad = stringOrNull(res.CPULoad)
+	case errors.As(err, &apiErr):
+		data.Reachable = types.BoolValue(true)
+		data.RESTAPI = types.BoolValue(true)
+		data.Authenticated = types.BoolValue(apiErr.Status != http.StatusUnauthorized && apiErr.Status != http.StatusForbidden)
+	case errors.As(err, &nonJSON):
+		data.Reachable = types.BoolValue(true)
+		data.RESTAPI = types.BoolValue(false)
+		data.Authenticated = types.BoolValue(false)
+	default:
+		// nothing is known about the device, e.g. if it could not be reached
+		// or its certificate is not trusted
+		data.Reachable = types.BoolValue(!client.IsUnreachable(err) && !errors.Is(err, client.ErrConfigUnknown))
+		data.RESTAPI = types.BoolValue(false)
+		data.Authenticated = types.BoolValue(false)
+	}
+
+	data.Healthy = types.BoolValue(err == nil)
+	if err != nil {
+		summary, detail := describeConnectionError(err)
+		data.Error = types.StringValue(fmt.Sprintf("%s: %s", summary, detail))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
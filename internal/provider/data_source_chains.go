@@ -0,0 +1,132 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ChainsDataSource{}
+
+func NewChainsDataSource() datasource.DataSource {
+	return &ChainsDataSource{}
+}
+
+// ChainsDataSource defines the data source implementation.
+type ChainsDataSource struct {
+	client client.API
+}
+
+// ChainsDataSourceModel describes the data source data model.
+type ChainsDataSourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	RuleType types.String `tfsdk:"rule_type"`
+	Chains   types.List   `tfsdk:"chains"`
+}
+
+func (d *ChainsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_chains"
+}
+
+func (d *ChainsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *ChainsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Chains which are in use by at least one rule of a rule table, including custom chains",
+		Description:         "Chains which are in use by at least one rule of a rule table, including custom chains",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule type to list the chains of",
+				Description:         "The rule type to list the chains of",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+				},
+			},
+			"chains": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Distinct chains in the order of their first appearance in the table",
+				Description:         "Distinct chains in the order of their first appearance in the table",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *ChainsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ChainsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules, err := d.client.GetRulesOfType(ctx, data.RuleType.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read chains"))
+		return
+	}
+
+	seen := map[string]bool{}
+	chains := []string{}
+	for _, rule := range rules {
+		if !seen[rule.Chain] {
+			seen[rule.Chain] = true
+			chains = append(chains, rule.Chain)
+		}
+	}
+
+	list, diags := types.ListValueFrom(ctx, types.StringType, chains)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.RuleType
+	data.Chains = list
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,261 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ConnectionsDataSource{}
+
+func NewConnectionsDataSource() datasource.DataSource {
+	return &ConnectionsDataSource{}
+}
+
+// ConnectionsDataSource defines the data source implementation.
+type ConnectionsDataSource struct {
+	client client.API
+}
+
+// ConnectionsDataSourceModel describes the data source data model.
+type ConnectionsDataSourceModel struct {
+	ID          types.String      `tfsdk:"id"`
+	SrcAddress  types.String      `tfsdk:"src_address"`
+	DstAddress  types.String      `tfsdk:"dst_address"`
+	Protocol    types.String      `tfsdk:"protocol"`
+	TCPState    types.String      `tfsdk:"tcp_state"`
+	Connections []ConnectionModel `tfsdk:"connections"`
+}
+
+// ConnectionModel describes a single tracked connection.
+type ConnectionModel struct {
+	ID              types.String `tfsdk:"id"`
+	Protocol        types.String `tfsdk:"protocol"`
+	SrcAddress      types.String `tfsdk:"src_address"`
+	DstAddress      types.String `tfsdk:"dst_address"`
+	ReplySrcAddress types.String `tfsdk:"reply_src_address"`
+	ReplyDstAddress types.String `tfsdk:"reply_dst_address"`
+	TCPState        types.String `tfsdk:"tcp_state"`
+	Timeout         types.String `tfsdk:"timeout"`
+	SrcNAT          types.Bool   `tfsdk:"srcnat"`
+	DstNAT          types.Bool   `tfsdk:"dstnat"`
+	Assured         types.Bool   `tfsdk:"assured"`
+	ConnectionMark  types.String `tfsdk:"connection_mark"`
+	OrigBytes       types.Int64  `tfsdk:"orig_bytes"`
+	ReplBytes       types.Int64  `tfsdk:"repl_bytes"`
+	OrigPackets     types.Int64  `tfsdk:"orig_packets"`
+	ReplPackets     types.Int64  `tfsdk:"repl_packets"`
+}
+
+func (d *ConnectionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_connections"
+}
+
+func (d *ConnectionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *ConnectionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Currently tracked connections (`/ip/firewall/connection`). The connection table changes constantly, so the result is only a snapshot taken whenever the data source is read",
+		Description:         "Currently tracked connections (/ip/firewall/connection). The connection table changes constantly, so the result is only a snapshot taken whenever the data source is read",
+		Attributes: map[string]schema.Attribute{
+			"src_address": schema.StringAttribute{
+				MarkdownDescription: "Only return connections from this source address. Matches either the address alone, e.g. `192.168.88.10`, or including the port, e.g. `192.168.88.10:51234`",
+				Description:         "Only return connections from this source address. Matches either the address alone, e.g. '192.168.88.10', or including the port, e.g. '192.168.88.10:51234'",
+				Optional:            true,
+			},
+			"dst_address": schema.StringAttribute{
+				MarkdownDescription: "Only return connections to this destination address. Matches either the address alone, e.g. `10.0.0.1`, or including the port, e.g. `10.0.0.1:443`",
+				Description:         "Only return connections to this destination address. Matches either the address alone, e.g. '10.0.0.1', or including the port, e.g. '10.0.0.1:443'",
+				Optional:            true,
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "Only return connections of this protocol, e.g. `tcp`",
+				Description:         "Only return connections of this protocol, e.g. 'tcp'",
+				Optional:            true,
+			},
+			"tcp_state": schema.StringAttribute{
+				MarkdownDescription: "Only return TCP connections in this state, e.g. `established`",
+				Description:         "Only return TCP connections in this state, e.g. 'established'",
+				Optional:            true,
+			},
+			"connections": schema.ListNestedAttribute{
+				MarkdownDescription: "Connections matching all filters",
+				Description:         "Connections matching all filters",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Identifier of the connection",
+							Description:         "Identifier of the connection",
+							Computed:            true,
+						},
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: "Protocol of the connection, e.g. `tcp`",
+							Description:         "Protocol of the connection, e.g. 'tcp'",
+							Computed:            true,
+						},
+						"src_address": schema.StringAttribute{
+							MarkdownDescription: "Source address of the original direction, including the port if applicable",
+							Description:         "Source address of the original direction, including the port if applicable",
+							Computed:            true,
+						},
+						"dst_address": schema.StringAttribute{
+							MarkdownDescription: "Destination address of the original direction, including the port if applicable",
+							Description:         "Destination address of the original direction, including the port if applicable",
+							Computed:            true,
+						},
+						"reply_src_address": schema.StringAttribute{
+							MarkdownDescription: "Source address of the reply direction. Differs from `dst_address` if the connection was destination NATed",
+							Description:         "Source address of the reply direction. Differs from 'dst_address' if the connection was destination NATed",
+							Computed:            true,
+						},
+						"reply_dst_address": schema.StringAttribute{
+							MarkdownDescription: "Destination address of the reply direction. Differs from `src_address` if the connection was source NATed",
+							Description:         "Destination address of the reply direction. Differs from 'src_address' if the connection was source NATed",
+							Computed:            true,
+						},
+						"tcp_state": schema.StringAttribute{
+							MarkdownDescription: "State of TCP connections, e.g. `established`",
+							Description:         "State of TCP connections, e.g. 'established'",
+							Computed:            true,
+						},
+						"timeout": schema.StringAttribute{
+							MarkdownDescription: "Time remaining until the connection entry expires",
+							Description:         "Time remaining until the connection entry expires",
+							Computed:            true,
+						},
+						"srcnat": schema.BoolAttribute{
+							MarkdownDescription: "Whether the connection is source NATed",
+							Description:         "Whether the connection is source NATed",
+							Computed:            true,
+						},
+						"dstnat": schema.BoolAttribute{
+							MarkdownDescription: "Whether the connection is destination NATed",
+							Description:         "Whether the connection is destination NATed",
+							Computed:            true,
+						},
+						"assured": schema.BoolAttribute{
+							MarkdownDescription: "Whether traffic has been seen in both directions",
+							Description:         "Whether traffic has been seen in both directions",
+							Computed:            true,
+						},
+						"connection_mark": schema.StringAttribute{
+							MarkdownDescription: "Connection mark set by mangle rules, if any",
+							Description:         "Connection mark set by mangle rules, if any",
+							Computed:            true,
+						},
+						"orig_bytes": schema.Int64Attribute{
+							MarkdownDescription: "Number of bytes sent in the original direction",
+							Description:         "Number of bytes sent in the original direction",
+							Computed:            true,
+						},
+						"repl_bytes": schema.Int64Attribute{
+							MarkdownDescription: "Number of bytes sent in the reply direction",
+							Description:         "Number of bytes sent in the reply direction",
+							Computed:            true,
+						},
+						"orig_packets": schema.Int64Attribute{
+							MarkdownDescription: "Number of packets sent in the original direction",
+							Description:         "Number of packets sent in the original direction",
+							Computed:            true,
+						},
+						"repl_packets": schema.Int64Attribute{
+							MarkdownDescription: "Number of packets sent in the reply direction",
+							Description:         "Number of packets sent in the reply direction",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *ConnectionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ConnectionsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := client.ConnectionFilter{
+		SrcAddress: data.SrcAddress.ValueString(),
+		DstAddress: data.DstAddress.ValueString(),
+		Protocol:   data.Protocol.ValueString(),
+		TCPState:   data.TCPState.ValueString(),
+	}
+
+	conns, err := d.client.GetConnections(ctx, filter)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read connections"))
+		return
+	}
+
+	data.ID = types.StringValue(strings.Join([]string{filter.SrcAddress, filter.DstAddress, filter.Protocol, filter.TCPState}, "/"))
+	data.Connections = make([]ConnectionModel, 0, len(conns))
+	for _, e := range conns {
+		data.Connections = append(data.Connections, ConnectionModel{
+			ID:              types.StringValue(e.ID),
+			Protocol:        stringOrNull(e.Protocol),
+			SrcAddress:      types.StringValue(e.SrcAddress),
+			DstAddress:      types.StringValue(e.DstAddress),
+			ReplySrcAddress: stringOrNull(e.ReplySrcAddress),
+			ReplyDstAddress: stringOrNull(e.ReplyDstAddress),
+			TCPState:        stringOrNull(e.TCPState),
+			Timeout:         stringOrNull(e.Timeout),
+			SrcNAT:          types.BoolValue(e.SrcNAT == "true"),
+			DstNAT:          types.BoolValue(e.DstNAT == "true"),
+			Assured:         types.BoolValue(e.Assured == "true"),
+			ConnectionMark:  stringOrNull(e.ConnectionMark),
+			OrigBytes:       int64OrNull(e.OrigBytes),
+			ReplBytes:       int64OrNull(e.ReplBytes),
+			OrigPackets:     int64OrNull(e.OrigPackets),
+			ReplPackets:     int64OrNull(e.ReplPackets),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,225 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FirewallRuleDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &FirewallRuleDataSource{}
+
+func NewFirewallRuleDataSource() datasource.DataSource {
+	return &FirewallRuleDataSource{}
+}
+
+// FirewallRuleDataSource defines the data source implementation.
+type FirewallRuleDataSource struct {
+	client client.API
+}
+
+// FirewallRuleDataSourceModel describes the data source data model.
+type FirewallRuleDataSourceModel struct {
+	RuleType   types.String `tfsdk:"rule_type"`
+	ID         types.String `tfsdk:"id"`
+	Comment    types.String `tfsdk:"comment"`
+	Query      types.List   `tfsdk:"query"`
+	Chain      types.String `tfsdk:"chain"`
+	Action     types.String `tfsdk:"action"`
+	Disabled   types.Bool   `tfsdk:"disabled"`
+	Bytes      types.Int64  `tfsdk:"bytes"`
+	Packets    types.Int64  `tfsdk:"packets"`
+	Properties types.Map    `tfsdk:"properties"`
+}
+
+func (d *FirewallRuleDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_rule"
+}
+
+func (d *FirewallRuleDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *FirewallRuleDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "A single firewall rule, looked up by its ID, its comment or a query",
+		Description:         "A single firewall rule, looked up by its ID, its comment or a query",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule type to look up the rule in",
+				Description:         "The rule type to look up the rule in",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(client.RuleTypes...),
+				},
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "RouterOS ID of the rule. Exactly one of `id`, `comment` and `query` must be set",
+				Description:         "RouterOS ID of the rule. Exactly one of 'id', 'comment' and 'query' must be set",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(client.IDRegexp, "must be a RouterOS id of the form '*1A'"),
+				},
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment of the rule. The comment must be unique within the table. Exactly one of `id`, `comment` and `query` must be set",
+				Description:         "Comment of the rule. The comment must be unique within the table. Exactly one of 'id', 'comment' and 'query' must be set",
+				Optional:            true,
+				Computed:            true,
+			},
+			"query": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "RouterOS query words selecting the rule, e.g. `[\"chain=input\", \"dst-port=22\"]`. Words such as `#|` and `#!` combine or negate the preceding conditions, all remaining conditions must match. The query is evaluated by the device, so that only the matching rule is transferred, and must match exactly one rule. Values are compared to the properties as stored on the device. Exactly one of `id`, `comment` and `query` must be set",
+				Description:         "RouterOS query words selecting the rule, e.g. [\"chain=input\", \"dst-port=22\"]. Words such as '#|' and '#!' combine or negate the preceding conditions, all remaining conditions must match. The query is evaluated by the device, so that only the matching rule is transferred, and must match exactly one rule. Values are compared to the properties as stored on the device. Exactly one of 'id', 'comment' and 'query' must be set",
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"chain": schema.StringAttribute{
+				MarkdownDescription: "Chain the rule belongs to",
+				Description:         "Chain the rule belongs to",
+				Computed:            true,
+			},
+			"action": schema.StringAttribute{
+				MarkdownDescription: "Action taken for matching packets",
+				Description:         "Action taken for matching packets",
+				Computed:            true,
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the rule is disabled",
+				Description:         "Whether the rule is disabled",
+				Computed:            true,
+			},
+			"bytes": schema.Int64Attribute{
+				MarkdownDescription: "Number of bytes matched by the rule",
+				Description:         "Number of bytes matched by the rule",
+				Computed:            true,
+			},
+			"packets": schema.Int64Attribute{
+				MarkdownDescription: "Number of packets matched by the rule",
+				Description:         "Number of packets matched by the rule",
+				Computed:            true,
+			},
+			"properties": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "All properties of the rule as reported by RouterOS, e.g. `src-address`",
+				Description:         "All properties of the rule as reported by RouterOS, e.g. 'src-address'",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *FirewallRuleDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(path.MatchRoot("id"), path.MatchRoot("comment"), path.MatchRoot("query")),
+	}
+}
+
+func (d *FirewallRuleDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FirewallRuleDataSourceModel
+	var props map[string]string
+	var err error
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	switch {
+	case !data.ID.IsNull():
+		props, err = d.client.GetRuleProperties(ctx, data.RuleType.ValueString(), data.ID.ValueString())
+	case !data.Query.IsNull():
+		var query []string
+		resp.Diagnostics.Append(data.Query.ElementsAs(ctx, &query, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		props, err = d.queryRule(ctx, data.RuleType.ValueString(), query)
+	default:
+		props, err = d.client.FindRuleByComment(ctx, data.RuleType.ValueString(), data.Comment.ValueString())
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read firewall rule"))
+		return
+	}
+
+	data.ID = types.StringValue(props[".id"])
+	data.Comment = types.StringValue(props["comment"])
+	data.Chain = types.StringValue(props["chain"])
+	data.Action = types.StringValue(props["action"])
+	data.Disabled = types.BoolValue(props["disabled"] == "true")
+	data.Bytes = int64OrNull(props["bytes"])
+	data.Packets = int64OrNull(props["packets"])
+
+	properties, diags := types.MapValueFrom(ctx, types.StringType, props)
+	resp.Diagnostics.Append(diags...)
+	data.Properties = properties
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// queryRule returns the properties of the single rule of the given type which
+// matches query.
+func (d *FirewallRuleDataSource) queryRule(ctx context.Context, ruleType string, query []string) (map[string]string, error) {
+	rules, err := d.client.QueryRules(ctx, ruleType, query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(rules) {
+	case 0:
+		return nil, fmt.Errorf("%w: no rule of type '%s' matches the query %v", client.ErrRuleNotFound, ruleType, query)
+	case 1:
+		return rules[0], nil
+	default:
+		ids := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			ids = append(ids, rule[".id"])
+		}
+		return nil, fmt.Errorf("the query %v is ambiguous, it matches the rules %s of type '%s'", query, strings.Join(ids, ", "), ruleType)
+	}
+}
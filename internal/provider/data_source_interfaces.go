@@ -0,0 +1,323 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &InterfacesDataSource{}
+
+func NewInterfacesDataSource() datasource.DataSource {
+	return &InterfacesDataSource{}
+}
+
+// InterfacesDataSource defines the data source implementation.
+type InterfacesDataSource struct {
+	client client.API
+}
+
+// InterfacesDataSourceModel describes the data source data model.
+type InterfacesDataSourceModel struct {
+	ID                types.String                  `tfsdk:"id"`
+	RequireInterfaces []types.String                `tfsdk:"require_interfaces"`
+	RequireLists      []types.String                `tfsdk:"require_lists"`
+	Interfaces        map[string]InterfaceModel     `tfsdk:"interfaces"`
+	Lists             map[string]InterfaceListModel `tfsdk:"lists"`
+}
+
+// InterfaceModel describes a single interface.
+type InterfaceModel struct {
+	ID          types.String   `tfsdk:"id"`
+	Type        types.String   `tfsdk:"type"`
+	DefaultName types.String   `tfsdk:"default_name"`
+	MACAddress  types.String   `tfsdk:"mac_address"`
+	Comment     types.String   `tfsdk:"comment"`
+	Running     types.Bool     `tfsdk:"running"`
+	Disabled    types.Bool     `tfsdk:"disabled"`
+	Dynamic     types.Bool     `tfsdk:"dynamic"`
+	Lists       []types.String `tfsdk:"lists"`
+}
+
+// InterfaceListModel describes a single interface list.
+type InterfaceListModel struct {
+	ID      types.String   `tfsdk:"id"`
+	Comment types.String   `tfsdk:"comment"`
+	Builtin types.Bool     `tfsdk:"builtin"`
+	Include []types.String `tfsdk:"include"`
+	Exclude []types.String `tfsdk:"exclude"`
+	Members []types.String `tfsdk:"members"`
+}
+
+func (d *InterfacesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_interfaces"
+}
+
+func (d *InterfacesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *InterfacesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Interfaces (`/interface`) and interface lists (`/interface/list`) of the device, e.g. to check that the interfaces referenced by firewall rules exist",
+		Description:         "Interfaces ('/interface') and interface lists ('/interface/list') of the device, e.g. to check that the interfaces referenced by firewall rules exist",
+		Attributes: map[string]schema.Attribute{
+			"require_interfaces": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of interfaces which must exist. Reading the data source fails if any of them is missing",
+				Description:         "Names of interfaces which must exist. Reading the data source fails if any of them is missing",
+				Optional:            true,
+			},
+			"require_lists": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of interface lists which must exist. Reading the data source fails if any of them is missing",
+				Description:         "Names of interface lists which must exist. Reading the data source fails if any of them is missing",
+				Optional:            true,
+			},
+			"interfaces": schema.MapNestedAttribute{
+				MarkdownDescription: "Interfaces of the device, keyed by name",
+				Description:         "Interfaces of the device, keyed by name",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Identifier of the interface",
+							Description:         "Identifier of the interface",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Type of the interface, e.g. `ether` or `bridge`",
+							Description:         "Type of the interface, e.g. 'ether' or 'bridge'",
+							Computed:            true,
+						},
+						"default_name": schema.StringAttribute{
+							MarkdownDescription: "Factory name of the interface, if it is a physical one",
+							Description:         "Factory name of the interface, if it is a physical one",
+							Computed:            true,
+						},
+						"mac_address": schema.StringAttribute{
+							MarkdownDescription: "MAC address of the interface, if any",
+							Description:         "MAC address of the interface, if any",
+							Computed:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Comment attached to the interface",
+							Description:         "Comment attached to the interface",
+							Computed:            true,
+						},
+						"running": schema.BoolAttribute{
+							MarkdownDescription: "Whether the interface is up",
+							Description:         "Whether the interface is up",
+							Computed:            true,
+						},
+						"disabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the interface is disabled",
+							Description:         "Whether the interface is disabled",
+							Computed:            true,
+						},
+						"dynamic": schema.BoolAttribute{
+							MarkdownDescription: "Whether the interface was created dynamically, e.g. by a PPP or VPN connection",
+							Description:         "Whether the interface was created dynamically, e.g. by a PPP or VPN connection",
+							Computed:            true,
+						},
+						"lists": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Names of the interface lists the interface is an explicit member of",
+							Description:         "Names of the interface lists the interface is an explicit member of",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"lists": schema.MapNestedAttribute{
+				MarkdownDescription: "Interface lists of the device, keyed by name",
+				Description:         "Interface lists of the device, keyed by name",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Identifier of the list",
+							Description:         "Identifier of the list",
+							Computed:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Comment attached to the list",
+							Description:         "Comment attached to the list",
+							Computed:            true,
+						},
+						"builtin": schema.BoolAttribute{
+							MarkdownDescription: "Whether the list is one of the builtin lists, e.g. `all`, whose members are implicit",
+							Description:         "Whether the list is one of the builtin lists, e.g. 'all', whose members are implicit",
+							Computed:            true,
+						},
+						"include": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Names of the lists whose members are included in the list",
+							Description:         "Names of the lists whose members are included in the list",
+							Computed:            true,
+						},
+						"exclude": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Names of the lists whose members are excluded from the list",
+							Description:         "Names of the lists whose members are excluded from the list",
+							Computed:            true,
+						},
+						"members": schema.ListAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Names of the explicit members of the list. Members of builtin lists and of included lists are not listed",
+							Description:         "Names of the explicit members of the list. Members of builtin lists and of included lists are not listed",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *InterfacesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data InterfacesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	interfaces, err := d.client.GetInterfaces(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read interfaces"))
+		return
+	}
+	lists, err := d.client.GetInterfaceLists(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read interface lists"))
+		return
+	}
+	members, err := d.client.GetInterfaceListMembers(ctx, "")
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read interface list members"))
+		return
+	}
+
+	listsOf := map[string][]string{}
+	membersOf := map[string][]string{}
+	for _, m := range members {
+		listsOf[m.Interface] = append(listsOf[m.Interface], m.List)
+		membersOf[m.List] = append(membersOf[m.List], m.Interface)
+	}
+
+	data.ID = types.StringValue("interfaces")
+	data.Interfaces = make(map[string]InterfaceModel, len(interfaces))
+	for _, i := range interfaces {
+		data.Interfaces[i.Name] = InterfaceModel{
+			ID:          types.StringValue(i.ID),
+			Type:        stringOrNull(i.Type),
+			DefaultName: stringOrNull(i.DefaultName),
+			MACAddress:  stringOrNull(i.MACAddress),
+			Comment:     stringOrNull(i.Comment),
+			Running:     types.BoolValue(i.Running == "true"),
+			Disabled:    types.BoolValue(i.Disabled == "true"),
+			Dynamic:     types.BoolValue(i.Dynamic == "true"),
+			Lists:       sortedStringValues(listsOf[i.Name]),
+		}
+	}
+
+	data.Lists = make(map[string]InterfaceListModel, len(lists))
+	for _, l := range lists {
+		data.Lists[l.Name] = InterfaceListModel{
+			ID:      types.StringValue(l.ID),
+			Comment: stringOrNull(l.Comment),
+			Builtin: types.BoolValue(l.Builtin == "true"),
+			Include: sortedStringValues(splitCommaList(l.Include)),
+			Exclude: sortedStringValues(splitCommaList(l.Exclude)),
+			Members: sortedStringValues(membersOf[l.Name]),
+		}
+	}
+
+	for i, name := range data.RequireInterfaces {
+		if _, ok := data.Interfaces[name.ValueString()]; !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("require_interfaces").AtListIndex(i),
+				"Unknown Interface",
+				fmt.Sprintf("Interface '%s' does not exist on the device", name.ValueString()),
+			)
+		}
+	}
+	for i, name := range data.RequireLists {
+		if _, ok := data.Lists[name.ValueString()]; !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("require_lists").AtListIndex(i),
+				"Unknown Interface List",
+				fmt.Sprintf("Interface list '%s' does not exist on the device", name.ValueString()),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// splitCommaList splits a comma-separated RouterOS value. An empty value
+// yields no elements.
+func splitCommaList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// sortedStringValues returns the given strings in sorted order. The result is
+// never nil, so that empty lists are not stored as null.
+func sortedStringValues(s []string) []types.String {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	values := make([]types.String, 0, len(sorted))
+	for _, v := range sorted {
+		values = append(values, types.StringValue(v))
+	}
+	return values
+}
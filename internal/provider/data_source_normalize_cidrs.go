@@ -0,0 +1,107 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &NormalizeCIDRsDataSource{}
+
+func NewNormalizeCIDRsDataSource() datasource.DataSource {
+	return &NormalizeCIDRsDataSource{}
+}
+
+// NormalizeCIDRsDataSource normalizes a list of addresses and networks. Like
+// RangeToCIDRsDataSource, it does not talk to the device and takes the place
+// of a provider function.
+type NormalizeCIDRsDataSource struct{}
+
+// NormalizeCIDRsDataSourceModel describes the data source data model.
+type NormalizeCIDRsDataSourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	CIDRs      types.List   `tfsdk:"cidrs"`
+	Normalized types.List   `tfsdk:"normalized"`
+}
+
+func (d *NormalizeCIDRsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_normalize_cidrs"
+}
+
+func (d *NormalizeCIDRsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Normalizes a list of addresses and networks the way RouterOS reports them in address lists, so that feeding them into address list resources does not cause perpetual diffs, e.g. between `1.2.3.4/32` and `1.2.3.4`. Duplicates and networks contained in others are removed, and adjacent networks are aggregated. The normalization happens locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with",
+		Description:         "Normalizes a list of addresses and networks the way RouterOS reports them in address lists, so that feeding them into address list resources does not cause perpetual diffs, e.g. between '1.2.3.4/32' and '1.2.3.4'. Duplicates and networks contained in others are removed, and adjacent networks are aggregated. The normalization happens locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with",
+		Attributes: map[string]schema.Attribute{
+			"cidrs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "IPv4 or IPv6 addresses and networks, e.g. `10.0.0.1/32` or `10.0.0.0/24`",
+				Description:         "IPv4 or IPv6 addresses and networks, e.g. '10.0.0.1/32' or '10.0.0.0/24'",
+				Required:            true,
+			},
+			"normalized": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Normalized addresses and networks in ascending order. Single addresses are written without a prefix length, e.g. `10.0.0.1`",
+				Description:         "Normalized addresses and networks in ascending order. Single addresses are written without a prefix length, e.g. '10.0.0.1'",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *NormalizeCIDRsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data NormalizeCIDRsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var cidrs []string
+	resp.Diagnostics.Append(data.CIDRs.ElementsAs(ctx, &cidrs, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	normalized, err := client.NormalizeCIDRs(cidrs)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("cidrs"), "Invalid Address", fmt.Sprintf("Unable to normalize addresses, got error: %s", err))
+		return
+	}
+
+	list, diags := types.ListValueFrom(ctx, types.StringType, normalized)
+	resp.Diagnostics.Append(diags...)
+	data.Normalized = list
+	data.ID = types.StringValue(strings.Join(normalized, ","))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
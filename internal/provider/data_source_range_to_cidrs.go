@@ -0,0 +1,99 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &RangeToCIDRsDataSource{}
+
+func NewRangeToCIDRsDataSource() datasource.DataSource {
+	return &RangeToCIDRsDataSource{}
+}
+
+// RangeToCIDRsDataSource converts an address range to networks. It does not
+// talk to the device, and takes the place of a provider function, which the
+// plugin framework version in use does not support yet.
+type RangeToCIDRsDataSource struct{}
+
+// RangeToCIDRsDataSourceModel describes the data source data model.
+type RangeToCIDRsDataSourceModel struct {
+	ID    types.String `tfsdk:"id"`
+	Range types.String `tfsdk:"range"`
+	CIDRs types.List   `tfsdk:"cidrs"`
+}
+
+func (d *RangeToCIDRsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_range_to_cidrs"
+}
+
+func (d *RangeToCIDRsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Converts an address range, as accepted by RouterOS in address lists, to the minimal list of networks covering it, e.g. for data feeds which only accept CIDR notation. The conversion happens locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with",
+		Description:         "Converts an address range, as accepted by RouterOS in address lists, to the minimal list of networks covering it, e.g. for data feeds which only accept CIDR notation. The conversion happens locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with",
+		Attributes: map[string]schema.Attribute{
+			"range": schema.StringAttribute{
+				MarkdownDescription: "IPv4 or IPv6 address range such as `10.0.0.10-10.0.0.20`. A single address or network is accepted as well",
+				Description:         "IPv4 or IPv6 address range such as '10.0.0.10-10.0.0.20'. A single address or network is accepted as well",
+				Required:            true,
+			},
+			"cidrs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Networks covering exactly the addresses of the range in ascending order, always in CIDR notation, e.g. `10.0.0.20/32`",
+				Description:         "Networks covering exactly the addresses of the range in ascending order, always in CIDR notation, e.g. '10.0.0.20/32'",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *RangeToCIDRsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data RangeToCIDRsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cidrs, err := client.RangeToCIDRs(data.Range.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("range"), "Invalid Address Range", fmt.Sprintf("Unable to convert address range, got error: %s", err))
+		return
+	}
+
+	list, diags := types.ListValueFrom(ctx, types.StringType, cidrs)
+	resp.Diagnostics.Append(diags...)
+	data.CIDRs = list
+	data.ID = data.Range
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,223 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &RuleCountersDataSource{}
+
+func NewRuleCountersDataSource() datasource.DataSource {
+	return &RuleCountersDataSource{}
+}
+
+// RuleCountersDataSource defines the data source implementation.
+type RuleCountersDataSource struct {
+	client client.API
+}
+
+// RuleCountersDataSourceModel describes the data source data model.
+type RuleCountersDataSourceModel struct {
+	ID       types.String                 `tfsdk:"id"`
+	RuleType types.String                 `tfsdk:"rule_type"`
+	Rules    []types.String               `tfsdk:"rules"`
+	Counters map[string]RuleCountersModel `tfsdk:"counters"`
+}
+
+// RuleCountersModel describes the counters of a single rule.
+type RuleCountersModel struct {
+	ID      types.String `tfsdk:"id"`
+	Chain   types.String `tfsdk:"chain"`
+	Comment types.String `tfsdk:"comment"`
+	Bytes   types.Int64  `tfsdk:"bytes"`
+	Packets types.Int64  `tfsdk:"packets"`
+}
+
+func (d *RuleCountersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_counters"
+}
+
+func (d *RuleCountersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *RuleCountersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Hit counters of firewall rules, e.g. for checks asserting that a rule never matches or for exporting counters to dashboards. All counters are read with a single request",
+		Description:         "Hit counters of firewall rules, e.g. for checks asserting that a rule never matches or for exporting counters to dashboards. All counters are read with a single request",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule type to read counters from",
+				Description:         "The rule type to read counters from",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+				},
+			},
+			"rules": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Rules to read counters of, referenced either by their RouterOS ID, e.g. `*1A`, or by their comment, e.g. `comment:drop invalid`. Comment references must match exactly one rule. If unset, the counters of all rules of the table are read",
+				Description:         "Rules to read counters of, referenced either by their RouterOS ID, e.g. '*1A', or by their comment, e.g. 'comment:drop invalid'. Comment references must match exactly one rule. If unset, the counters of all rules of the table are read",
+				Optional:            true,
+			},
+			"counters": schema.MapNestedAttribute{
+				MarkdownDescription: "Counters of each rule, keyed by its reference in `rules`, or by its ID if `rules` is unset",
+				Description:         "Counters of each rule, keyed by its reference in 'rules', or by its ID if 'rules' is unset",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "RouterOS ID of the rule",
+							Description:         "RouterOS ID of the rule",
+							Computed:            true,
+						},
+						"chain": schema.StringAttribute{
+							MarkdownDescription: "Chain the rule belongs to",
+							Description:         "Chain the rule belongs to",
+							Computed:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Comment of the rule",
+							Description:         "Comment of the rule",
+							Computed:            true,
+						},
+						"bytes": schema.Int64Attribute{
+							MarkdownDescription: "Number of bytes matched by the rule",
+							Description:         "Number of bytes matched by the rule",
+							Computed:            true,
+						},
+						"packets": schema.Int64Attribute{
+							MarkdownDescription: "Number of packets matched by the rule",
+							Description:         "Number of packets matched by the rule",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *RuleCountersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data RuleCountersDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules, err := d.client.ListRuleProperties(ctx, data.RuleType.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read rule counters"))
+		return
+	}
+
+	data.Counters = make(map[string]RuleCountersModel, len(rules))
+	if data.Rules == nil {
+		for _, props := range rules {
+			data.Counters[props[".id"]] = ruleCounters(props)
+		}
+	}
+
+	for i, ref := range data.Rules {
+		props, err := findRuleProperties(rules, ref.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rules").AtListIndex(i),
+				"Unknown Rule",
+				fmt.Sprintf("Unable to read counters of %s rule '%s': %s", data.RuleType.ValueString(), ref.ValueString(), err),
+			)
+			continue
+		}
+		data.Counters[ref.ValueString()] = ruleCounters(props)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.RuleType
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func ruleCounters(props map[string]string) RuleCountersModel {
+	return RuleCountersModel{
+		ID:      types.StringValue(props[".id"]),
+		Chain:   types.StringValue(props["chain"]),
+		Comment: stringOrNull(props["comment"]),
+		Bytes:   int64OrNull(props["bytes"]),
+		Packets: int64OrNull(props["packets"]),
+	}
+}
+
+// findRuleProperties returns the properties of the rule identified by ref,
+// see client.ResolveRuleReference.
+func findRuleProperties(rules []map[string]string, ref string) (map[string]string, error) {
+	var matches []map[string]string
+	for _, props := range rules {
+		if chain, comment, ok := client.ParseCommentReference(ref); ok {
+			if props["comment"] == comment && (chain == "" || props["chain"] == chain) {
+				matches = append(matches, props)
+			}
+		} else if props[".id"] == ref {
+			matches = append(matches, props)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, client.ErrRuleNotFound
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, props := range matches {
+			ids = append(ids, props[".id"])
+		}
+		return nil, fmt.Errorf("the reference is ambiguous, it matches the rules %s", strings.Join(ids, ", "))
+	}
+}
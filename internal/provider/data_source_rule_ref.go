@@ -0,0 +1,155 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &RuleRefDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &RuleRefDataSource{}
+
+func NewRuleRefDataSource() datasource.DataSource {
+	return &RuleRefDataSource{}
+}
+
+func NewRuleRefChainDataSource() datasource.DataSource {
+	return &RuleRefDataSource{chain: true}
+}
+
+// RuleRefDataSource builds the comment references accepted by the rule
+// ordering resources, see client.CommentReference. Like
+// RangeToCIDRsDataSource, it does not talk to the device and takes the place
+// of a provider function. The chain variant additionally requires a chain and
+// builds a client.ChainCommentReference.
+type RuleRefDataSource struct {
+	chain bool
+}
+
+func (d *RuleRefDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_ref"
+	if d.chain {
+		resp.TypeName += "_chain"
+	}
+}
+
+func (d *RuleRefDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Builds the reference to a rule by its comment, e.g. `comment:allow ssh`, as accepted by `rule_ordering` and the other resources which reference rules. The comment is validated while planning. The reference is built locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with",
+		Description:         "Builds the reference to a rule by its comment, e.g. 'comment:allow ssh', as accepted by 'rule_ordering' and the other resources which reference rules. The comment is validated while planning. The reference is built locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with",
+		Attributes: map[string]schema.Attribute{
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment of the rule. Must not be empty",
+				Description:         "Comment of the rule. Must not be empty",
+				Required:            true,
+			},
+			"ref": schema.StringAttribute{
+				MarkdownDescription: "Reference to the rule, e.g. `comment:allow ssh`",
+				Description:         "Reference to the rule, e.g. 'comment:allow ssh'",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+
+	if d.chain {
+		resp.Schema.MarkdownDescription = "Builds the reference to a rule of a chain by its comment, e.g. `chain:input,comment:allow ssh`, as accepted by `rule_ordering` and the other resources which reference rules. Unlike the references built by `rule_ref`, only rules of the chain match, so the same comment may be used in several chains. The chain and comment are validated while planning. The reference is built locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with"
+		resp.Schema.Description = "Builds the reference to a rule of a chain by its comment, e.g. 'chain:input,comment:allow ssh', as accepted by 'rule_ordering' and the other resources which reference rules. Unlike the references built by 'rule_ref', only rules of the chain match, so the same comment may be used in several chains. The chain and comment are validated while planning. The reference is built locally, no requests are made to the device. It is a data source rather than a provider function, as provider functions require Terraform 1.8 and a newer version of the plugin framework than this provider is built with"
+		resp.Schema.Attributes["chain"] = schema.StringAttribute{
+			MarkdownDescription: "Chain of the rule, e.g. `input`. Must not contain commas",
+			Description:         "Chain of the rule, e.g. 'input'. Must not contain commas",
+			Required:            true,
+		}
+		resp.Schema.Attributes["ref"] = schema.StringAttribute{
+			MarkdownDescription: "Reference to the rule, e.g. `chain:input,comment:allow ssh`",
+			Description:         "Reference to the rule, e.g. 'chain:input,comment:allow ssh'",
+			Computed:            true,
+		}
+	}
+}
+
+// config returns the chain and comment of the configuration. The chain is
+// null for `rule_ref`, which has none.
+func (d *RuleRefDataSource) config(ctx context.Context, c tfsdk.Config) (chain, comment types.String, diags diag.Diagnostics) {
+	chain = types.StringNull()
+	if d.chain {
+		diags.Append(c.GetAttribute(ctx, path.Root("chain"), &chain)...)
+	}
+	diags.Append(c.GetAttribute(ctx, path.Root("comment"), &comment)...)
+	return chain, comment, diags
+}
+
+// validate validates the chain and comment, unless they are not known yet.
+// References are validated while planning and again on read, for values which
+// only became known during apply.
+func (d *RuleRefDataSource) validate(chain, comment types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !comment.IsNull() && !comment.IsUnknown() {
+		if err := client.ValidateReferenceComment(comment.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("comment"), "Invalid Rule Reference", fmt.Sprintf("Unable to build a rule reference: %s", err))
+		}
+	}
+	if d.chain && !chain.IsNull() && !chain.IsUnknown() {
+		if err := client.ValidateReferenceChain(chain.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("chain"), "Invalid Rule Reference", fmt.Sprintf("Unable to build a rule reference: %s", err))
+		}
+	}
+	return diags
+}
+
+func (d *RuleRefDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	chain, comment, diags := d.config(ctx, req.Config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(d.validate(chain, comment)...)
+}
+
+func (d *RuleRefDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	chain, comment, diags := d.config(ctx, req.Config)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(d.validate(chain, comment)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref := client.CommentReference(comment.ValueString())
+	if d.chain {
+		ref = client.ChainCommentReference(chain.ValueString(), comment.ValueString())
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("chain"), chain)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("comment"), comment)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ref"), ref)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), ref)...)
+}
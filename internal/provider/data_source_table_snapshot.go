@@ -0,0 +1,262 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TableSnapshotDataSource{}
+var _ datasource.DataSourceWithConfigValidators = &TableSnapshotDataSource{}
+
+func NewTableSnapshotDataSource() datasource.DataSource {
+	return &TableSnapshotDataSource{}
+}
+
+// TableSnapshotDataSource defines the data source implementation.
+type TableSnapshotDataSource struct {
+	client client.API
+}
+
+// TableSnapshotDataSourceModel describes the data source data model.
+type TableSnapshotDataSourceModel struct {
+	ID          types.String        `tfsdk:"id"`
+	RuleType    types.String        `tfsdk:"rule_type"`
+	RuleTypes   []types.String      `tfsdk:"rule_types"`
+	ManagedOnly types.Bool          `tfsdk:"managed_only"`
+	Rules       []SnapshotRuleModel `tfsdk:"rules"`
+}
+
+// SnapshotRuleModel describes a single rule of a table snapshot.
+type SnapshotRuleModel struct {
+	ID         types.String `tfsdk:"id"`
+	RuleType   types.String `tfsdk:"rule_type"`
+	Position   types.Int64  `tfsdk:"position"`
+	Chain      types.String `tfsdk:"chain"`
+	Action     types.String `tfsdk:"action"`
+	Comment    types.String `tfsdk:"comment"`
+	Disabled   types.Bool   `tfsdk:"disabled"`
+	Dynamic    types.Bool   `tfsdk:"dynamic"`
+	Managed    types.Bool   `tfsdk:"managed"`
+	Bytes      types.Int64  `tfsdk:"bytes"`
+	Packets    types.Int64  `tfsdk:"packets"`
+	Properties types.Map    `tfsdk:"properties"`
+}
+
+func (d *TableSnapshotDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_table_snapshot"
+}
+
+func (d *TableSnapshotDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.client = client
+}
+
+func (d *TableSnapshotDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Complete, ordered snapshot of a rule table, e.g. for producing audit artifacts or reviewing changes in CI",
+		Description:         "Complete, ordered snapshot of a rule table, e.g. for producing audit artifacts or reviewing changes in CI",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule type to take a snapshot of. Exactly one of `rule_type` and `rule_types` must be set",
+				Description:         "The rule type to take a snapshot of. Exactly one of 'rule_type' and 'rule_types' must be set",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+				},
+			},
+			"rule_types": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "The rule types to take a snapshot of, e.g. `[\"filter\", \"nat\", \"mangle\", \"raw\"]`. The tables are read in parallel, and their rules are listed in the given order of tables. Exactly one of `rule_type` and `rule_types` must be set",
+				Description:         "The rule types to take a snapshot of, e.g. '[\"filter\", \"nat\", \"mangle\", \"raw\"]'. The tables are read in parallel, and their rules are listed in the given order of tables. Exactly one of 'rule_type' and 'rule_types' must be set",
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(
+						stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+					),
+				},
+			},
+			"managed_only": schema.BoolAttribute{
+				MarkdownDescription: "Whether to include only rules which were created by this provider configuration, i.e. whose comment carries the provider's `managed_comment_prefix` or its workspace tag, e.g. to find rules which are no longer part of any configuration. Defaults to `false`",
+				Description:         "Whether to include only rules which were created by this provider configuration, i.e. whose comment carries the provider's 'managed_comment_prefix' or its workspace tag, e.g. to find rules which are no longer part of any configuration. Defaults to 'false'",
+				Optional:            true,
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "All rules of the tables in their current order",
+				Description:         "All rules of the tables in their current order",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "RouterOS ID of the rule",
+							Description:         "RouterOS ID of the rule",
+							Computed:            true,
+						},
+						"rule_type": schema.StringAttribute{
+							MarkdownDescription: "Rule type of the table the rule belongs to",
+							Description:         "Rule type of the table the rule belongs to",
+							Computed:            true,
+						},
+						"position": schema.Int64Attribute{
+							MarkdownDescription: "Zero-based index of the rule within the table",
+							Description:         "Zero-based index of the rule within the table",
+							Computed:            true,
+						},
+						"chain": schema.StringAttribute{
+							MarkdownDescription: "Chain the rule belongs to",
+							Description:         "Chain the rule belongs to",
+							Computed:            true,
+						},
+						"action": schema.StringAttribute{
+							MarkdownDescription: "Action taken for matching packets",
+							Description:         "Action taken for matching packets",
+							Computed:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Comment of the rule, if any",
+							Description:         "Comment of the rule, if any",
+							Computed:            true,
+						},
+						"disabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the rule is disabled",
+							Description:         "Whether the rule is disabled",
+							Computed:            true,
+						},
+						"dynamic": schema.BoolAttribute{
+							MarkdownDescription: "Whether the rule was added dynamically, e.g. by UPnP",
+							Description:         "Whether the rule was added dynamically, e.g. by UPnP",
+							Computed:            true,
+						},
+						"managed": schema.BoolAttribute{
+							MarkdownDescription: "Whether the rule was created by this provider configuration, see `managed_only`",
+							Description:         "Whether the rule was created by this provider configuration, see 'managed_only'",
+							Computed:            true,
+						},
+						"bytes": schema.Int64Attribute{
+							MarkdownDescription: "Number of bytes matched by the rule",
+							Description:         "Number of bytes matched by the rule",
+							Computed:            true,
+						},
+						"packets": schema.Int64Attribute{
+							MarkdownDescription: "Number of packets matched by the rule",
+							Description:         "Number of packets matched by the rule",
+							Computed:            true,
+						},
+						"properties": schema.MapAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "All properties of the rule as reported by RouterOS, e.g. `src-address`",
+							Description:         "All properties of the rule as reported by RouterOS, e.g. 'src-address'",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of data source",
+				MarkdownDescription: "Identifier of data source",
+			},
+		},
+	}
+}
+
+func (d *TableSnapshotDataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.ExactlyOneOf(path.MatchRoot("rule_type"), path.MatchRoot("rule_types")),
+	}
+}
+
+func (d *TableSnapshotDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TableSnapshotDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleTypes := []string{data.RuleType.ValueString()}
+	if data.RuleType.IsNull() {
+		ruleTypes = make([]string, 0, len(data.RuleTypes))
+		for _, ruleType := range data.RuleTypes {
+			ruleTypes = append(ruleTypes, ruleType.ValueString())
+		}
+	}
+
+	tables, err := d.client.GetTables(ctx, ruleTypes)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read table snapshot"))
+		return
+	}
+
+	data.Rules = []SnapshotRuleModel{}
+	for _, ruleType := range ruleTypes {
+		table := tables[ruleType]
+		for i, props := range table.Rules {
+			if data.ManagedOnly.ValueBool() && !table.Managed[props[".id"]] {
+				continue
+			}
+			properties, diags := types.MapValueFrom(ctx, types.StringType, props)
+			resp.Diagnostics.Append(diags...)
+
+			data.Rules = append(data.Rules, SnapshotRuleModel{
+				ID:         types.StringValue(props[".id"]),
+				RuleType:   types.StringValue(ruleType),
+				Position:   types.Int64Value(int64(i)),
+				Chain:      types.StringValue(props["chain"]),
+				Action:     types.StringValue(props["action"]),
+				Comment:    stringOrNull(props["comment"]),
+				Disabled:   types.BoolValue(props["disabled"] == "true"),
+				Dynamic:    types.BoolValue(props["dynamic"] == "true"),
+				Managed:    types.BoolValue(table.Managed[props[".id"]]),
+				Bytes:      int64OrNull(props["bytes"]),
+				Packets:    int64OrNull(props["packets"]),
+				Properties: properties,
+			})
+		}
+	}
+
+	data.ID = types.StringValue(strings.Join(ruleTypes, ","))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
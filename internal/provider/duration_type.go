@@ -0,0 +1,172 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/rosmap"
+)
+
+// Ensure the duration type fully satisfies framework interfaces.
+var _ basetypes.StringTypable = DurationType{}
+var _ xattr.TypeWithValidate = DurationType{}
+var _ basetypes.StringValuableWithSemanticEquals = DurationValue{}
+
+// DurationType is a string attribute type for durations. Values may be
+// written as a number of seconds, e.g. `86400`, as a Go duration, e.g. `24h`,
+// or in any of the formats used by RouterOS, e.g. `1d` or `1d00:00:00`.
+// Values denoting the same duration are semantically equal, so that the
+// format in which RouterOS reports a duration never shows up as a diff.
+type DurationType struct {
+	basetypes.StringType
+}
+
+func (t DurationType) Equal(o attr.Type) bool {
+	other, ok := o.(DurationType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t DurationType) String() string {
+	return "DurationType"
+}
+
+func (t DurationType) ValueType(ctx context.Context) attr.Value {
+	return DurationValue{}
+}
+
+func (t DurationType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return DurationValue{StringValue: in}, nil
+}
+
+func (t DurationType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	s, err := stringFromTerraform(ctx, t.StringType, in)
+	if err != nil {
+		return nil, err
+	}
+	return DurationValue{StringValue: s}, nil
+}
+
+// Validate rejects values which are not a duration.
+func (t DurationType) Validate(ctx context.Context, in tftypes.Value, p path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !in.IsKnown() || in.IsNull() {
+		return diags
+	}
+
+	var s string
+	if err := in.As(&s); err != nil {
+		diags.AddAttributeError(p, "Invalid Duration", fmt.Sprintf("Unable to convert value to a string, got error: %s", err))
+		return diags
+	}
+	if d, err := parseDuration(s); err != nil || d < 0 {
+		diags.AddAttributeError(p, "Invalid Duration", fmt.Sprintf("Expected a duration such as '1h30m', '1d' or '86400', got: %s", s))
+	}
+	return diags
+}
+
+// DurationValue is a value of DurationType.
+type DurationValue struct {
+	basetypes.StringValue
+}
+
+func (v DurationValue) Equal(o attr.Value) bool {
+	other, ok := o.(DurationValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v DurationValue) Type(ctx context.Context) attr.Type {
+	return DurationType{}
+}
+
+// StringSemanticEquals reports whether both values denote the same duration.
+func (v DurationValue) StringSemanticEquals(ctx context.Context, o basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	other, ok := o.(DurationValue)
+	if !ok {
+		return false, diags
+	}
+
+	a, err := v.Duration()
+	if err != nil {
+		return false, diags
+	}
+	b, err := other.Duration()
+	if err != nil {
+		return false, diags
+	}
+	return a == b, diags
+}
+
+// Duration returns the duration denoted by the value, or zero if it is null
+// or unknown.
+func (v DurationValue) Duration() (time.Duration, error) {
+	if v.IsNull() || v.IsUnknown() {
+		return 0, nil
+	}
+	return parseDuration(v.ValueString())
+}
+
+// RouterOS returns the value in the format used by RouterOS, e.g. `1d2h`, or
+// an empty string if it is null or unknown. RouterOS durations have a
+// resolution of one second, so fractions of seconds are rounded up.
+func (v DurationValue) RouterOS() string {
+	d, err := v.Duration()
+	if err != nil || v.IsNull() || v.IsUnknown() {
+		return ""
+	}
+	return rosmap.FormatDuration(int64((d + time.Second - 1) / time.Second))
+}
+
+// NewDurationValue returns a known DurationValue.
+func NewDurationValue(s string) DurationValue {
+	return DurationValue{StringValue: basetypes.NewStringValue(s)}
+}
+
+// NewDurationNull returns a null DurationValue.
+func NewDurationNull() DurationValue {
+	return DurationValue{StringValue: basetypes.NewStringNull()}
+}
+
+// parseDuration parses a duration given as a Go duration or in one of the
+// formats used by RouterOS, see DurationType.
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	secs, err := rosmap.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs) * time.Second, nil
+}
@@ -0,0 +1,79 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDurationValueStringSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1d", "24h", true},
+		{"1d2h", "26h", true},
+		{"1w3d", "240h", true},
+		{"00:05:00", "5m", true},
+		{"1d00:00:01", "1d1s", true},
+		{"90", "1m30s", true},
+		{"1500ms", "1s500ms", true},
+		{"1h", "61m", false},
+		{"1d", "1d1s", false},
+		{"invalid", "invalid", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got, diags := NewDurationValue(tt.a).StringSemanticEquals(context.Background(), NewDurationValue(tt.b))
+			if diags.HasError() {
+				t.Fatalf("StringSemanticEquals() returned diagnostics: %v", diags)
+			}
+			if got != tt.want {
+				t.Errorf("StringSemanticEquals(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationValueRouterOS(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"24h", "1d"},
+		{"90m", "1h30m"},
+		{"1500ms", "2s"},
+		{"1w3d", "1w3d"},
+		{"invalid", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := NewDurationValue(tt.s).RouterOS(); got != tt.want {
+				t.Errorf("RouterOS(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+
+	if got := NewDurationNull().RouterOS(); got != "" {
+		t.Errorf("RouterOS() of null = %q, want empty", got)
+	}
+}
@@ -0,0 +1,384 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+type ruleAttributeKind int
+
+const (
+	ruleAttributeString ruleAttributeKind = iota
+	ruleAttributeBool
+	// ruleAttributeDuration is a string holding a duration, see DurationType.
+	ruleAttributeDuration
+	// ruleAttributeAddress is a string holding an address matcher, see
+	// CIDRType.
+	ruleAttributeAddress
+)
+
+// customType returns the attribute type of string attributes of the kind, or
+// nil for plain strings.
+func (k ruleAttributeKind) customType() basetypes.StringTypable {
+	switch k {
+	case ruleAttributeDuration:
+		return DurationType{}
+	case ruleAttributeAddress:
+		return CIDRType{}
+	}
+	return nil
+}
+
+// ruleAttribute maps a single Terraform attribute of a firewall rule resource
+// to its RouterOS property. The attributes of the rule resources are generated
+// from ruleCatalog, see ruleAttributesFor.
+type ruleAttribute struct {
+	// name is the name of the Terraform attribute, e.g. `src_address`.
+	name string
+	// property is the name of the RouterOS property, e.g. `src-address`.
+	property    string
+	description string
+	kind        ruleAttributeKind
+	required    bool
+	// computed must be set for properties which RouterOS assigns a default
+	// value to if they are not specified.
+	computed   bool
+	validators []validator.String
+}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &firewallRuleResource{}
+var _ resource.ResourceWithImportState = &firewallRuleResource{}
+
+// firewallRuleResource implements a resource for a single rule of a firewall
+// table. The concrete resources only differ in their table and the set of
+// supported attributes.
+type firewallRuleResource struct {
+	client client.API
+
+	ruleType    string
+	typeName    string
+	description string
+	attributes  []ruleAttribute
+}
+
+func (r *firewallRuleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + r.typeName
+}
+
+func (r *firewallRuleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *firewallRuleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed:            true,
+			Description:         "Identifier of resource",
+			MarkdownDescription: "Identifier of resource",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"place_before": schema.StringAttribute{
+			Optional:            true,
+			Description:         "Rule which the new rule is inserted in front of, referenced either by its ID or by its comment, e.g. 'comment:drop all else'. The rule is created at this position directly, so there is no window in which it is in effect at the end of the table. Only applies when the rule is created, changing it afterwards neither moves nor replaces the rule. Use the 'rule_ordering' resource to keep it in place afterwards",
+			MarkdownDescription: "Rule which the new rule is inserted in front of, referenced either by its ID or by its comment, e.g. `comment:drop all else`. The rule is created at this position directly, so there is no window in which it is in effect at the end of the table. Only applies when the rule is created, changing it afterwards neither moves nor replaces the rule. Use the `rule_ordering` resource to keep it in place afterwards",
+		},
+	}
+
+	for _, a := range r.attributes {
+		switch a.kind {
+		case ruleAttributeBool:
+			attr := schema.BoolAttribute{
+				Description:         a.description,
+				MarkdownDescription: a.description,
+				Required:            a.required,
+				Optional:            !a.required,
+				Computed:            a.computed,
+			}
+			if a.computed {
+				attr.PlanModifiers = []planmodifier.Bool{boolplanmodifier.UseStateForUnknown()}
+			}
+			attributes[a.name] = attr
+		default:
+			attr := schema.StringAttribute{
+				CustomType:          a.kind.customType(),
+				Description:         a.description,
+				MarkdownDescription: a.description,
+				Required:            a.required,
+				Optional:            !a.required,
+				Computed:            a.computed,
+				Validators:          a.validators,
+			}
+			if a.computed {
+				attr.PlanModifiers = []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+			}
+			attributes[a.name] = attr
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: r.description,
+		Description:         r.description,
+		Attributes:          attributes,
+	}
+}
+
+func (r *firewallRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	props, diags := r.propertiesFrom(ctx, req.Plan, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var placeBefore types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("place_before"), &placeBefore)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if ref := placeBefore.ValueString(); ref != "" {
+		target, err := r.client.ResolveRuleReference(ctx, r.ruleType, ref)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("place_before"),
+				"Unknown Rule",
+				fmt.Sprintf("Unable to resolve %s rule '%s', got error: %s", r.ruleType, ref, err),
+			)
+			return
+		}
+		props[client.PlaceBeforeProperty] = target.ID
+	}
+
+	created, err := r.client.CreateRule(ctx, r.ruleType, props)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, fmt.Sprintf("Unable to create %s rule", r.ruleType)))
+		return
+	}
+
+	resp.Diagnostics.Append(r.setState(ctx, &resp.State, &req.Plan, created)...)
+}
+
+func (r *firewallRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var id types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	props, err := r.client.GetRuleProperties(ctx, r.ruleType, id.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, fmt.Sprintf("Unable to read %s rule", r.ruleType)))
+		return
+	}
+
+	resp.Diagnostics.Append(r.setState(ctx, &resp.State, nil, props)...)
+}
+
+func (r *firewallRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var id types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	props, diags := r.propertiesFrom(ctx, req.Plan, true)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateRule(ctx, r.ruleType, id.ValueString(), props)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, fmt.Sprintf("Unable to update %s rule", r.ruleType)))
+		return
+	}
+
+	resp.Diagnostics.Append(r.setState(ctx, &resp.State, &req.Plan, updated)...)
+}
+
+func (r *firewallRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var id types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteRule(ctx, r.ruleType, id.ValueString())
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(clientError(err, fmt.Sprintf("Unable to delete %s rule", r.ruleType)))
+	}
+}
+
+func (r *firewallRuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// propertiesFrom converts the planned attribute values to RouterOS
+// properties. Unset attributes are omitted on creation, and cleared when
+// updating an existing rule.
+func (r *firewallRuleResource) propertiesFrom(ctx context.Context, plan tfsdk.Plan, update bool) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	props := map[string]string{}
+
+	for _, a := range r.attributes {
+		switch a.kind {
+		case ruleAttributeBool:
+			var v types.Bool
+			diags.Append(plan.GetAttribute(ctx, path.Root(a.name), &v)...)
+			if !v.IsNull() && !v.IsUnknown() {
+				props[a.property] = strconv.FormatBool(v.ValueBool())
+			}
+		case ruleAttributeDuration:
+			var v DurationValue
+			diags.Append(plan.GetAttribute(ctx, path.Root(a.name), &v)...)
+			if !v.IsNull() && !v.IsUnknown() {
+				props[a.property] = v.RouterOS()
+			} else if v.IsNull() && update {
+				props[a.property] = ""
+			}
+		case ruleAttributeAddress:
+			var v CIDRValue
+			diags.Append(plan.GetAttribute(ctx, path.Root(a.name), &v)...)
+			if !v.IsNull() && !v.IsUnknown() {
+				props[a.property] = v.ValueString()
+			} else if v.IsNull() && update {
+				props[a.property] = ""
+			}
+		default:
+			var v types.String
+			diags.Append(plan.GetAttribute(ctx, path.Root(a.name), &v)...)
+			if !v.IsNull() && !v.IsUnknown() {
+				props[a.property] = v.ValueString()
+			} else if v.IsNull() && update {
+				props[a.property] = ""
+			}
+		}
+	}
+
+	return props, diags
+}
+
+// setState stores the rule's properties in state. If a plan is passed,
+// configured values are taken from the plan as-is, since RouterOS may
+// normalize them (e.g. `tcp` vs `6`), and only unknown values are filled in
+// from the device.
+func (r *firewallRuleResource) setState(ctx context.Context, state *tfsdk.State, plan *tfsdk.Plan, props map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(state.SetAttribute(ctx, path.Root("id"), types.StringValue(props[".id"]))...)
+	if plan != nil {
+		var placeBefore types.String
+		diags.Append(plan.GetAttribute(ctx, path.Root("place_before"), &placeBefore)...)
+		diags.Append(state.SetAttribute(ctx, path.Root("place_before"), placeBefore)...)
+	}
+
+	for _, a := range r.attributes {
+		p := path.Root(a.name)
+		value, ok := props[a.property]
+
+		switch a.kind {
+		case ruleAttributeBool:
+			v := types.BoolNull()
+			if ok {
+				v = types.BoolValue(value == "true" || value == "yes")
+			}
+			if plan != nil {
+				var planned types.Bool
+				diags.Append(plan.GetAttribute(ctx, p, &planned)...)
+				if !planned.IsUnknown() {
+					v = planned
+				}
+			}
+			diags.Append(state.SetAttribute(ctx, p, v)...)
+		case ruleAttributeDuration:
+			v := NewDurationNull()
+			if value != "" {
+				v = NewDurationValue(value)
+			}
+			if plan != nil {
+				var planned DurationValue
+				diags.Append(plan.GetAttribute(ctx, p, &planned)...)
+				if !planned.IsUnknown() {
+					v = planned
+				}
+			}
+			diags.Append(state.SetAttribute(ctx, p, v)...)
+		case ruleAttributeAddress:
+			v := CIDRValue{StringValue: stringOrNull(value)}
+			if plan != nil {
+				var planned CIDRValue
+				diags.Append(plan.GetAttribute(ctx, p, &planned)...)
+				if !planned.IsUnknown() {
+					v = planned
+				}
+			}
+			diags.Append(state.SetAttribute(ctx, p, v)...)
+		default:
+			v := stringOrNull(value)
+			if plan != nil {
+				var planned types.String
+				diags.Append(plan.GetAttribute(ctx, p, &planned)...)
+				if !planned.IsUnknown() {
+					v = planned
+				}
+			}
+			diags.Append(state.SetAttribute(ctx, p, v)...)
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,119 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// stringOrNull maps RouterOS' empty string values to null, so that optional
+// attributes which are not set in the configuration do not produce a diff.
+func stringOrNull(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+// setFromCommaList converts a comma-separated RouterOS value to a set of
+// strings. An empty value yields a null set.
+func setFromCommaList(ctx context.Context, s string) (types.Set, diag.Diagnostics) {
+	if s == "" {
+		return types.SetNull(types.StringType), nil
+	}
+	return types.SetValueFrom(ctx, types.StringType, strings.Split(s, ","))
+}
+
+// commaListFromSet is the inverse of setFromCommaList.
+func commaListFromSet(ctx context.Context, set types.Set) (string, diag.Diagnostics) {
+	elems := make([]string, 0, len(set.Elements()))
+	diags := set.ElementsAs(ctx, &elems, false)
+	return strings.Join(elems, ","), diags
+}
+
+// stringSlicesEqual reports whether a and b contain the same elements in the
+// same order.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// int64OrNull parses a numeric RouterOS value, mapping missing or malformed
+// values to null.
+func int64OrNull(s string) types.Int64 {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return types.Int64Null()
+	}
+	return types.Int64Value(i)
+}
+
+// propertiesFromMap returns the RouterOS properties configured in m. Keys of
+// previous which are no longer part of m are cleared.
+func propertiesFromMap(ctx context.Context, m, previous types.Map) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	props := map[string]string{}
+
+	if !previous.IsNull() && !previous.IsUnknown() {
+		old := map[string]string{}
+		diags.Append(previous.ElementsAs(ctx, &old, false)...)
+		for k := range old {
+			props[k] = ""
+		}
+	}
+	if !m.IsNull() && !m.IsUnknown() {
+		configured := map[string]string{}
+		diags.Append(m.ElementsAs(ctx, &configured, false)...)
+		for k, v := range configured {
+			props[k] = v
+		}
+	}
+
+	return props, diags
+}
+
+// managedProperties returns the values in props of the keys of m, so that
+// properties which are not configured via m are ignored. A null map stays
+// null.
+func managedProperties(ctx context.Context, m types.Map, props map[string]string) (types.Map, diag.Diagnostics) {
+	if m.IsNull() || m.IsUnknown() {
+		return m, nil
+	}
+
+	managed := map[string]string{}
+	diags := m.ElementsAs(ctx, &managed, false)
+	for k := range managed {
+		managed[k] = props[k]
+	}
+	values, d := types.MapValueFrom(ctx, types.StringType, managed)
+	diags.Append(d...)
+	return values, diags
+}
@@ -0,0 +1,216 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// hostModel describes a single entry of the provider's `hosts` map. Unset
+// attributes are inherited from the provider configuration.
+type hostModel struct {
+	HostURL             types.String `tfsdk:"hosturl"`
+	Port                types.Int64  `tfsdk:"port"`
+	BasePath            types.String `tfsdk:"base_path"`
+	Username            types.String `tfsdk:"username"`
+	Password            types.String `tfsdk:"password"`
+	AuthorizationHeader types.String `tfsdk:"authorization_header"`
+	CA                  types.String `tfsdk:"ca_certificate"`
+	Insecure            types.Bool   `tfsdk:"insecure"`
+	TLSServerName       types.String `tfsdk:"tls_server_name"`
+	TLSFingerprint      types.String `tfsdk:"tls_fingerprint_sha256"`
+}
+
+var hostsAttribute = schema.MapNestedAttribute{
+	Optional:            true,
+	Description:         "Additional devices which resources can be applied to by setting their 'host' attribute to the key of the device. Unset attributes of a device are inherited from the provider configuration",
+	MarkdownDescription: "Additional devices which resources can be applied to by setting their `host` attribute to the key of the device. Unset attributes of a device are inherited from the provider configuration",
+	NestedObject: schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"hosturl": schema.StringAttribute{
+				Required:            true,
+				Description:         "Address of the device, see the provider's 'hosturl'",
+				MarkdownDescription: "Address of the device, see the provider's `hosturl`",
+			},
+			"port": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Port of the REST API service",
+				MarkdownDescription: "Port of the REST API service",
+			},
+			"base_path": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Path the REST API is served at, see the provider's 'base_path'",
+				MarkdownDescription: "Path the REST API is served at, see the provider's `base_path`",
+			},
+			"username": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Username to use for API authentication",
+				MarkdownDescription: "Username to use for API authentication",
+			},
+			"password": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				Description:         "Password to use for API authentication",
+				MarkdownDescription: "Password to use for API authentication",
+			},
+			"authorization_header": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				Description:         "Value of the Authorization header sent with every API request, see the provider's 'authorization_header'",
+				MarkdownDescription: "Value of the `Authorization` header sent with every API request, see the provider's `authorization_header`",
+			},
+			"ca_certificate": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Path to the CA root certificate",
+				MarkdownDescription: "Path to the CA root certificate",
+			},
+			"insecure": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to skip verifying the SSL certificate used by the API service",
+				MarkdownDescription: "Whether to skip verifying the SSL certificate used by the API service",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Host name to verify the certificate of the API service against, see the provider's 'tls_server_name'. Not inherited from the provider configuration",
+				MarkdownDescription: "Host name to verify the certificate of the API service against, see the provider's `tls_server_name`. Not inherited from the provider configuration",
+			},
+			"tls_fingerprint_sha256": schema.StringAttribute{
+				Optional:            true,
+				Description:         "SHA-256 fingerprint of the certificate of the API service, see the provider's 'tls_fingerprint_sha256'. Not inherited from the provider configuration",
+				MarkdownDescription: "SHA-256 fingerprint of the certificate of the API service, see the provider's `tls_fingerprint_sha256`. Not inherited from the provider configuration",
+			},
+		},
+	},
+}
+
+// clientOpts returns the options of the client for this host, based on those
+// of the provider's default client.
+func (h hostModel) clientOpts(defaults client.ClientOpts, inheritedPort int64) (client.ClientOpts, error) {
+	opts := defaults
+
+	port := inheritedPort
+	if !h.Port.IsNull() && !h.Port.IsUnknown() {
+		port = h.Port.ValueInt64()
+	}
+	hostURL, err := normalizeHostURL(h.HostURL.ValueString(), port)
+	if err != nil {
+		return opts, err
+	}
+	opts.HostURL = hostURL
+	if !h.BasePath.IsNull() {
+		opts.BasePath = h.BasePath.ValueString()
+	}
+
+	// credentials of the default device are never mixed with those of the host
+	if !h.Username.IsNull() || !h.AuthorizationHeader.IsNull() {
+		opts.Username = h.Username.ValueString()
+		opts.Password = h.Password.ValueString()
+		opts.Authorization = h.AuthorizationHeader.ValueString()
+	}
+	if !h.CA.IsNull() {
+		opts.CA = h.CA.ValueString()
+	}
+	if !h.Insecure.IsNull() {
+		opts.Insecure = h.Insecure.ValueBool()
+	}
+	// a server name or certificate of the default device is unlikely to be
+	// valid for any other device
+	opts.ServerName = h.TLSServerName.ValueString()
+	opts.FingerprintSHA256 = h.TLSFingerprint.ValueString()
+	return opts, nil
+}
+
+// providerClients is handed to resources and data sources as provider data.
+// It acts as the client of the default device, and additionally holds the
+// clients of all named hosts.
+type providerClients struct {
+	client.API
+	hosts map[string]client.API
+	// unconfigured is set if the provider configuration is not known yet, in
+	// which case every host is served by the unconfigured default client.
+	unconfigured bool
+}
+
+// newHostClients creates a client for every entry of hosts.
+func newHostClients(ctx context.Context, hosts types.Map, defaults client.ClientOpts, inheritedPort int64) (map[string]client.API, diag.Diagnostics) {
+	var models map[string]hostModel
+	diags := hosts.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	clients := make(map[string]client.API, len(models))
+	for name, h := range models {
+		opts, err := h.clientOpts(defaults, inheritedPort)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("hosts").AtMapKey(name).AtName("hosturl"),
+				"Invalid API Host",
+				fmt.Sprintf("Cannot create API client of host '%s', the host '%s' is invalid: %s", name, h.HostURL.ValueString(), err),
+			)
+			continue
+		}
+		c, err := client.New(opts)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("hosts").AtMapKey(name),
+				"Client configure error",
+				fmt.Sprintf("Error while configuring client of host '%s', got err: %s", name, err),
+			)
+			continue
+		}
+		clients[name] = c
+	}
+	return clients, diags
+}
+
+// hostClient returns the client of the named host, or c itself if host is
+// unset.
+func hostClient(c client.API, host types.String) (client.API, error) {
+	if host.IsNull() || host.IsUnknown() {
+		return c, nil
+	}
+
+	var hosts map[string]client.API
+	if pc, ok := c.(*providerClients); ok {
+		if pc.unconfigured {
+			return c, nil
+		}
+		hosts = pc.hosts
+	}
+	if h, ok := hosts[host.ValueString()]; ok {
+		return h, nil
+	}
+
+	names := make([]string, 0, len(hosts))
+	for name := range hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown host '%s', expected one of the keys of the provider's 'hosts': [%s]", host.ValueString(), strings.Join(names, ", "))
+}
@@ -0,0 +1,69 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// normalizeHostURL turns the configured address of a device into the base URL
+// of its REST API. The address may be a bare host, e.g. `router.lan`, a host
+// and port, e.g. `router.lan:8443`, or a full URL, e.g.
+// `https://router.lan:8443/`. The scheme defaults to https and the port to
+// port, unless either is part of the address. Trailing slashes are stripped so
+// that request paths can be appended as is.
+func normalizeHostURL(address string, port int64) (string, error) {
+	address = strings.TrimSpace(address)
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", fmt.Errorf("unsupported scheme '%s', expected 'https' or 'http'", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("the address does not contain a host")
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("the address must not contain credentials, a query or a fragment")
+	}
+
+	if p := u.Port(); p != "" {
+		if n, err := strconv.ParseUint(p, 10, 16); err != nil || n == 0 {
+			return "", fmt.Errorf("invalid port '%s'", p)
+		}
+	} else {
+		if port < 1 || port > 65535 {
+			return "", fmt.Errorf("invalid port %d", port)
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.FormatInt(port, 10))
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
@@ -0,0 +1,157 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// orderingClaim identifies a single rule on a single device.
+type orderingClaim struct {
+	// all implementations of client.API are pointers, and thus usable as keys
+	client   client.API
+	ruleType string
+	id       string
+}
+
+// nameClaim identifies the name of an ordering on a single device.
+type nameClaim struct {
+	client client.API
+	name   string
+}
+
+// orderingRegistry keeps track of which rule_ordering resource manages which
+// rules during a single Terraform operation. A rule which is part of more than
+// one ordering would otherwise be moved back and forth on every apply. The
+// names of orderings are tracked as well, as they are used as their IDs.
+//
+// Resources are told apart by their owner token, see orderingOwner, and not
+// by their ID or configuration, which two resources may have in common.
+type orderingRegistry struct {
+	mu     sync.Mutex
+	owners map[orderingClaim]string
+	names  map[nameClaim]string
+}
+
+// claimedRules is shared by all rule_ordering resources served by this
+// provider process. The claims of an ordering are released whenever it is
+// planned again or destroyed, see release.
+var claimedRules = &orderingRegistry{owners: map[orderingClaim]string{}, names: map[nameClaim]string{}}
+
+// claim registers owner as the manager of the given rules and returns all IDs
+// which are already claimed by a different owner.
+func (r *orderingRegistry) claim(c client.API, ruleType string, ids []string, owner string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var conflicts []string
+	for _, id := range ids {
+		key := orderingClaim{client: c, ruleType: ruleType, id: id}
+		if current, ok := r.owners[key]; ok && current != owner {
+			conflicts = append(conflicts, id)
+			continue
+		}
+		r.owners[key] = owner
+	}
+	return conflicts
+}
+
+// claimName registers owner as the ordering with the given name and reports
+// whether the name is already taken by a different owner.
+func (r *orderingRegistry) claimName(c client.API, name, owner string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := nameClaim{client: c, name: name}
+	if current, ok := r.names[key]; ok && current != owner {
+		return true
+	}
+	r.names[key] = owner
+	return false
+}
+
+// release drops all rules and names claimed by owner, so that they may be
+// claimed by other orderings again, e.g. once the ordering is destroyed or no
+// longer manages them.
+func (r *orderingRegistry) release(owner string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, current := range r.owners {
+		if current == owner {
+			delete(r.owners, key)
+		}
+	}
+	for key, current := range r.names {
+		if current == owner {
+			delete(r.names, key)
+		}
+	}
+}
+
+// ownerKey is the private state key under which the owner token of an
+// ordering is stored.
+const ownerKey = "owner"
+
+// orderingOwner returns the token which identifies the ordering whose private
+// state is given in the registry. It is generated when the ordering is
+// created, see newOwnerToken. Orderings which are yet to be created, or which
+// were created before tokens were introduced, get a token of their own for
+// the current operation, as every resource is planned only once per provider
+// process.
+func orderingOwner(ctx context.Context, private privateState) (string, diag.Diagnostics) {
+	var owner string
+	if private != nil {
+		b, diags := private.GetKey(ctx, ownerKey)
+		if diags.HasError() {
+			return "", diags
+		}
+		if len(b) > 0 && json.Unmarshal(b, &owner) == nil && owner != "" {
+			return owner, diags
+		}
+	}
+	return newOwnerToken(), nil
+}
+
+// saveOwner stores owner as the owner token of the ordering.
+func saveOwner(ctx context.Context, private privateState, owner string) diag.Diagnostics {
+	b, err := json.Marshal(owner)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Internal Error", fmt.Sprintf("Unable to encode owner of ordering, got error: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, ownerKey, b)
+}
+
+// newOwnerToken returns a random token which is unique to a single ordering.
+func newOwnerToken() string {
+	b := make([]byte, 16)
+	// crypto/rand never fails on the supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
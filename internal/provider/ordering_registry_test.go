@@ -0,0 +1,58 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+func TestOrderingRegistryRelease(t *testing.T) {
+	r := &orderingRegistry{owners: map[orderingClaim]string{}, names: map[nameClaim]string{}}
+	c := client.NewUnconfigured()
+
+	if conflicts := r.claim(c, "filter", []string{"*1", "*2"}, "a"); len(conflicts) != 0 {
+		t.Fatalf("claim() by a returned conflicts %v", conflicts)
+	}
+	if taken := r.claimName(c, "web", "a"); taken {
+		t.Fatalf("claimName() by a reported the name as taken")
+	}
+	r.claim(c, "filter", []string{"*3"}, "b")
+
+	if conflicts := r.claim(c, "filter", []string{"*2", "*3"}, "c"); !reflect.DeepEqual(conflicts, []string{"*2", "*3"}) {
+		t.Errorf("claim() by c returned conflicts %v, want [*2 *3]", conflicts)
+	}
+	if taken := r.claimName(c, "web", "c"); !taken {
+		t.Errorf("claimName() by c did not report the name as taken")
+	}
+
+	r.release("a")
+
+	if conflicts := r.claim(c, "filter", []string{"*1", "*2", "*3"}, "c"); !reflect.DeepEqual(conflicts, []string{"*3"}) {
+		t.Errorf("claim() by c after release returned conflicts %v, want [*3]", conflicts)
+	}
+	if taken := r.claimName(c, "web", "c"); taken {
+		t.Errorf("claimName() by c after release reported the name as taken")
+	}
+	if len(r.owners) != 3 || len(r.names) != 1 {
+		t.Errorf("registry holds %d rules and %d names after release, want 3 and 1", len(r.owners), len(r.names))
+	}
+}
@@ -23,8 +23,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -47,12 +50,54 @@ type RouterosFWFLProvider struct {
 // ScaffoldingProviderModel describes the provider data model.
 type ScaffoldingProviderModel struct {
 	HostURL  types.String `tfsdk:"hosturl"`
+	Port     types.Int64  `tfsdk:"port"`
+	BasePath types.String `tfsdk:"base_path"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
+
+	AuthorizationHeader types.String `tfsdk:"authorization_header"`
+	CredentialsCommand  types.String `tfsdk:"credentials_command"`
+	CredentialsFile     types.String `tfsdk:"credentials_file"`
+
 	CA       types.String `tfsdk:"ca_certificate"`
 	Insecure types.Bool   `tfsdk:"insecure"`
+	Timeout  types.Int64  `tfsdk:"timeout"`
+	Proxy    types.String `tfsdk:"http_proxy"`
+
+	TLSServerName        types.String `tfsdk:"tls_server_name"`
+	TLSFingerprintSHA256 types.String `tfsdk:"tls_fingerprint_sha256"`
+
+	SSHHost       types.String `tfsdk:"ssh_host"`
+	SSHUser       types.String `tfsdk:"ssh_user"`
+	SSHKey        types.String `tfsdk:"ssh_key"`
+	SSHKnownHosts types.String `tfsdk:"ssh_known_hosts"`
+
+	Concurrency    types.Int64 `tfsdk:"concurrency"`
+	SerializeMoves types.Bool  `tfsdk:"serialize_moves"`
+	MaxAPIRate     types.Int64 `tfsdk:"max_api_rate"`
+
+	MaxIdleConnections    types.Int64 `tfsdk:"max_idle_connections"`
+	IdleConnectionTimeout types.Int64 `tfsdk:"idle_connection_timeout"`
+
+	ValidateConnection types.Bool `tfsdk:"validate_connection"`
+	SkipReadOnError    types.Bool `tfsdk:"skip_read_on_error"`
+
+	ReportOrderingDrift types.Bool `tfsdk:"report_ordering_drift"`
+	IdentityInErrors    types.Bool `tfsdk:"identity_in_errors"`
+
+	Workspace            types.String `tfsdk:"workspace"`
+	AllowCrossWorkspace  types.Bool   `tfsdk:"allow_cross_workspace"`
+	AllowDefconfMoves    types.Bool   `tfsdk:"allow_defconf_moves"`
+	ManagedCommentPrefix types.String `tfsdk:"managed_comment_prefix"`
+
+	Hosts types.Map `tfsdk:"hosts"`
 }
 
+const (
+	defaultPort    = 443
+	defaultTimeout = 30
+)
+
 func (p *RouterosFWFLProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "routeros-firewall-list"
 	resp.Version = p.version
@@ -60,34 +105,166 @@ func (p *RouterosFWFLProvider) Metadata(ctx context.Context, req provider.Metada
 
 func (p *RouterosFWFLProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Description:         "A provider for declaratively managing firewall lists on RouterOS devices",
-		MarkdownDescription: "A provider for declaratively  managing firewall lists on RouterOS devices.",
+		Description:         "A provider for declaratively managing firewall lists on RouterOS devices. Every attribute can alternatively be set via its environment variable. Values set in the configuration take precedence over environment variables, which in turn take precedence over 'credentials_file' and defaults. Only the output of 'credentials_command' overrides configured credentials",
+		MarkdownDescription: "A provider for declaratively  managing firewall lists on RouterOS devices.\n\nEvery attribute can alternatively be set via its environment variable. Values set in the configuration take precedence over environment variables, which in turn take precedence over `credentials_file` and defaults. Only the output of `credentials_command` overrides configured credentials.\n\nIf the connection settings depend on values which are only known after apply, e.g. the address of a device created in the same configuration, the device is not contacted during plan. Resources are then planned from the configuration alone and the state of existing resources is not refreshed until the settings are known.",
 		Attributes: map[string]schema.Attribute{
 			"hosturl": schema.StringAttribute{
 				Optional:            true,
-				Description:         "Address of the host device. Do not specify the protocol or port, these are hard-coded to 'https' and '443' respectively",
-				MarkdownDescription: "Address of the host device. Do not specify the protocol or port, these are hard-coded to 'https' and '443' respectively",
+				Description:         "Address of the host device, either as a host, e.g. 'router.lan', a host and port, e.g. 'router.lan:8443', or a full URL, e.g. 'https://router.lan:8443'. The protocol defaults to 'https' and the port to 'port' unless they are part of the address. Environment variable: ROS_HOSTURL",
+				MarkdownDescription: "Address of the host device, either as a host, e.g. `router.lan`, a host and port, e.g. `router.lan:8443`, or a full URL, e.g. `https://router.lan:8443`. The protocol defaults to `https` and the port to `port` unless they are part of the address. Environment variable: `ROS_HOSTURL`",
+			},
+			"port": schema.Int64Attribute{
+				Optional:            true,
+				Description:         fmt.Sprintf("Port of the REST API service. Environment variable: ROS_PORT. Defaults to %d", defaultPort),
+				MarkdownDescription: fmt.Sprintf("Port of the REST API service. Environment variable: `ROS_PORT`. Defaults to `%d`", defaultPort),
+			},
+			"base_path": schema.StringAttribute{
+				Optional:            true,
+				Description:         fmt.Sprintf("Path the REST API is served at, e.g. '/mikrotik/rest' if the device is reached through a reverse proxy. Environment variable: ROS_BASE_PATH. Defaults to '%s'", client.DefaultBasePath),
+				MarkdownDescription: fmt.Sprintf("Path the REST API is served at, e.g. `/mikrotik/rest` if the device is reached through a reverse proxy. Environment variable: `ROS_BASE_PATH`. Defaults to `%s`", client.DefaultBasePath),
 			},
 			"username": schema.StringAttribute{
 				Optional:            true,
-				Description:         "Username to use for API authentication",
-				MarkdownDescription: "Username to use for API authentication",
+				Description:         "Username to use for API authentication. Environment variable: ROS_USERNAME",
+				MarkdownDescription: "Username to use for API authentication. Environment variable: `ROS_USERNAME`",
 			},
 			"password": schema.StringAttribute{
 				Optional:            true,
 				Sensitive:           true,
-				Description:         "Password to use for API authentication",
-				MarkdownDescription: "Password to use for API authentication",
+				Description:         "Password to use for API authentication. Environment variable: ROS_PASSWORD",
+				MarkdownDescription: "Password to use for API authentication. Environment variable: `ROS_PASSWORD`",
+			},
+			"authorization_header": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				Description:         "Value of the Authorization header sent with every API request, e.g. 'Bearer <token>' for a proxy which authenticates against the device on behalf of the provider. Takes precedence over 'username' and 'password'. Environment variable: ROS_AUTHORIZATION_HEADER",
+				MarkdownDescription: "Value of the `Authorization` header sent with every API request, e.g. `Bearer <token>` for a proxy which authenticates against the device on behalf of the provider. Takes precedence over `username` and `password`. Environment variable: `ROS_AUTHORIZATION_HEADER`",
+			},
+			"credentials_command": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Shell command which is executed while configuring the provider and prints the credentials as a JSON object on stdout, either of the form {\"username\": \"...\", \"password\": \"...\"} or {\"authorization\": \"...\"}. Printed values take precedence over 'username', 'password' and 'authorization_header', as well as 'credentials_file'. Environment variable: ROS_CREDENTIALS_COMMAND",
+				MarkdownDescription: "Shell command which is executed while configuring the provider and prints the credentials as a JSON object on stdout, either of the form `{\"username\": \"...\", \"password\": \"...\"}` or `{\"authorization\": \"...\"}`. Printed values take precedence over `username`, `password` and `authorization_header`, as well as `credentials_file`. Environment variable: `ROS_CREDENTIALS_COMMAND`",
+			},
+			"credentials_file": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Path to a JSON or YAML file containing any of the keys 'hosturl', 'username', 'password' and 'authorization', e.g. a mounted Kubernetes or Vault secret. The file is read whenever the provider is configured. Values in the file are only used for attributes which are neither configured nor set via their environment variable, and are overridden by the output of 'credentials_command'. The 'authorization' of the file is ignored if 'username' or 'password' is set. Environment variable: ROS_CREDENTIALS_FILE",
+				MarkdownDescription: "Path to a JSON or YAML file containing any of the keys `hosturl`, `username`, `password` and `authorization`, e.g. a mounted Kubernetes or Vault secret. The file is read whenever the provider is configured. Values in the file are only used for attributes which are neither configured nor set via their environment variable, and are overridden by the output of `credentials_command`. The `authorization` of the file is ignored if `username` or `password` is set. Environment variable: `ROS_CREDENTIALS_FILE`",
 			},
 			"ca_certificate": schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "Path to the CA root certificate",
-				Description:         "Path to the CA root certificate",
+				MarkdownDescription: "Path to the CA root certificate. Optional if `tls_fingerprint_sha256` is set. Environment variable: `ROS_CA_CERTIFICATE`",
+				Description:         "Path to the CA root certificate. Optional if 'tls_fingerprint_sha256' is set. Environment variable: ROS_CA_CERTIFICATE",
 			},
 			"insecure": schema.BoolAttribute{
 				Optional:            true,
-				Description:         "Whether to skip verifying the SSL certificate used by the API service",
-				MarkdownDescription: "Whether to skip verifying the SSL certificate used by the API service",
+				Description:         "Whether to skip verifying the SSL certificate used by the API service. Environment variable: ROS_INSECURE. Defaults to false",
+				MarkdownDescription: "Whether to skip verifying the SSL certificate used by the API service. Environment variable: `ROS_INSECURE`. Defaults to `false`",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Host name to verify the certificate of the API service against instead of the host of 'hosturl', e.g. if the device is reached by IP address but its certificate is issued for a DNS name. Environment variable: ROS_TLS_SERVER_NAME",
+				MarkdownDescription: "Host name to verify the certificate of the API service against instead of the host of `hosturl`, e.g. if the device is reached by IP address but its certificate is issued for a DNS name. Environment variable: `ROS_TLS_SERVER_NAME`",
+			},
+			"tls_fingerprint_sha256": schema.StringAttribute{
+				Optional:            true,
+				Description:         "SHA-256 fingerprint of the certificate of the API service, as hex optionally separated by colons. If set, exactly this certificate is accepted regardless of its issuer and host name, e.g. to pin a self-signed certificate. Environment variable: ROS_TLS_FINGERPRINT_SHA256",
+				MarkdownDescription: "SHA-256 fingerprint of the certificate of the API service, as hex optionally separated by colons. If set, exactly this certificate is accepted regardless of its issuer and host name, e.g. to pin a self-signed certificate. The fingerprint can be obtained with `openssl x509 -noout -fingerprint -sha256`. Environment variable: `ROS_TLS_FINGERPRINT_SHA256`",
+			},
+			"timeout": schema.Int64Attribute{
+				Optional:            true,
+				Description:         fmt.Sprintf("Timeout of a single API request in seconds. Environment variable: ROS_TIMEOUT. Defaults to %d", defaultTimeout),
+				MarkdownDescription: fmt.Sprintf("Timeout of a single API request in seconds. Environment variable: `ROS_TIMEOUT`. Defaults to `%d`", defaultTimeout),
+			},
+			"http_proxy": schema.StringAttribute{
+				Optional:            true,
+				Description:         "URL of a proxy to send all API requests through, e.g. 'http://proxy.example.com:3128'. Environment variable: ROS_HTTP_PROXY. Defaults to the proxy configured via the HTTPS_PROXY and NO_PROXY environment variables, if any",
+				MarkdownDescription: "URL of a proxy to send all API requests through, e.g. `http://proxy.example.com:3128`. Environment variable: `ROS_HTTP_PROXY`. Defaults to the proxy configured via the `HTTPS_PROXY` and `NO_PROXY` environment variables, if any",
+			},
+			"ssh_host": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Address of an SSH server, optionally including the port, through which all API requests are tunneled. The REST API is then reached at 'hosturl' as seen from the SSH server, e.g. the device itself. Environment variable: ROS_SSH_HOST",
+				MarkdownDescription: "Address of an SSH server, optionally including the port, through which all API requests are tunneled. The REST API is then reached at `hosturl` as seen from the SSH server, e.g. the device itself. Environment variable: `ROS_SSH_HOST`",
+			},
+			"ssh_user": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Username to use for SSH authentication. Environment variable: ROS_SSH_USER. Defaults to 'username'",
+				MarkdownDescription: "Username to use for SSH authentication. Environment variable: `ROS_SSH_USER`. Defaults to `username`",
+			},
+			"ssh_key": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Path to the unencrypted private key to use for SSH authentication. Required if 'ssh_host' is set. Environment variable: ROS_SSH_KEY",
+				MarkdownDescription: "Path to the unencrypted private key to use for SSH authentication. Required if `ssh_host` is set. Environment variable: `ROS_SSH_KEY`",
+			},
+			"ssh_known_hosts": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Path to the known_hosts file which the host key of the SSH server is verified against. Environment variable: ROS_SSH_KNOWN_HOSTS. Defaults to '~/.ssh/known_hosts'",
+				MarkdownDescription: "Path to the `known_hosts` file which the host key of the SSH server is verified against. Environment variable: `ROS_SSH_KNOWN_HOSTS`. Defaults to `~/.ssh/known_hosts`",
+			},
+			"concurrency": schema.Int64Attribute{
+				Optional:            true,
+				Description:         fmt.Sprintf("Maximum number of parallel requests sent for batched operations, such as creating address list entries. Environment variable: ROS_CONCURRENCY. Defaults to %d", client.DefaultConcurrency),
+				MarkdownDescription: fmt.Sprintf("Maximum number of parallel requests sent for batched operations, such as creating address list entries. Environment variable: `ROS_CONCURRENCY`. Defaults to `%d`", client.DefaultConcurrency),
+			},
+			"max_api_rate": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Maximum number of API requests sent per second. Requests which the device rejects as overloaded are retried with a backoff regardless. Environment variable: ROS_MAX_API_RATE. Defaults to 0, which means no limit",
+				MarkdownDescription: "Maximum number of API requests sent per second. Requests which the device rejects as overloaded are retried with a backoff regardless. Environment variable: `ROS_MAX_API_RATE`. Defaults to `0`, which means no limit",
+			},
+			"max_idle_connections": schema.Int64Attribute{
+				Optional:            true,
+				Description:         "Maximum number of idle connections to the device which are kept open, so that subsequent requests can reuse them instead of each performing a TLS handshake. 0 disables reusing connections. Environment variable: ROS_MAX_IDLE_CONNECTIONS. Defaults to the value of 'concurrency'",
+				MarkdownDescription: "Maximum number of idle connections to the device which are kept open, so that subsequent requests can reuse them instead of each performing a TLS handshake. `0` disables reusing connections. Environment variable: `ROS_MAX_IDLE_CONNECTIONS`. Defaults to the value of `concurrency`",
+			},
+			"idle_connection_timeout": schema.Int64Attribute{
+				Optional:            true,
+				Description:         fmt.Sprintf("Time in seconds after which idle connections to the device are closed. Environment variable: ROS_IDLE_CONNECTION_TIMEOUT. Defaults to %d", int(client.DefaultIdleConnTimeout.Seconds())),
+				MarkdownDescription: fmt.Sprintf("Time in seconds after which idle connections to the device are closed. Environment variable: `ROS_IDLE_CONNECTION_TIMEOUT`. Defaults to `%d`", int(client.DefaultIdleConnTimeout.Seconds())),
+			},
+			"validate_connection": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to contact the device while configuring the provider, failing early if it cannot be reached, authentication fails, or the group of the user lacks the read, write and rest-api policies (api before RouterOS introduced rest-api). Environment variable: ROS_VALIDATE_CONNECTION. Defaults to false",
+				MarkdownDescription: "Whether to contact the device while configuring the provider, failing early if it cannot be reached, authentication fails, or the group of the user lacks the `read`, `write` and `rest-api` policies (`api` before RouterOS introduced `rest-api`). Environment variable: `ROS_VALIDATE_CONNECTION`. Defaults to `false`",
+			},
+			"skip_read_on_error": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to keep the prior state of resources instead of failing if the device cannot be reached while refreshing. Plans against an offline device then report no drift, which keeps them from blocking unrelated changes. Data sources still fail. Environment variable: ROS_SKIP_READ_ON_ERROR. Defaults to false",
+				MarkdownDescription: "Whether to keep the prior state of resources instead of failing if the device cannot be reached while refreshing. Plans against an offline device then report no drift, which keeps them from blocking unrelated changes. Data sources still fail. Environment variable: `ROS_SKIP_READ_ON_ERROR`. Defaults to `false`",
+			},
+			"report_ordering_drift": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether rule orderings report drift with a summary of the rules which are out of place and, if the system log has a record of it, the users who moved them, which helps to trace out-of-band changes. The summary is reported as a warning while refreshing and logged again when the next apply restores the configured ordering. Refreshing never changes the device. Environment variable: ROS_REPORT_ORDERING_DRIFT. Defaults to false",
+				MarkdownDescription: "Whether rule orderings report drift with a summary of the rules which are out of place and, if the system log has a record of it, the users who moved them, which helps to trace out-of-band changes. The summary is reported as a warning while refreshing and logged again when the next apply restores the configured ordering. Refreshing never changes the device. Environment variable: `ROS_REPORT_ORDERING_DRIFT`. Defaults to `false`",
+			},
+			"identity_in_errors": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to fetch the identity of every device while configuring the provider, and to prefix all errors returned by a device with its identity, so that the device an error came from can be told apart when managing multiple routers. Environment variable: ROS_IDENTITY_IN_ERRORS. Defaults to false",
+				MarkdownDescription: "Whether to fetch the identity of every device while configuring the provider, and to prefix all errors returned by a device with its identity, so that the device an error came from can be told apart when managing multiple routers. Environment variable: `ROS_IDENTITY_IN_ERRORS`. Defaults to `false`",
+			},
+			"workspace": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Workspace identity which is attached to the comment of every object created by this provider. Environment variable: ROS_WORKSPACE. Defaults to the value of TF_WORKSPACE, or 'default' if unset",
+				MarkdownDescription: "Workspace identity which is attached to the comment of every object created by this provider. Environment variable: `ROS_WORKSPACE`. Defaults to the value of `TF_WORKSPACE`, or `default` if unset",
+			},
+			"serialize_moves": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to execute move operations on the same rule table one at a time, so that orderings which are applied in parallel do not interleave their moves. Environment variable: ROS_SERIALIZE_MOVES. Defaults to true",
+				MarkdownDescription: "Whether to execute move operations on the same rule table one at a time, so that orderings which are applied in parallel do not interleave their moves. Environment variable: `ROS_SERIALIZE_MOVES`. Defaults to `true`",
+			},
+			"hosts": hostsAttribute,
+			"managed_comment_prefix": schema.StringAttribute{
+				Optional:            true,
+				Description:         "Prefix which is prepended to the comment of every object created by this provider, e.g. 'tf:', so that managed objects can be told apart on the device. The prefix is not part of the comments in the state. Environment variable: ROS_MANAGED_COMMENT_PREFIX",
+				MarkdownDescription: "Prefix which is prepended to the comment of every object created by this provider, e.g. `tf:`, so that managed objects can be told apart on the device. The prefix is not part of the comments in the state. Environment variable: `ROS_MANAGED_COMMENT_PREFIX`",
+			},
+			"allow_cross_workspace": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to allow modifying and deleting objects which were created from a different workspace. Environment variable: ROS_ALLOW_CROSS_WORKSPACE. Defaults to false",
+				MarkdownDescription: "Whether to allow modifying and deleting objects which were created from a different workspace. Environment variable: `ROS_ALLOW_CROSS_WORKSPACE`. Defaults to `false`",
+			},
+			"allow_defconf_moves": schema.BoolAttribute{
+				Optional:            true,
+				Description:         "Whether to allow moving rules of the RouterOS default configuration, i.e. rules whose comment starts with 'defconf:', and moving other rules in front of them. Such moves are refused by default, as a wrong ordering can push e.g. the rule dropping everything from the WAN out of effect. Environment variable: ROS_ALLOW_DEFCONF_MOVES. Defaults to false",
+				MarkdownDescription: "Whether to allow moving rules of the RouterOS default configuration, i.e. rules whose comment starts with `defconf:`, and moving other rules in front of them. Such moves are refused by default, as a wrong ordering can push e.g. the rule dropping everything from the WAN out of effect. Environment variable: `ROS_ALLOW_DEFCONF_MOVES`. Defaults to `false`",
 			},
 		},
 	}
@@ -102,77 +279,267 @@ func (p *RouterosFWFLProvider) Configure(ctx context.Context, req provider.Confi
 		return
 	}
 
-	opts.HostURL = os.Getenv("ROS_HOSTURL")
-	if !config.HostURL.IsNull() {
-		opts.HostURL = config.HostURL.ValueString()
-		if opts.HostURL == "" {
+	// Terraform configures the provider during plan even if its configuration
+	// depends on resources which have not been created yet. Resources are then
+	// planned without contacting the device, see client.NewUnconfigured.
+	if config.connectionUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Provider Configuration Unknown",
+			"The connection settings of the provider depend on values which are only known after apply, so the device cannot be contacted during this plan. "+
+				"The state of existing resources is not refreshed and is assumed to be unchanged until the configuration is known.",
+		)
+		data := &providerClients{API: client.NewUnconfigured(), unconfigured: true}
+		resp.DataSourceData = data
+		resp.ResourceData = data
+		return
+	}
+
+	host := stringSetting(config.HostURL, "ROS_HOSTURL", "")
+	opts.Username = stringSetting(config.Username, "ROS_USERNAME", "")
+	opts.Password = stringSetting(config.Password, "ROS_PASSWORD", "")
+	opts.Authorization = stringSetting(config.AuthorizationHeader, "ROS_AUTHORIZATION_HEADER", "")
+
+	if file := stringSetting(config.CredentialsFile, "ROS_CREDENTIALS_FILE", ""); file != "" {
+		creds, err := readCredentialsFile(file)
+		if err != nil {
 			resp.Diagnostics.AddAttributeError(
-				path.Root("host"),
-				"Unknown API Host",
-				"Cannot create API client, no host value provided",
+				path.Root("credentials_file"),
+				"Unable To Read Credentials",
+				err.Error(),
 			)
 		}
+		creds.fill(&host, &opts)
 	}
-	// TODO: parse value as URL and check if proto / port are already set
-	opts.HostURL = fmt.Sprintf("https://%s:443", opts.HostURL)
 
-	opts.Username = os.Getenv("ROS_USERNAME")
-	if !config.Username.IsNull() {
-		opts.Username = config.Username.ValueString()
-		if opts.Username == "" {
+	if command := stringSetting(config.CredentialsCommand, "ROS_CREDENTIALS_COMMAND", ""); command != "" {
+		creds, err := runCredentialsCommand(ctx, command)
+		if err != nil {
 			resp.Diagnostics.AddAttributeError(
-				path.Root("username"),
-				"Unknown API Username",
-				"Cannot create API client, no username value provided",
+				path.Root("credentials_command"),
+				"Unable To Obtain Credentials",
+				err.Error(),
 			)
 		}
+		creds.apply(&host, &opts)
 	}
 
-	opts.Password = os.Getenv("ROS_PASSWORD")
-	if !config.Password.IsNull() {
-		opts.Password = config.Password.ValueString()
+	if host == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("hosturl"),
+			"Unknown API Host",
+			"Cannot create API client, no host value provided",
+		)
+	}
+	port := int64Setting(config.Port, "ROS_PORT", defaultPort, path.Root("port"), &resp.Diagnostics)
+	if host != "" {
+		hostURL, err := normalizeHostURL(host, port)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hosturl"),
+				"Invalid API Host",
+				fmt.Sprintf("Cannot create API client, the host '%s' is invalid: %s", host, err),
+			)
+		}
+		opts.HostURL = hostURL
 	}
 
-	opts.CA = os.Getenv("ROS_CA_CERTIFICATE")
-	if !config.CA.IsNull() {
-		opts.CA = config.CA.ValueString()
+	if opts.Username == "" && opts.Authorization == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("username"),
+			"Unknown API Username",
+			"Cannot create API client, no username value or authorization header provided",
+		)
 	}
 
-	if v := os.Getenv("ROS_INSECURE"); v != "" && config.Insecure.IsNull() {
-		var err error
-		opts.Insecure, err = strconv.ParseBool(v)
-		if err != nil {
-			resp.Diagnostics.AddAttributeWarning(path.Root("insecure"),
-				"Invalid value for parameter `insecure`",
-				fmt.Sprintf("Could not parse provided value '%s' for parameter 'insecure' as a boolean", v),
+	opts.BasePath = stringSetting(config.BasePath, "ROS_BASE_PATH", "")
+	opts.CA = stringSetting(config.CA, "ROS_CA_CERTIFICATE", "")
+	opts.Insecure = boolSetting(config.Insecure, "ROS_INSECURE", false, path.Root("insecure"), &resp.Diagnostics)
+	opts.ServerName = stringSetting(config.TLSServerName, "ROS_TLS_SERVER_NAME", "")
+	opts.FingerprintSHA256 = stringSetting(config.TLSFingerprintSHA256, "ROS_TLS_FINGERPRINT_SHA256", "")
+
+	timeout := int64Setting(config.Timeout, "ROS_TIMEOUT", defaultTimeout, path.Root("timeout"), &resp.Diagnostics)
+	opts.Timeout = time.Duration(timeout) * time.Second
+
+	opts.Proxy = stringSetting(config.Proxy, "ROS_HTTP_PROXY", "")
+
+	if sshHost := stringSetting(config.SSHHost, "ROS_SSH_HOST", ""); sshHost != "" {
+		opts.SSH = &client.SSHTunnelOpts{
+			Host:       sshHost,
+			User:       stringSetting(config.SSHUser, "ROS_SSH_USER", opts.Username),
+			Key:        stringSetting(config.SSHKey, "ROS_SSH_KEY", ""),
+			KnownHosts: stringSetting(config.SSHKnownHosts, "ROS_SSH_KNOWN_HOSTS", ""),
+		}
+		if opts.SSH.Key == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ssh_key"),
+				"Unknown SSH Key",
+				"Cannot create SSH tunnel, no private key provided",
 			)
 		}
-	} else {
-		opts.Insecure = config.Insecure.ValueBool()
 	}
 
+	opts.Concurrency = int(int64Setting(config.Concurrency, "ROS_CONCURRENCY", client.DefaultConcurrency, path.Root("concurrency"), &resp.Diagnostics))
+	opts.MaxRate = int(int64Setting(config.MaxAPIRate, "ROS_MAX_API_RATE", 0, path.Root("max_api_rate"), &resp.Diagnostics))
+	if opts.MaxRate < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_api_rate"),
+			"Invalid API Rate",
+			fmt.Sprintf("The maximum API rate must not be negative, got %d", opts.MaxRate),
+		)
+	}
+	maxIdle := int64Setting(config.MaxIdleConnections, "ROS_MAX_IDLE_CONNECTIONS", int64(opts.Concurrency), path.Root("max_idle_connections"), &resp.Diagnostics)
+	idleTimeout := int64Setting(config.IdleConnectionTimeout, "ROS_IDLE_CONNECTION_TIMEOUT", int64(client.DefaultIdleConnTimeout.Seconds()), path.Root("idle_connection_timeout"), &resp.Diagnostics)
+	if maxIdle < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_idle_connections"),
+			"Invalid Idle Connections",
+			fmt.Sprintf("The maximum number of idle connections must not be negative, got %d", maxIdle),
+		)
+	}
+	if idleTimeout <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("idle_connection_timeout"),
+			"Invalid Idle Connection Timeout",
+			fmt.Sprintf("The idle connection timeout must be positive, got %d", idleTimeout),
+		)
+	}
+	opts.MaxIdleConns = int(maxIdle)
+	opts.DisableKeepAlives = maxIdle == 0
+	opts.IdleConnTimeout = time.Duration(idleTimeout) * time.Second
+	opts.DisableMoveLock = !boolSetting(config.SerializeMoves, "ROS_SERIALIZE_MOVES", true, path.Root("serialize_moves"), &resp.Diagnostics)
+
+	workspace := os.Getenv("TF_WORKSPACE")
+	if workspace == "" {
+		workspace = "default"
+	}
+	opts.Workspace = stringSetting(config.Workspace, "ROS_WORKSPACE", workspace)
+	opts.CommentPrefix = stringSetting(config.ManagedCommentPrefix, "ROS_MANAGED_COMMENT_PREFIX", "")
+	opts.AllowCrossWorkspace = boolSetting(config.AllowCrossWorkspace, "ROS_ALLOW_CROSS_WORKSPACE", false, path.Root("allow_cross_workspace"), &resp.Diagnostics)
+	opts.AllowDefconfMoves = boolSetting(config.AllowDefconfMoves, "ROS_ALLOW_DEFCONF_MOVES", false, path.Root("allow_defconf_moves"), &resp.Diagnostics)
+
+	opts.RecordPath = os.Getenv(client.RecordEnv)
+
+	opts.SkipReadOnError = boolSetting(config.SkipReadOnError, "ROS_SKIP_READ_ON_ERROR", false, path.Root("skip_read_on_error"), &resp.Diagnostics)
+	opts.ReportOrderingDrift = boolSetting(config.ReportOrderingDrift, "ROS_REPORT_ORDERING_DRIFT", false, path.Root("report_ordering_drift"), &resp.Diagnostics)
+	opts.IdentityInErrors = boolSetting(config.IdentityInErrors, "ROS_IDENTITY_IN_ERRORS", false, path.Root("identity_in_errors"), &resp.Diagnostics)
+
+	validate := boolSetting(config.ValidateConnection, "ROS_VALIDATE_CONNECTION", false, path.Root("validate_connection"), &resp.Diagnostics)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	client, err := client.New(opts)
+	c, err := client.New(opts)
 	if err != nil {
 		resp.Diagnostics.AddError("Client configure error", fmt.Sprintf("Error while configuring client, got err: %s", err))
 		return
 	}
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	hosts, diags := newHostClients(ctx, config.Hosts, opts, port)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if validate {
+		if _, err := c.Version(ctx); err != nil {
+			resp.Diagnostics.AddError(describeConnectionError(err))
+			return
+		}
+		if err := c.CheckPolicies(ctx); err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to verify the policies of the configured user"))
+			return
+		}
+		resp.Diagnostics.Append(versionDiagnostics(ctx, c)...)
+
+		for name, h := range hosts {
+			if _, err := h.Version(ctx); err != nil {
+				summary, detail := describeConnectionError(err)
+				resp.Diagnostics.AddAttributeError(path.Root("hosts").AtMapKey(name), summary, detail)
+				return
+			}
+			if err := h.CheckPolicies(ctx); err != nil {
+				summary, detail := clientError(err, "Unable to verify the policies of the configured user")
+				resp.Diagnostics.AddAttributeError(path.Root("hosts").AtMapKey(name), summary, detail)
+				return
+			}
+			resp.Diagnostics.Append(versionDiagnostics(ctx, h)...)
+		}
+	}
+
+	// A device whose identity cannot be determined is still usable, its
+	// errors merely lack the identity.
+	if opts.IdentityInErrors {
+		if err := c.LoadIdentity(ctx); err != nil {
+			resp.Diagnostics.AddWarning("Unable To Determine Router Identity", fmt.Sprintf("Errors of the device will not include its identity, got error: %s", err))
+		}
+		for name, h := range hosts {
+			if err := h.LoadIdentity(ctx); err != nil {
+				resp.Diagnostics.AddAttributeWarning(path.Root("hosts").AtMapKey(name), "Unable To Determine Router Identity", fmt.Sprintf("Errors of the device will not include its identity, got error: %s", err))
+			}
+		}
+	}
+
+	data := &providerClients{API: c, hosts: hosts}
+	resp.DataSourceData = data
+	resp.ResourceData = data
+}
+
+// connectionUnknown reports whether any setting which determines how the
+// device is reached is unknown.
+func (m ScaffoldingProviderModel) connectionUnknown() bool {
+	for _, v := range []attr.Value{
+		m.HostURL, m.Port, m.BasePath, m.Username, m.Password,
+		m.AuthorizationHeader, m.CredentialsCommand, m.CredentialsFile,
+		m.CA, m.Insecure, m.Proxy, m.TLSServerName, m.TLSFingerprintSHA256,
+		m.SSHHost, m.SSHUser, m.SSHKey, m.SSHKnownHosts,
+		m.Hosts,
+	} {
+		if v.IsUnknown() {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *RouterosFWFLProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewFirewallRuleOrderingResource,
+		NewInterfaceListResource,
+		NewInterfaceListMemberResource,
+		NewMangleRuleResource,
+		NewRawRuleResource,
+		NewAddressListBulkResource,
+		NewIPv6AddressListBulkResource,
+		NewLayer7ProtocolResource,
+		NewServicePortResource,
+		NewChainResource,
+		NewRuleBlockResource,
+		NewHairpinNATResource,
+		NewPortForwardResource,
+		NewLogRuleResource,
+		NewFirewallLayoutResource,
+		NewRuleStateResource,
+		NewRuleCommentResource,
 	}
 }
 
 func (p *RouterosFWFLProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewAddressListDataSource,
+		NewIPv6AddressListDataSource,
+		NewFirewallRuleDataSource,
+		NewConnectionsDataSource,
+		NewChainsDataSource,
+		NewTableSnapshotDataSource,
+		NewRuleCountersDataSource,
+		NewInterfacesDataSource,
+		NewAPIStatusDataSource,
+		NewRangeToCIDRsDataSource,
+		NewNormalizeCIDRsDataSource,
+		NewRuleRefDataSource,
+		NewRuleRefChainDataSource,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -182,3 +549,54 @@ func New(version string) func() provider.Provider {
 		}
 	}
 }
+
+// stringSetting resolves a provider setting in the order config > env >
+// default.
+func stringSetting(v types.String, env, def string) string {
+	if !v.IsNull() && !v.IsUnknown() {
+		return v.ValueString()
+	}
+	if e := os.Getenv(env); e != "" {
+		return e
+	}
+	return def
+}
+
+// boolSetting resolves a provider setting in the order config > env > default.
+// Unparsable environment values are reported as a warning and ignored.
+func boolSetting(v types.Bool, env string, def bool, p path.Path, diags *diag.Diagnostics) bool {
+	if !v.IsNull() && !v.IsUnknown() {
+		return v.ValueBool()
+	}
+	if e := os.Getenv(env); e != "" {
+		b, err := strconv.ParseBool(e)
+		if err == nil {
+			return b
+		}
+		diags.AddAttributeWarning(p,
+			fmt.Sprintf("Invalid value for environment variable `%s`", env),
+			fmt.Sprintf("Could not parse provided value '%s' for parameter '%s' as a boolean", e, p),
+		)
+	}
+	return def
+}
+
+// int64Setting resolves a provider setting in the order config > env >
+// default. Unparsable environment values are reported as a warning and
+// ignored.
+func int64Setting(v types.Int64, env string, def int64, p path.Path, diags *diag.Diagnostics) int64 {
+	if !v.IsNull() && !v.IsUnknown() {
+		return v.ValueInt64()
+	}
+	if e := os.Getenv(env); e != "" {
+		i, err := strconv.ParseInt(e, 10, 64)
+		if err == nil {
+			return i
+		}
+		diags.AddAttributeWarning(p,
+			fmt.Sprintf("Invalid value for environment variable `%s`", env),
+			fmt.Sprintf("Could not parse provided value '%s' for parameter '%s' as an integer", e, p),
+		)
+	}
+	return def
+}
@@ -0,0 +1,52 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/rostest"
+)
+
+// testAccProtoV6ProviderFactories are used to instantiate a provider during
+// acceptance testing. The factory function will be invoked for every Terraform
+// CLI command executed to create a provider server to which the CLI can
+// reattach.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"routeros-firewall-list": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// newTestServer starts a fake device which is closed at the end of the test,
+// and points the provider at it through the environment.
+func newTestServer(t *testing.T) *rostest.Server {
+	t.Helper()
+
+	server, err := rostest.NewServer()
+	if err != nil {
+		t.Fatalf("unable to start fake device: %s", err)
+	}
+	t.Cleanup(server.Close)
+
+	for k, v := range server.Env() {
+		t.Setenv(k, v)
+	}
+	return server
+}
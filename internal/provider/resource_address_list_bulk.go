@@ -0,0 +1,442 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AddressListBulkResource{}
+var _ resource.ResourceWithModifyPlan = &AddressListBulkResource{}
+
+func NewAddressListBulkResource() resource.Resource {
+	return &AddressListBulkResource{ipVersion: client.IPv4}
+}
+
+func NewIPv6AddressListBulkResource() resource.Resource {
+	return &AddressListBulkResource{ipVersion: client.IPv6}
+}
+
+// AddressListBulkResource defines the resource implementation. It manages
+// the IPv4 or IPv6 address lists depending on ipVersion, which share the
+// same schema.
+type AddressListBulkResource struct {
+	client    client.API
+	ipVersion client.IPVersion
+}
+
+// AddressListBulkResourceModel describes the resource data model.
+type AddressListBulkResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	List      types.String `tfsdk:"list"`
+	Addresses types.Set    `tfsdk:"addresses"`
+	Comment   types.String `tfsdk:"comment"`
+	EntryIDs  types.Map    `tfsdk:"entry_ids"`
+
+	Timeout        DurationValue `tfsdk:"timeout"`
+	RefreshTimeout types.Bool    `tfsdk:"refresh_timeout"`
+	ExpiresAt      types.String  `tfsdk:"expires_at"`
+}
+
+func (r *AddressListBulkResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + addressListTypeName(r.ipVersion) + "_bulk"
+}
+
+func (r *AddressListBulkResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *AddressListBulkResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: fmt.Sprintf("Large sets of static address list entries (`%s`), e.g. from threat intelligence feeds. Entries are updated and removed with a single request on RouterOS 7.16 and newer. RouterOS has no command for adding several entries at once, so new entries are added with parallel requests, see the provider's `concurrency` option", client.AddressListMenu(r.ipVersion)),
+		Description:         fmt.Sprintf("Large sets of static address list entries (%s), e.g. from threat intelligence feeds. Entries are updated and removed with a single request on RouterOS 7.16 and newer. RouterOS has no command for adding several entries at once, so new entries are added with parallel requests, see the provider's 'concurrency' option", client.AddressListMenu(r.ipVersion)),
+		Attributes: map[string]schema.Attribute{
+			"list": schema.StringAttribute{
+				MarkdownDescription: "Name of the address list",
+				Description:         "Name of the address list",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"addresses": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: fmt.Sprintf("%s addresses, ranges or subnets, or DNS names to add to the list", addressFamilyName(r.ipVersion)),
+				Description:         fmt.Sprintf("%s addresses, ranges or subnets, or DNS names to add to the list", addressFamilyName(r.ipVersion)),
+				Required:            true,
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(addressFamilyValidator{version: r.ipVersion}),
+				},
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to every entry",
+				Description:         "Comment attached to every entry",
+				Optional:            true,
+			},
+			"timeout": schema.StringAttribute{
+				CustomType:          DurationType{},
+				MarkdownDescription: "Duration after which RouterOS removes the entries again, e.g. `24h` or `1d`. Entries with a timeout are dynamic and do not survive a reboot",
+				Description:         "Duration after which RouterOS removes the entries again, e.g. '24h' or '1d'. Entries with a timeout are dynamic and do not survive a reboot",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"refresh_timeout": schema.BoolAttribute{
+				MarkdownDescription: "Whether every apply resets the `timeout` of all entries and adds entries which have expired in the meantime again. Expired entries are then not reported as drift. Otherwise, expired entries show up as missing in the plan. Defaults to `true`",
+				Description:         "Whether every apply resets the 'timeout' of all entries and adds entries which have expired in the meantime again. Expired entries are then not reported as drift. Otherwise, expired entries show up as missing in the plan. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"expires_at": schema.StringAttribute{
+				MarkdownDescription: "RFC 3339 timestamp at which the entries written by the last apply expire, unless they are refreshed before. Null if `timeout` is unset",
+				Description:         "RFC 3339 timestamp at which the entries written by the last apply expire, unless they are refreshed before. Null if 'timeout' is unset",
+				Computed:            true,
+			},
+			"entry_ids": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "RouterOS IDs of the created entries, keyed by address",
+				Description:         "RouterOS IDs of the created entries, keyed by address",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *AddressListBulkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AddressListBulkResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	addresses := make([]string, 0, len(data.Addresses.Elements()))
+	resp.Diagnostics.Append(data.Addresses.ElementsAs(ctx, &addresses, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, err := r.addEntries(ctx, &data, addresses)
+	if err != nil {
+		// do not leave behind entries which are not tracked in state
+		created := make([]string, 0, len(ids))
+		for _, id := range ids {
+			created = append(created, id)
+		}
+		if rmErr := r.client.RemoveAddressListEntries(ctx, r.ipVersion, created); rmErr != nil {
+			resp.Diagnostics.AddWarning(clientError(rmErr, "Unable to clean up partially created address list entries"))
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to create address list entries"))
+		return
+	}
+
+	data.ID = data.List
+	data.ExpiresAt = data.expiry()
+	resp.Diagnostics.Append(data.setEntryIDs(ctx, ids)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AddressListBulkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AddressListBulkResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, diags := data.entryIDs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	entries, err := r.client.GetAddressList(ctx, r.ipVersion, data.List.ValueString())
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read address list"))
+		return
+	}
+
+	existing := make(map[string]client.AddressListEntry, len(entries))
+	for _, e := range entries {
+		existing[e.ID] = e
+	}
+
+	// Addresses are tracked by ID instead of by value, since RouterOS
+	// normalizes them, e.g. `10.0.0.1/32` becomes `10.0.0.1`.
+	found := map[string]string{}
+	addresses := []string{}
+	comment := types.StringNull()
+	for address, id := range ids {
+		if e, ok := existing[id]; ok {
+			found[address] = id
+			addresses = append(addresses, address)
+			comment = stringOrNull(e.Comment)
+		}
+	}
+
+	// Expired entries are added again on the next apply, so they are only
+	// dropped from entry_ids, which does not show up as drift.
+	if !data.refreshesTimeout() {
+		set, diags := types.SetValueFrom(ctx, types.StringType, addresses)
+		resp.Diagnostics.Append(diags...)
+		data.Addresses = set
+	}
+	if len(found) > 0 {
+		data.Comment = comment
+	}
+	resp.Diagnostics.Append(data.setEntryIDs(ctx, found)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AddressListBulkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state AddressListBulkResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, diags := state.entryIDs(ctx)
+	resp.Diagnostics.Append(diags...)
+	addresses := make([]string, 0, len(plan.Addresses.Elements()))
+	resp.Diagnostics.Append(plan.Addresses.ElementsAs(ctx, &addresses, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	desired := make(map[string]bool, len(addresses))
+	var added []string
+	for _, address := range addresses {
+		desired[address] = true
+		if _, ok := ids[address]; !ok {
+			added = append(added, address)
+		}
+	}
+
+	var removed []string
+	for address, id := range ids {
+		if !desired[address] {
+			removed = append(removed, id)
+			delete(ids, address)
+		}
+	}
+
+	plan.ID = state.ID
+	if plan.ExpiresAt.IsUnknown() {
+		plan.ExpiresAt = plan.expiry()
+	}
+	defer func() {
+		// always persist what has been written so far, even on error
+		written := make([]string, 0, len(ids))
+		for address := range ids {
+			written = append(written, address)
+		}
+		set, diags := types.SetValueFrom(ctx, types.StringType, written)
+		resp.Diagnostics.Append(diags...)
+		plan.Addresses = set
+		resp.Diagnostics.Append(plan.setEntryIDs(ctx, ids)...)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}()
+
+	if err := r.client.RemoveAddressListEntries(ctx, r.ipVersion, removed); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to remove address list entries"))
+		return
+	}
+
+	props := map[string]string{}
+	if !plan.Comment.Equal(state.Comment) {
+		props["comment"] = plan.Comment.ValueString()
+	}
+	if plan.refreshesTimeout() {
+		props["timeout"] = plan.routerOSTimeout()
+	}
+	if len(props) > 0 {
+		kept := make([]string, 0, len(ids))
+		for _, id := range ids {
+			kept = append(kept, id)
+		}
+		if err := r.client.SetAddressListEntries(ctx, r.ipVersion, kept, props); err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to update address list entries"))
+			return
+		}
+	}
+
+	created, err := r.addEntries(ctx, &plan, added)
+	for address, id := range created {
+		ids[address] = id
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create address list entries"))
+	}
+}
+
+// ModifyPlan schedules an update of existing entries on every apply if their
+// timeout is refreshed, as expired entries do not show up as drift otherwise.
+func (r *AddressListBulkResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var plan AddressListBulkResourceModel
+
+	// nothing to refresh on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.refreshesTimeout() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires_at"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("entry_ids"), types.MapUnknown(types.StringType))...)
+}
+
+func (r *AddressListBulkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AddressListBulkResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, diags := data.entryIDs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	all := make([]string, 0, len(ids))
+	for _, id := range ids {
+		all = append(all, id)
+	}
+
+	if err := r.client.RemoveAddressListEntries(ctx, r.ipVersion, all); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to remove address list entries"))
+	}
+}
+
+// addEntries creates an entry for each address and returns the IDs of all
+// entries which were created successfully, keyed by address.
+func (r *AddressListBulkResource) addEntries(ctx context.Context, data *AddressListBulkResourceModel, addresses []string) (map[string]string, error) {
+	entries := make([]client.AddressListEntry, 0, len(addresses))
+	for _, address := range addresses {
+		entries = append(entries, client.AddressListEntry{
+			List:     data.List.ValueString(),
+			Address:  address,
+			Comment:  data.Comment.ValueString(),
+			Timeout:  data.routerOSTimeout(),
+			Disabled: "false",
+		})
+	}
+
+	created, err := r.client.AddAddressListEntries(ctx, r.ipVersion, entries)
+
+	ids := make(map[string]string, len(created))
+	for i, e := range created {
+		if e.ID != "" {
+			ids[addresses[i]] = e.ID
+		}
+	}
+	return ids, err
+}
+
+func (m *AddressListBulkResourceModel) entryIDs(ctx context.Context) (map[string]string, diag.Diagnostics) {
+	ids := map[string]string{}
+	if m.EntryIDs.IsNull() || m.EntryIDs.IsUnknown() {
+		return ids, nil
+	}
+	diags := m.EntryIDs.ElementsAs(ctx, &ids, false)
+	return ids, diags
+}
+
+func (m *AddressListBulkResourceModel) setEntryIDs(ctx context.Context, ids map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	m.EntryIDs, diags = types.MapValueFrom(ctx, types.StringType, ids)
+	return diags
+}
+
+// refreshesTimeout reports whether the entries have a timeout which is reset
+// on every apply.
+func (m *AddressListBulkResourceModel) refreshesTimeout() bool {
+	return m.Timeout.ValueString() != "" && !m.RefreshTimeout.IsUnknown() && (m.RefreshTimeout.IsNull() || m.RefreshTimeout.ValueBool())
+}
+
+// timeout returns the configured timeout, or zero if unset.
+func (m *AddressListBulkResourceModel) timeout() time.Duration {
+	// the value has been validated already
+	d, _ := m.Timeout.Duration()
+	return d
+}
+
+// routerOSTimeout returns the configured timeout in the format used by
+// RouterOS, e.g. `1d2h30m`, or an empty string if unset.
+func (m *AddressListBulkResourceModel) routerOSTimeout() string {
+	if m.timeout() <= 0 {
+		return ""
+	}
+	return m.Timeout.RouterOS()
+}
+
+// expiry returns the time at which entries written now expire, or null if no
+// timeout is configured.
+func (m *AddressListBulkResourceModel) expiry() types.String {
+	d := m.timeout()
+	if d <= 0 {
+		return types.StringNull()
+	}
+	return types.StringValue(time.Now().Add(d).UTC().Format(time.RFC3339))
+}
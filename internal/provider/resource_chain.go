@@ -0,0 +1,352 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// builtinChains are the chains predefined by RouterOS across all rule tables.
+var builtinChains = []string{"input", "forward", "output", "prerouting", "postrouting", "srcnat", "dstnat"}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ChainResource{}
+
+func NewChainResource() resource.Resource {
+	return &ChainResource{}
+}
+
+// ChainResource defines the resource implementation.
+type ChainResource struct {
+	client client.API
+}
+
+// ChainResourceModel describes the resource data model.
+type ChainResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	RuleType       types.String `tfsdk:"rule_type"`
+	Name           types.String `tfsdk:"name"`
+	ParentChain    types.String `tfsdk:"parent_chain"`
+	JumpProperties types.Map    `tfsdk:"jump_properties"`
+	FinalAction    types.String `tfsdk:"final_action"`
+	Comment        types.String `tfsdk:"comment"`
+	JumpRuleID     types.String `tfsdk:"jump_rule_id"`
+	FinalRuleID    types.String `tfsdk:"final_rule_id"`
+}
+
+func (r *ChainResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_chain"
+}
+
+func (r *ChainResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *ChainResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Custom chain, consisting of a jump rule in a parent chain and a final rule which terminates the custom chain. Rules of the chain itself are managed separately, and the jump rule can be positioned via `rule_ordering` using `jump_rule_id`",
+		Description:         "Custom chain, consisting of a jump rule in a parent chain and a final rule which terminates the custom chain. Rules of the chain itself are managed separately, and the jump rule can be positioned via 'rule_ordering' using 'jump_rule_id'",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule type to create the chain in",
+				Description:         "The rule type to create the chain in",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(client.RuleTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the custom chain",
+				Description:         "Name of the custom chain",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.NoneOf(builtinChains...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"parent_chain": schema.StringAttribute{
+				MarkdownDescription: "Chain which contains the jump rule, e.g. `forward`",
+				Description:         "Chain which contains the jump rule, e.g. 'forward'",
+				Required:            true,
+			},
+			"jump_properties": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Additional RouterOS properties of the jump rule restricting which packets enter the chain, e.g. `{ \"in-interface-list\" = \"WAN\" }`",
+				Description:         "Additional RouterOS properties of the jump rule restricting which packets enter the chain, e.g. '{ \"in-interface-list\" = \"WAN\" }'",
+				Optional:            true,
+			},
+			"final_action": schema.StringAttribute{
+				MarkdownDescription: "Action of the final rule of the chain, which is kept at the end of the chain. Defaults to `return`",
+				Description:         "Action of the final rule of the chain, which is kept at the end of the chain. Defaults to 'return'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("return"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("return", "accept", "drop", "passthrough"),
+				},
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to the jump rule and the final rule",
+				Description:         "Comment attached to the jump rule and the final rule",
+				Optional:            true,
+			},
+			"jump_rule_id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "RouterOS ID of the jump rule",
+				MarkdownDescription: "RouterOS ID of the jump rule",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"final_rule_id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "RouterOS ID of the final rule",
+				MarkdownDescription: "RouterOS ID of the final rule",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *ChainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ChainResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+
+	// The final rule is created first, so that the chain is terminated as
+	// soon as packets can enter it.
+	final, err := r.client.CreateRule(ctx, ruleType, data.finalProperties())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create chain"))
+		return
+	}
+
+	props, diags := data.jumpProperties(ctx, types.MapNull(types.StringType))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	jump, err := r.client.CreateRule(ctx, ruleType, props)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create chain"))
+		if err := r.client.DeleteRule(ctx, ruleType, final[".id"]); err != nil {
+			resp.Diagnostics.AddWarning("Incomplete Cleanup", fmt.Sprintf("The final rule '%s' of the chain could not be removed, got error: %s", final[".id"], err))
+		}
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s:%s", ruleType, data.Name.ValueString()))
+	data.JumpRuleID = types.StringValue(jump[".id"])
+	data.FinalRuleID = types.StringValue(final[".id"])
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ChainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ChainResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+
+	// Without its jump rule, the chain is unreachable and has to be created
+	// again.
+	jump, err := r.client.GetRuleProperties(ctx, ruleType, data.JumpRuleID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read chain"))
+		return
+	}
+
+	data.ParentChain = types.StringValue(jump["chain"])
+	data.Comment = stringOrNull(jump["comment"])
+
+	// Only track the properties which are managed by this resource.
+	var diags diag.Diagnostics
+	data.JumpProperties, diags = managedProperties(ctx, data.JumpProperties, jump)
+	resp.Diagnostics.Append(diags...)
+
+	// The final action is only considered to be in place if the final rule is
+	// actually the last rule of the chain. Otherwise, the plan shows a diff
+	// and the rule is moved back to the end on the next apply.
+	rules, err := r.client.GetRulesOfChain(ctx, ruleType, data.Name.ValueString())
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read chain"))
+		return
+	}
+	data.FinalAction = types.StringNull()
+	if n := len(rules); n > 0 && rules[n-1].ID == data.FinalRuleID.ValueString() {
+		final, err := r.client.GetRuleProperties(ctx, ruleType, data.FinalRuleID.ValueString())
+		if err != nil && !client.IsNotFound(err) {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read chain"))
+			return
+		}
+		data.FinalAction = stringOrNull(final["action"])
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ChainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state ChainResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+
+	props, diags := data.jumpProperties(ctx, state.JumpProperties)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := r.client.UpdateRule(ctx, ruleType, state.JumpRuleID.ValueString(), props); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update chain"))
+		return
+	}
+
+	finalID := state.FinalRuleID.ValueString()
+	_, err := r.client.UpdateRule(ctx, ruleType, finalID, data.finalProperties())
+	if client.IsNotFound(err) {
+		var final map[string]string
+		final, err = r.client.CreateRule(ctx, ruleType, data.finalProperties())
+		finalID = final[".id"]
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update chain"))
+		return
+	}
+
+	rules, err := r.client.GetRulesOfChain(ctx, ruleType, data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update chain"))
+		return
+	}
+	if n := len(rules); n == 0 || rules[n-1].ID != finalID {
+		if err := r.client.MoveRules(ctx, ruleType, []string{finalID}, client.End); err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to update chain"))
+			return
+		}
+	}
+
+	data.JumpRuleID = state.JumpRuleID
+	data.FinalRuleID = types.StringValue(finalID)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ChainResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ChainResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The jump rule is removed first, so that no packets enter the chain once
+	// it is no longer terminated.
+	for _, id := range []string{data.JumpRuleID.ValueString(), data.FinalRuleID.ValueString()} {
+		err := r.client.DeleteRule(ctx, data.RuleType.ValueString(), id)
+		if err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(clientError(err, "Unable to delete chain"))
+			return
+		}
+	}
+}
+
+// jumpProperties returns the RouterOS properties of the jump rule. Properties
+// which are part of previous but no longer configured are cleared.
+func (m *ChainResourceModel) jumpProperties(ctx context.Context, previous types.Map) (map[string]string, diag.Diagnostics) {
+	props, diags := propertiesFromMap(ctx, m.JumpProperties, previous)
+	props["chain"] = m.ParentChain.ValueString()
+	props["action"] = "jump"
+	props["jump-target"] = m.Name.ValueString()
+	props["comment"] = m.Comment.ValueString()
+	return props, diags
+}
+
+// finalProperties returns the RouterOS properties of the final rule.
+func (m *ChainResourceModel) finalProperties() map[string]string {
+	return map[string]string{
+		"chain":   m.Name.ValueString(),
+		"action":  m.FinalAction.ValueString(),
+		"comment": m.Comment.ValueString(),
+	}
+}
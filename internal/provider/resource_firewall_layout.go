@@ -0,0 +1,344 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &FirewallLayoutResource{}
+
+func NewFirewallLayoutResource() resource.Resource {
+	return &FirewallLayoutResource{}
+}
+
+// FirewallLayoutResource defines the resource implementation.
+type FirewallLayoutResource struct {
+	client client.API
+}
+
+// FirewallLayoutResourceModel describes the resource data model.
+type FirewallLayoutResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	RuleType       types.String `tfsdk:"rule_type"`
+	Chains         types.Map    `tfsdk:"chains"`
+	Strict         types.Bool   `tfsdk:"strict"`
+	IgnoreDynamic  types.Bool   `tfsdk:"ignore_dynamic"`
+	IgnoreDisabled types.Bool   `tfsdk:"ignore_disabled"`
+}
+
+func (r *FirewallLayoutResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_firewall_layout"
+}
+
+func (r *FirewallLayoutResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *FirewallLayoutResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Ordering of the rules of several chains of a rule table. Equivalent to one `rule_ordering` per chain, but the table is read once and the moves of all chains are performed in one batch, which scales much better to the layout of an entire router",
+		Description:         "Ordering of the rules of several chains of a rule table. Equivalent to one 'rule_ordering' per chain, but the table is read once and the moves of all chains are performed in one batch, which scales much better to the layout of an entire router",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule table to apply the layout to. Either one of `filter`, `nat`, `mangle` and `raw`, which are tables below `/ip/firewall`, `bridge-filter` and `bridge-nat`, which are tables below `/interface/bridge`, or the menu path of a rule table, e.g. `/ipv6/firewall/filter`",
+				Description:         "The rule table to apply the layout to. Either one of 'filter', 'nat', 'mangle' and 'raw', which are tables below '/ip/firewall', 'bridge-filter' and 'bridge-nat', which are tables below '/interface/bridge', or the menu path of a rule table, e.g. '/ipv6/firewall/filter'",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.Any(
+						stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+						stringvalidator.RegexMatches(client.MenuPathRegexp, "must be a RouterOS menu path such as '/ipv6/firewall/filter'"),
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"chains": schema.MapAttribute{
+				ElementType:         types.ListType{ElemType: types.StringType},
+				MarkdownDescription: "Map of chain names to the rules of the chain in their desired order, e.g. `{ input = [\"comment:allow established\", \"comment:drop all else\"] }`. Rules are referenced either by their RouterOS ID, e.g. `*1A`, or by their comment, e.g. `comment:allow ssh`, and must belong to the chain they are listed for. Rules of chains which are not listed are left alone",
+				Description:         "Map of chain names to the rules of the chain in their desired order, e.g. '{ input = [\"comment:allow established\", \"comment:drop all else\"] }'. Rules are referenced either by their RouterOS ID, e.g. '*1A', or by their comment, e.g. 'comment:allow ssh', and must belong to the chain they are listed for. Rules of chains which are not listed are left alone",
+				Required:            true,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.ValueListsAre(ruleRefsValidator{}),
+				},
+			},
+			"strict": schema.BoolAttribute{
+				MarkdownDescription: "Whether the rules of each chain must be directly adjacent to each other. If disabled, other rules may be placed in between as long as the relative order of the listed rules is intact. Defaults to `true`",
+				Description:         "Whether the rules of each chain must be directly adjacent to each other. If disabled, other rules may be placed in between as long as the relative order of the listed rules is intact. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"ignore_dynamic": schema.BoolAttribute{
+				MarkdownDescription: "Whether to ignore dynamic rules when checking for drift. Defaults to `false`",
+				Description:         "Whether to ignore dynamic rules when checking for drift. Defaults to 'false'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"ignore_disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether to ignore disabled rules which are not part of the layout when checking for drift. Defaults to `false`",
+				Description:         "Whether to ignore disabled rules which are not part of the layout when checking for drift. Defaults to 'false'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *FirewallLayoutResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data FirewallLayoutResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.applyLayout(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id, diags := layoutID(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(id)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallLayoutResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data FirewallLayoutResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	chains, diags := data.chainRefs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+	rules, err := r.client.GetRulesOfType(ctx, ruleType)
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read layout"))
+		return
+	}
+
+	opts := data.orderingOpts()
+	observed := make(map[string][]string, len(chains))
+	for chain, refs := range chains {
+		// Resolve references so that the ordering can be compared by ID, but
+		// keep track of how each rule was referenced in the configuration.
+		ids := make([]string, 0, len(refs))
+		refsByID := make(map[string]string, len(refs))
+		for _, ref := range refs {
+			rule, err := client.MatchRuleReference(rules, ruleType, ref)
+			if errors.Is(err, client.ErrRuleNotFound) {
+				// the rule is gone, which shows up as a diff in the plan
+				continue
+			}
+			if err != nil {
+				resp.Diagnostics.AddError(clientError(err, "Unable to read layout"))
+				return
+			}
+			ids = append(ids, rule.ID)
+			refsByID[rule.ID] = ref
+		}
+
+		actual := client.ObservedOrdering(ids, opts.Filter(client.RulesOfChain(rules, chain), ids), opts.Strict)
+		for i, id := range actual {
+			if ref, ok := refsByID[id]; ok {
+				actual[i] = ref
+			}
+		}
+		if !stringSlicesEqual(actual, refs) {
+			tflog.Debug(ctx, "Detected drift in rule layout", map[string]interface{}{
+				"rule_type": ruleType,
+				"chain":     chain,
+				"expected":  refs,
+				"actual":    actual,
+			})
+		}
+		observed[chain] = actual
+	}
+
+	// Store what is actually on the device so that the plan shows precisely
+	// which rules moved.
+	data.Chains, diags = types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, observed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *FirewallLayoutResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data FirewallLayoutResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.applyLayout(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete leaves the rules where they are, like rule_ordering.
+func (r *FirewallLayoutResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// applyLayout resolves the rule references of all chains against a single
+// read of the table and orders all chains at once, see client.OrderChains.
+func (r *FirewallLayoutResource) applyLayout(ctx context.Context, data *FirewallLayoutResourceModel) (diags diag.Diagnostics) {
+	chains, diags := data.chainRefs(ctx)
+	if diags.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+	rules, err := r.client.GetRulesOfType(ctx, ruleType)
+	if err != nil {
+		diags.AddError(clientError(err, "Unable to read layout"))
+		return
+	}
+
+	ids := make(map[string][]string, len(chains))
+	for chain, refs := range chains {
+		for i, ref := range refs {
+			p := path.Root("chains").AtMapKey(chain).AtListIndex(i)
+			rule, err := client.MatchRuleReference(rules, ruleType, ref)
+			if err != nil {
+				diags.AddAttributeError(p, "Unknown Rule", fmt.Sprintf("Unable to resolve %s rule '%s', got error: %s", ruleType, ref, err))
+				continue
+			}
+			if rule.Chain != chain {
+				diags.AddAttributeError(p, "Rule Outside Of Chain",
+					fmt.Sprintf("Rule '%s' belongs to chain '%s', but is listed for chain '%s'", ref, rule.Chain, chain))
+				continue
+			}
+			ids[chain] = append(ids[chain], rule.ID)
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	moves, err := r.client.OrderChains(ctx, ruleType, ids, data.orderingOpts())
+	if err != nil {
+		diags.AddError(clientError(err, "Unable to apply layout"))
+		return
+	}
+	tflog.Debug(ctx, "Applied rule layout", map[string]interface{}{
+		"rule_type": ruleType,
+		"moves":     moves,
+	})
+	return
+}
+
+// chainRefs returns the rule references of every chain.
+func (m *FirewallLayoutResourceModel) chainRefs(ctx context.Context) (map[string][]string, diag.Diagnostics) {
+	chains := map[string][]string{}
+	diags := m.Chains.ElementsAs(ctx, &chains, false)
+	return chains, diags
+}
+
+func (m *FirewallLayoutResourceModel) orderingOpts() client.OrderingOpts {
+	return client.OrderingOpts{
+		Strict:         m.Strict.ValueBool(),
+		IgnoreDynamic:  m.IgnoreDynamic.ValueBool(),
+		IgnoreDisabled: m.IgnoreDisabled.ValueBool(),
+	}
+}
+
+// layoutID returns the identifier of a layout, a hash of its rule type and
+// the names of its chains, see orderingID.
+func layoutID(ctx context.Context, data *FirewallLayoutResourceModel) (string, diag.Diagnostics) {
+	chains, diags := data.chainRefs(ctx)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	names := make([]string, 0, len(chains))
+	for chain := range chains {
+		names = append(names, chain)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, s := range append([]string{data.RuleType.ValueString()}, names...) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s-layout-%x", data.RuleType.ValueString(), h.Sum(nil)[:8]), diags
+}
@@ -0,0 +1,414 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &HairpinNATResource{}
+
+func NewHairpinNATResource() resource.Resource {
+	return &HairpinNATResource{}
+}
+
+// HairpinNATResource defines the resource implementation.
+type HairpinNATResource struct {
+	client client.API
+}
+
+// HairpinNATResourceModel describes the resource data model.
+type HairpinNATResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	PublicAddress   types.String `tfsdk:"public_address"`
+	Protocol        types.String `tfsdk:"protocol"`
+	Port            types.String `tfsdk:"port"`
+	InternalAddress types.String `tfsdk:"internal_address"`
+	InternalPort    types.String `tfsdk:"internal_port"`
+	LANSubnet       types.String `tfsdk:"lan_subnet"`
+	Comment         types.String `tfsdk:"comment"`
+	KeepFirst       types.Bool   `tfsdk:"keep_first"`
+	DstNATRuleID    types.String `tfsdk:"dstnat_rule_id"`
+	SrcNATRuleID    types.String `tfsdk:"srcnat_rule_id"`
+	Ordered         types.Bool   `tfsdk:"ordered"`
+}
+
+func (r *HairpinNATResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_hairpin_nat"
+}
+
+func (r *HairpinNATResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *HairpinNATResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Port forwarding to an internal server which is also reachable from the LAN via the public address, also known as hairpin NAT. Creates a `dst-nat` rule in the `dstnat` chain and a `masquerade` rule for LAN clients in the `srcnat` chain. Note that forwarded connections still have to be accepted by the `forward` chain of the filter table",
+		Description:         "Port forwarding to an internal server which is also reachable from the LAN via the public address, also known as hairpin NAT. Creates a 'dst-nat' rule in the 'dstnat' chain and a 'masquerade' rule for LAN clients in the 'srcnat' chain. Note that forwarded connections still have to be accepted by the 'forward' chain of the filter table",
+		Attributes: map[string]schema.Attribute{
+			"public_address": schema.StringAttribute{
+				MarkdownDescription: "Public address of the router which connections are forwarded from, e.g. `203.0.113.10`",
+				Description:         "Public address of the router which connections are forwarded from, e.g. '203.0.113.10'",
+				Required:            true,
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "Protocol of the forwarded connections. Defaults to `tcp`",
+				Description:         "Protocol of the forwarded connections. Defaults to 'tcp'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("tcp"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("tcp", "udp"),
+				},
+			},
+			"port": schema.StringAttribute{
+				MarkdownDescription: "Public port, port range or comma-separated list of ports which is forwarded, e.g. `443` or `8000-8010`",
+				Description:         "Public port, port range or comma-separated list of ports which is forwarded, e.g. '443' or '8000-8010'",
+				Required:            true,
+			},
+			"internal_address": schema.StringAttribute{
+				MarkdownDescription: "Address of the internal server, e.g. `192.168.88.10`",
+				Description:         "Address of the internal server, e.g. '192.168.88.10'",
+				Required:            true,
+			},
+			"internal_port": schema.StringAttribute{
+				MarkdownDescription: "Port of the internal server. Defaults to `port`",
+				Description:         "Port of the internal server. Defaults to 'port'",
+				Optional:            true,
+			},
+			"lan_subnet": schema.StringAttribute{
+				MarkdownDescription: "Subnet of the LAN clients which reach the server via the public address, e.g. `192.168.88.0/24`. Their connections are masqueraded so that the replies of the server are routed back through the router",
+				Description:         "Subnet of the LAN clients which reach the server via the public address, e.g. '192.168.88.0/24'. Their connections are masqueraded so that the replies of the server are routed back through the router",
+				Required:            true,
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to both rules",
+				Description:         "Comment attached to both rules",
+				Optional:            true,
+			},
+			"keep_first": schema.BoolAttribute{
+				MarkdownDescription: "Whether to keep both rules in front of all other rules of their chain, so that they are not shadowed by broader rules such as a general masquerade rule. If disabled, the rules are added to the end of the table and may be arranged with the `rule_ordering` resource. Defaults to `true`",
+				Description:         "Whether to keep both rules in front of all other rules of their chain, so that they are not shadowed by broader rules such as a general masquerade rule. If disabled, the rules are added to the end of the table and may be arranged with the 'rule_ordering' resource. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"dstnat_rule_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RouterOS ID of the `dst-nat` rule",
+				Description:         "RouterOS ID of the 'dst-nat' rule",
+			},
+			"srcnat_rule_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RouterOS ID of the `masquerade` rule",
+				Description:         "RouterOS ID of the 'masquerade' rule",
+			},
+			"ordered": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether both rules exist and, if `keep_first` is set, are the first rules of their chain. A value of `false` indicates drift, which is corrected on the next apply",
+				Description:         "Whether both rules exist and, if 'keep_first' is set, are the first rules of their chain. A value of 'false' indicates drift, which is corrected on the next apply",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *HairpinNATResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data HairpinNATResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, err := r.applyRules(ctx, &data, nil)
+	if err == nil && data.KeepFirst.ValueBool() {
+		err = r.placeFirst(ctx, ids)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create hairpin NAT"))
+		r.cleanup(ctx, ids, &resp.Diagnostics)
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("nat:%s", ids[0]))
+	data.setRuleIDs(ids)
+	data.Ordered = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *HairpinNATResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data HairpinNATResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules := make([]map[string]string, 0, len(hairpinChains))
+	found := make([]bool, 0, len(hairpinChains))
+	for _, id := range data.ruleIDs() {
+		props, err := r.client.GetRuleProperties(ctx, "nat", id)
+		if err != nil && !client.IsNotFound(err) {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read hairpin NAT"))
+			return
+		}
+		rules = append(rules, props)
+		found = append(found, err == nil)
+	}
+
+	if !found[0] && !found[1] {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// A rule which was removed from the device is created again on the next
+	// apply, which is signaled through ordered.
+	data.Ordered = types.BoolValue(found[0] && found[1])
+	if dstnat := rules[0]; found[0] {
+		data.PublicAddress = types.StringValue(dstnat["dst-address"])
+		data.Protocol = types.StringValue(dstnat["protocol"])
+		data.Port = types.StringValue(dstnat["dst-port"])
+		data.InternalAddress = types.StringValue(dstnat["to-addresses"])
+		if !data.InternalPort.IsNull() || dstnat["to-ports"] != dstnat["dst-port"] {
+			data.InternalPort = stringOrNull(dstnat["to-ports"])
+		}
+		data.Comment = stringOrNull(dstnat["comment"])
+	}
+	if srcnat := rules[1]; found[1] {
+		data.LANSubnet = types.StringValue(srcnat["src-address"])
+	}
+
+	if data.Ordered.ValueBool() && data.KeepFirst.ValueBool() {
+		first, err := r.isFirst(ctx, data.ruleIDs())
+		if err != nil {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read hairpin NAT"))
+			return
+		}
+		data.Ordered = types.BoolValue(first)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *HairpinNATResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state HairpinNATResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, err := r.applyRules(ctx, &data, state.ruleIDs())
+	if err == nil && data.KeepFirst.ValueBool() {
+		err = r.placeFirst(ctx, ids)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update hairpin NAT"))
+		return
+	}
+
+	data.setRuleIDs(ids)
+	data.Ordered = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *HairpinNATResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data HairpinNATResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, id := range data.ruleIDs() {
+		err := r.client.DeleteRule(ctx, "nat", id)
+		if err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(clientError(err, "Unable to delete hairpin NAT"))
+			return
+		}
+	}
+}
+
+// hairpinChains are the chains of the dst-nat and the masquerade rule, in the
+// order in which their IDs are passed around.
+var hairpinChains = []string{"dstnat", "srcnat"}
+
+// applyRules sets the properties of both rules, creating those which do not
+// exist (yet). It returns the IDs of the dst-nat and the masquerade rule,
+// including the IDs of the rules which were created before an error occurred.
+func (r *HairpinNATResource) applyRules(ctx context.Context, data *HairpinNATResourceModel, previous []string) ([]string, error) {
+	ids := make([]string, 0, len(hairpinChains))
+	for i, props := range []map[string]string{data.dstNATProperties(), data.srcNATProperties()} {
+		var id string
+		if i < len(previous) {
+			id = previous[i]
+		}
+		id, err := upsertRule(ctx, r.client, "nat", id, props)
+		if id != "" {
+			ids = append(ids, id)
+		}
+		if err != nil {
+			return ids, err
+		}
+	}
+	return ids, nil
+}
+
+// placeFirst moves each rule in front of all other rules of its chain.
+func (r *HairpinNATResource) placeFirst(ctx context.Context, ids []string) error {
+	for i, id := range ids {
+		if err := keepFirstOfChain(ctx, r.client, "nat", hairpinChains[i], id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isFirst reports whether each rule is the first rule of its chain.
+func (r *HairpinNATResource) isFirst(ctx context.Context, ids []string) (bool, error) {
+	for i, id := range ids {
+		first, err := isFirstOfChain(ctx, r.client, "nat", hairpinChains[i], id)
+		if err != nil || !first {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isFirstOfChain reports whether the rule with the given ID is the first rule
+// of chain.
+func isFirstOfChain(ctx context.Context, c client.API, ruleType, chain, id string) (bool, error) {
+	rules, err := c.GetRulesOfChain(ctx, ruleType, chain)
+	if err != nil {
+		return false, err
+	}
+	return len(rules) > 0 && rules[0].ID == id, nil
+}
+
+// keepFirstOfChain moves the rule with the given ID to the start of the table
+// unless it is the first rule of chain already. Rules of other chains are
+// irrelevant for the evaluation of a chain, so this places it in front of its
+// chain.
+func keepFirstOfChain(ctx context.Context, c client.API, ruleType, chain, id string) error {
+	first, err := isFirstOfChain(ctx, c, ruleType, chain, id)
+	if err != nil || first {
+		return err
+	}
+	return c.MoveRules(ctx, ruleType, []string{id}, client.Start)
+}
+
+// cleanup removes the rules of a hairpin NAT which could not be created
+// completely.
+func (r *HairpinNATResource) cleanup(ctx context.Context, ids []string, diags *diag.Diagnostics) {
+	for _, id := range ids {
+		if err := r.client.DeleteRule(ctx, "nat", id); err != nil && !client.IsNotFound(err) {
+			diags.AddWarning("Incomplete Cleanup", fmt.Sprintf("The rule '%s' of the hairpin NAT could not be removed, got error: %s", id, err))
+		}
+	}
+}
+
+func (m *HairpinNATResourceModel) ruleIDs() []string {
+	return []string{m.DstNATRuleID.ValueString(), m.SrcNATRuleID.ValueString()}
+}
+
+func (m *HairpinNATResourceModel) setRuleIDs(ids []string) {
+	m.DstNATRuleID = types.StringValue(ids[0])
+	m.SrcNATRuleID = types.StringValue(ids[1])
+}
+
+// internalPort returns the port of the internal server.
+func (m *HairpinNATResourceModel) internalPort() string {
+	if m.InternalPort.IsNull() {
+		return m.Port.ValueString()
+	}
+	return m.InternalPort.ValueString()
+}
+
+// dstNATProperties returns the properties of the rule which forwards
+// connections to the public address to the internal server.
+func (m *HairpinNATResourceModel) dstNATProperties() map[string]string {
+	return map[string]string{
+		"chain":        "dstnat",
+		"action":       "dst-nat",
+		"dst-address":  m.PublicAddress.ValueString(),
+		"protocol":     m.Protocol.ValueString(),
+		"dst-port":     m.Port.ValueString(),
+		"to-addresses": m.InternalAddress.ValueString(),
+		"to-ports":     m.internalPort(),
+		"comment":      m.Comment.ValueString(),
+	}
+}
+
+// srcNATProperties returns the properties of the rule which masquerades
+// forwarded connections of LAN clients, so that the internal server replies
+// through the router rather than directly to the client.
+func (m *HairpinNATResourceModel) srcNATProperties() map[string]string {
+	return map[string]string{
+		"chain":       "srcnat",
+		"action":      "masquerade",
+		"src-address": m.LANSubnet.ValueString(),
+		"dst-address": m.InternalAddress.ValueString(),
+		"protocol":    m.Protocol.ValueString(),
+		"dst-port":    m.internalPort(),
+		"comment":     m.Comment.ValueString(),
+	}
+}
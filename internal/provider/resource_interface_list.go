@@ -0,0 +1,236 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &InterfaceListResource{}
+var _ resource.ResourceWithImportState = &InterfaceListResource{}
+
+func NewInterfaceListResource() resource.Resource {
+	return &InterfaceListResource{}
+}
+
+// InterfaceListResource defines the resource implementation.
+type InterfaceListResource struct {
+	client client.API
+}
+
+// InterfaceListResourceModel describes the resource data model.
+type InterfaceListResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Name    types.String `tfsdk:"name"`
+	Comment types.String `tfsdk:"comment"`
+	Include types.Set    `tfsdk:"include"`
+	Exclude types.Set    `tfsdk:"exclude"`
+}
+
+func (r *InterfaceListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_interface_list"
+}
+
+func (r *InterfaceListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *InterfaceListResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Interface list (`/interface/list`)",
+		Description:         "Interface list (/interface/list)",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the interface list",
+				Description:         "Name of the interface list",
+				Required:            true,
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to the interface list",
+				Description:         "Comment attached to the interface list",
+				Optional:            true,
+			},
+			"include": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of other interface lists whose members are included in this list",
+				Description:         "Names of other interface lists whose members are included in this list",
+				Optional:            true,
+			},
+			"exclude": schema.SetAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Names of other interface lists whose members are excluded from this list",
+				Description:         "Names of other interface lists whose members are excluded from this list",
+				Optional:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *InterfaceListResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data InterfaceListResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l, diags := data.toClient(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateInterfaceList(ctx, l)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create interface list"))
+		return
+	}
+
+	resp.Diagnostics.Append(data.fromClient(ctx, created)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *InterfaceListResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data InterfaceListResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l, err := r.client.GetInterfaceList(ctx, data.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read interface list"))
+		return
+	}
+
+	resp.Diagnostics.Append(data.fromClient(ctx, l)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *InterfaceListResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data InterfaceListResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l, diags := data.toClient(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateInterfaceList(ctx, l)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update interface list"))
+		return
+	}
+
+	resp.Diagnostics.Append(data.fromClient(ctx, updated)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *InterfaceListResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data InterfaceListResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteInterfaceList(ctx, data.ID.ValueString())
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(clientError(err, "Unable to delete interface list"))
+	}
+}
+
+func (r *InterfaceListResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (m *InterfaceListResourceModel) toClient(ctx context.Context) (client.InterfaceList, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	include, d := commaListFromSet(ctx, m.Include)
+	diags.Append(d...)
+	exclude, d := commaListFromSet(ctx, m.Exclude)
+	diags.Append(d...)
+
+	return client.InterfaceList{
+		ID:      m.ID.ValueString(),
+		Name:    m.Name.ValueString(),
+		Comment: m.Comment.ValueString(),
+		Include: include,
+		Exclude: exclude,
+	}, diags
+}
+
+func (m *InterfaceListResourceModel) fromClient(ctx context.Context, l client.InterfaceList) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var d diag.Diagnostics
+
+	m.ID = types.StringValue(l.ID)
+	m.Name = types.StringValue(l.Name)
+	m.Comment = stringOrNull(l.Comment)
+	m.Include, d = setFromCommaList(ctx, l.Include)
+	diags.Append(d...)
+	m.Exclude, d = setFromCommaList(ctx, l.Exclude)
+	diags.Append(d...)
+
+	return diags
+}
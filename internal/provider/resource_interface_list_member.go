@@ -0,0 +1,211 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &InterfaceListMemberResource{}
+var _ resource.ResourceWithImportState = &InterfaceListMemberResource{}
+
+func NewInterfaceListMemberResource() resource.Resource {
+	return &InterfaceListMemberResource{}
+}
+
+// InterfaceListMemberResource defines the resource implementation.
+type InterfaceListMemberResource struct {
+	client client.API
+}
+
+// InterfaceListMemberResourceModel describes the resource data model.
+type InterfaceListMemberResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Interface types.String `tfsdk:"interface"`
+	List      types.String `tfsdk:"list"`
+	Comment   types.String `tfsdk:"comment"`
+	Disabled  types.Bool   `tfsdk:"disabled"`
+}
+
+func (r *InterfaceListMemberResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_interface_list_member"
+}
+
+func (r *InterfaceListMemberResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *InterfaceListMemberResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Interface list member (`/interface/list/member`)",
+		Description:         "Interface list member (/interface/list/member)",
+		Attributes: map[string]schema.Attribute{
+			"interface": schema.StringAttribute{
+				MarkdownDescription: "Name of the interface to add to the list",
+				Description:         "Name of the interface to add to the list",
+				Required:            true,
+			},
+			"list": schema.StringAttribute{
+				MarkdownDescription: "Name of the interface list",
+				Description:         "Name of the interface list",
+				Required:            true,
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to the list member",
+				Description:         "Comment attached to the list member",
+				Optional:            true,
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the list member is disabled",
+				Description:         "Whether the list member is disabled",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *InterfaceListMemberResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data InterfaceListMemberResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateInterfaceListMember(ctx, data.toClient())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create interface list member"))
+		return
+	}
+
+	data.fromClient(created)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *InterfaceListMemberResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data InterfaceListMemberResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	m, err := r.client.GetInterfaceListMember(ctx, data.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read interface list member"))
+		return
+	}
+
+	data.fromClient(m)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *InterfaceListMemberResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data InterfaceListMemberResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateInterfaceListMember(ctx, data.toClient())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update interface list member"))
+		return
+	}
+
+	data.fromClient(updated)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *InterfaceListMemberResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data InterfaceListMemberResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteInterfaceListMember(ctx, data.ID.ValueString())
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(clientError(err, "Unable to delete interface list member"))
+	}
+}
+
+func (r *InterfaceListMemberResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (m *InterfaceListMemberResourceModel) toClient() client.InterfaceListMember {
+	return client.InterfaceListMember{
+		ID:        m.ID.ValueString(),
+		Interface: m.Interface.ValueString(),
+		List:      m.List.ValueString(),
+		Comment:   m.Comment.ValueString(),
+		Disabled:  strconv.FormatBool(m.Disabled.ValueBool()),
+	}
+}
+
+func (m *InterfaceListMemberResourceModel) fromClient(c client.InterfaceListMember) {
+	m.ID = types.StringValue(c.ID)
+	m.Interface = types.StringValue(c.Interface)
+	m.List = types.StringValue(c.List)
+	m.Comment = stringOrNull(c.Comment)
+	m.Disabled = types.BoolValue(c.Disabled == "true")
+}
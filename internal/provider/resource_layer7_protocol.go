@@ -0,0 +1,207 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Layer7ProtocolResource{}
+var _ resource.ResourceWithImportState = &Layer7ProtocolResource{}
+
+func NewLayer7ProtocolResource() resource.Resource {
+	return &Layer7ProtocolResource{}
+}
+
+// Layer7ProtocolResource defines the resource implementation.
+type Layer7ProtocolResource struct {
+	client client.API
+}
+
+// Layer7ProtocolResourceModel describes the resource data model.
+type Layer7ProtocolResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Name    types.String `tfsdk:"name"`
+	Regexp  types.String `tfsdk:"regexp"`
+	Comment types.String `tfsdk:"comment"`
+}
+
+func (r *Layer7ProtocolResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_layer7_protocol"
+}
+
+func (r *Layer7ProtocolResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *Layer7ProtocolResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Layer7 protocol definition (`/ip/firewall/layer7-protocol`)",
+		Description:         "Layer7 protocol definition (/ip/firewall/layer7-protocol)",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the protocol, which is referenced via `layer7-protocol` in firewall rules",
+				Description:         "Name of the protocol, which is referenced via 'layer7-protocol' in firewall rules",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"regexp": schema.StringAttribute{
+				MarkdownDescription: "POSIX regular expression which is matched against the first packets of a connection",
+				Description:         "POSIX regular expression which is matched against the first packets of a connection",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to the protocol",
+				Description:         "Comment attached to the protocol",
+				Optional:            true,
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *Layer7ProtocolResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data Layer7ProtocolResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateLayer7Protocol(ctx, data.toClient())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create layer7 protocol"))
+		return
+	}
+
+	data.fromClient(created)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Layer7ProtocolResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data Layer7ProtocolResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	l, err := r.client.GetLayer7Protocol(ctx, data.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read layer7 protocol"))
+		return
+	}
+
+	data.fromClient(l)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Layer7ProtocolResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data Layer7ProtocolResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateLayer7Protocol(ctx, data.toClient())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update layer7 protocol"))
+		return
+	}
+
+	data.fromClient(updated)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Layer7ProtocolResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data Layer7ProtocolResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteLayer7Protocol(ctx, data.ID.ValueString())
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(clientError(err, "Unable to delete layer7 protocol"))
+	}
+}
+
+func (r *Layer7ProtocolResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (m *Layer7ProtocolResourceModel) toClient() client.Layer7Protocol {
+	return client.Layer7Protocol{
+		ID:      m.ID.ValueString(),
+		Name:    m.Name.ValueString(),
+		Regexp:  m.Regexp.ValueString(),
+		Comment: m.Comment.ValueString(),
+	}
+}
+
+func (m *Layer7ProtocolResourceModel) fromClient(l client.Layer7Protocol) {
+	m.ID = types.StringValue(l.ID)
+	m.Name = types.StringValue(l.Name)
+	m.Regexp = types.StringValue(l.Regexp)
+	m.Comment = stringOrNull(l.Comment)
+}
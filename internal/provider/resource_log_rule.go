@@ -0,0 +1,391 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &LogRuleResource{}
+
+func NewLogRuleResource() resource.Resource {
+	return &LogRuleResource{}
+}
+
+// LogRuleResource defines the resource implementation.
+type LogRuleResource struct {
+	client client.API
+}
+
+// LogRuleResourceModel describes the resource data model.
+type LogRuleResourceModel struct {
+	ID               types.String  `tfsdk:"id"`
+	RuleType         types.String  `tfsdk:"rule_type"`
+	Chain            types.String  `tfsdk:"chain"`
+	LogPrefix        types.String  `tfsdk:"log_prefix"`
+	SrcAddress       CIDRValue     `tfsdk:"src_address"`
+	DstAddress       CIDRValue     `tfsdk:"dst_address"`
+	Protocol         types.String  `tfsdk:"protocol"`
+	SrcPort          types.String  `tfsdk:"src_port"`
+	DstPort          types.String  `tfsdk:"dst_port"`
+	InInterface      types.String  `tfsdk:"in_interface"`
+	OutInterface     types.String  `tfsdk:"out_interface"`
+	InInterfaceList  types.String  `tfsdk:"in_interface_list"`
+	OutInterfaceList types.String  `tfsdk:"out_interface_list"`
+	Comment          types.String  `tfsdk:"comment"`
+	PlaceBefore      types.String  `tfsdk:"place_before"`
+	TTL              DurationValue `tfsdk:"ttl"`
+	SchedulerID      types.String  `tfsdk:"scheduler_id"`
+	Expired          types.Bool    `tfsdk:"expired"`
+}
+
+// logRuleMatchers maps the matchers of the log rule to their RouterOS
+// properties.
+var logRuleMatchers = []struct {
+	name, property, description string
+	field                       func(m *LogRuleResourceModel) *types.String
+}{
+	{"protocol", "protocol", "IP protocol to match, e.g. `tcp`", func(m *LogRuleResourceModel) *types.String { return &m.Protocol }},
+	{"src_port", "src-port", "Source ports or port ranges to match. Requires `protocol` to be `tcp` or `udp`", func(m *LogRuleResourceModel) *types.String { return &m.SrcPort }},
+	{"dst_port", "dst-port", "Destination ports or port ranges to match. Requires `protocol` to be `tcp` or `udp`", func(m *LogRuleResourceModel) *types.String { return &m.DstPort }},
+	{"in_interface", "in-interface", "Interface the packet entered the router through", func(m *LogRuleResourceModel) *types.String { return &m.InInterface }},
+	{"out_interface", "out-interface", "Interface the packet is leaving the router through", func(m *LogRuleResourceModel) *types.String { return &m.OutInterface }},
+	{"in_interface_list", "in-interface-list", "Interface list the incoming interface must be a member of", func(m *LogRuleResourceModel) *types.String { return &m.InInterfaceList }},
+	{"out_interface_list", "out-interface-list", "Interface list the outgoing interface must be a member of", func(m *LogRuleResourceModel) *types.String { return &m.OutInterfaceList }},
+}
+
+func (r *LogRuleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_log_rule"
+}
+
+func (r *LogRuleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *LogRuleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"rule_type": schema.StringAttribute{
+			MarkdownDescription: "Firewall table the rule is added to, one of `filter`, `nat`, `mangle` or `raw`. Defaults to `filter`",
+			Description:         "Firewall table the rule is added to, one of 'filter', 'nat', 'mangle' or 'raw'. Defaults to 'filter'",
+			Optional:            true,
+			Computed:            true,
+			Default:             stringdefault.StaticString("filter"),
+			Validators: []validator.String{
+				stringvalidator.OneOf(client.RuleTypes...),
+			},
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"chain": schema.StringAttribute{
+			MarkdownDescription: "Chain the rule belongs to, e.g. `forward`",
+			Description:         "Chain the rule belongs to, e.g. 'forward'",
+			Required:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"log_prefix": schema.StringAttribute{
+			MarkdownDescription: "Prefix of the log messages of matching packets, e.g. `debug-ssh`",
+			Description:         "Prefix of the log messages of matching packets, e.g. 'debug-ssh'",
+			Required:            true,
+		},
+		"src_address": schema.StringAttribute{
+			MarkdownDescription: "Source address, range or subnet to match",
+			Description:         "Source address, range or subnet to match",
+			Optional:            true,
+			CustomType:          CIDRType{},
+		},
+		"dst_address": schema.StringAttribute{
+			MarkdownDescription: "Destination address, range or subnet to match",
+			Description:         "Destination address, range or subnet to match",
+			Optional:            true,
+			CustomType:          CIDRType{},
+		},
+		"comment": schema.StringAttribute{
+			MarkdownDescription: "Comment attached to the rule",
+			Description:         "Comment attached to the rule",
+			Optional:            true,
+		},
+		"place_before": schema.StringAttribute{
+			MarkdownDescription: "Rule which the log rule is inserted in front of, referenced either by its ID or by its comment, e.g. `comment:drop all else`. If unset, the rule is added to the end of the table. Changing it creates a new rule",
+			Description:         "Rule which the log rule is inserted in front of, referenced either by its ID or by its comment, e.g. 'comment:drop all else'. If unset, the rule is added to the end of the table. Changing it creates a new rule",
+			Optional:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"ttl": schema.StringAttribute{
+			MarkdownDescription: "Time after which the device disables the rule on its own, e.g. `1h`, even if Terraform is not run again. Implemented by a scheduler entry which removes itself once it has run. Changing it creates a new rule",
+			Description:         "Time after which the device disables the rule on its own, e.g. '1h', even if Terraform is not run again. Implemented by a scheduler entry which removes itself once it has run. Changing it creates a new rule",
+			Optional:            true,
+			CustomType:          DurationType{},
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"scheduler_id": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "RouterOS ID of the scheduler entry which disables the rule once `ttl` has passed. Null if `ttl` is unset or the entry has already run",
+			Description:         "RouterOS ID of the scheduler entry which disables the rule once 'ttl' has passed. Null if 'ttl' is unset or the entry has already run",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"expired": schema.BoolAttribute{
+			Computed:            true,
+			MarkdownDescription: "Whether the rule is disabled on the device, e.g. because `ttl` has passed. Expired rules are not enabled again, replace the resource to log for another `ttl`",
+			Description:         "Whether the rule is disabled on the device, e.g. because 'ttl' has passed. Expired rules are not enabled again, replace the resource to log for another 'ttl'",
+			PlanModifiers: []planmodifier.Bool{
+				boolplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"id": schema.StringAttribute{
+			Computed:            true,
+			Description:         "Identifier of resource",
+			MarkdownDescription: "Identifier of resource",
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+	}
+	for _, m := range logRuleMatchers {
+		attributes[m.name] = schema.StringAttribute{
+			MarkdownDescription: m.description,
+			Description:         strings.ReplaceAll(m.description, "`", "'"),
+			Optional:            true,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Temporary logging of packets for debugging. Creates a `passthrough` rule with logging enabled, which leaves the processing of packets unchanged, and optionally disables it after a given time",
+		Description:         "Temporary logging of packets for debugging. Creates a 'passthrough' rule with logging enabled, which leaves the processing of packets unchanged, and optionally disables it after a given time",
+		Attributes:          attributes,
+	}
+}
+
+func (r *LogRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LogRuleResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ruleType := data.RuleType.ValueString()
+
+	props := map[string]string{}
+	for k, v := range data.properties() {
+		if v != "" {
+			props[k] = v
+		}
+	}
+	props["chain"] = data.Chain.ValueString()
+	props["action"] = "passthrough"
+	if ref := data.PlaceBefore.ValueString(); ref != "" {
+		target, err := r.client.ResolveRuleReference(ctx, ruleType, ref)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("place_before"),
+				"Unknown Rule",
+				fmt.Sprintf("Unable to resolve %s rule '%s', got error: %s", ruleType, ref, err),
+			)
+			return
+		}
+		props[client.PlaceBeforeProperty] = target.ID
+	}
+
+	created, err := r.client.CreateRule(ctx, ruleType, props)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create log rule"))
+		return
+	}
+	id := created[".id"]
+
+	data.SchedulerID = types.StringNull()
+	if !data.TTL.IsNull() {
+		entry, err := r.scheduleExpiry(ctx, &data, id)
+		if err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to schedule the expiry of log rule"))
+			if err := r.client.DeleteRule(ctx, ruleType, id); err != nil && !client.IsNotFound(err) {
+				resp.Diagnostics.AddWarning("Incomplete Cleanup", fmt.Sprintf("The log rule '%s' could not be removed, got error: %s", id, err))
+			}
+			return
+		}
+		data.SchedulerID = types.StringValue(entry.ID)
+	}
+
+	data.ID = types.StringValue(id)
+	data.Expired = types.BoolValue(created["disabled"] == "true")
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LogRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LogRuleResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	props, err := r.client.GetRuleProperties(ctx, data.RuleType.ValueString(), data.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read log rule"))
+		return
+	}
+
+	data.Chain = types.StringValue(props["chain"])
+	data.LogPrefix = types.StringValue(props["log-prefix"])
+	data.SrcAddress = CIDRValue{StringValue: stringOrNull(props["src-address"])}
+	data.DstAddress = CIDRValue{StringValue: stringOrNull(props["dst-address"])}
+	data.setMatchers(props)
+	data.Comment = stringOrNull(props["comment"])
+	data.Expired = types.BoolValue(props["disabled"] == "true")
+
+	// The scheduler entry removes itself once it has run.
+	if id := data.SchedulerID.ValueString(); id != "" {
+		_, err := r.client.GetSchedulerEntry(ctx, id)
+		if client.IsNotFound(err) {
+			data.SchedulerID = types.StringNull()
+		} else if err != nil {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read log rule"))
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LogRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state LogRuleResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the matchers, the prefix and the comment are updated in place, so
+	// a rule which has expired stays disabled.
+	updated, err := r.client.UpdateRule(ctx, state.RuleType.ValueString(), state.ID.ValueString(), data.properties())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update log rule"))
+		return
+	}
+
+	data.ID = state.ID
+	data.SchedulerID = state.SchedulerID
+	data.Expired = types.BoolValue(updated["disabled"] == "true")
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LogRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data LogRuleResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if id := data.SchedulerID.ValueString(); id != "" {
+		if err := r.client.DeleteSchedulerEntry(ctx, id); err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(clientError(err, "Unable to delete log rule"))
+			return
+		}
+	}
+	if err := r.client.DeleteRule(ctx, data.RuleType.ValueString(), data.ID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(clientError(err, "Unable to delete log rule"))
+	}
+}
+
+// scheduleExpiry adds a scheduler entry which disables the rule with the
+// given ID once the TTL has passed and then removes itself.
+func (r *LogRuleResource) scheduleExpiry(ctx context.Context, data *LogRuleResourceModel, id string) (client.SchedulerEntry, error) {
+	disable, err := client.RuleCommand(data.RuleType.ValueString(), "disable", id)
+	if err != nil {
+		return client.SchedulerEntry{}, err
+	}
+	name := fmt.Sprintf("fwfl-log-rule-%s", strings.TrimPrefix(id, "*"))
+	return r.client.CreateSchedulerEntry(ctx, client.SchedulerEntry{
+		Name:     name,
+		Interval: data.TTL.RouterOS(),
+		OnEvent:  fmt.Sprintf("%s; /system scheduler remove [find where name=\"%s\"]", disable, name),
+		Policy:   "read,write",
+		Comment:  fmt.Sprintf("expiry of log rule %s", id),
+	})
+}
+
+// properties returns the properties of the rule which can be updated in
+// place. Empty properties clear the property of an existing rule.
+func (m *LogRuleResourceModel) properties() map[string]string {
+	props := map[string]string{
+		"log":         "yes",
+		"log-prefix":  m.LogPrefix.ValueString(),
+		"src-address": m.SrcAddress.ValueString(),
+		"dst-address": m.DstAddress.ValueString(),
+		"comment":     m.Comment.ValueString(),
+	}
+	for _, matcher := range logRuleMatchers {
+		props[matcher.property] = matcher.field(m).ValueString()
+	}
+	return props
+}
+
+// setMatchers sets the matchers to the given properties of the rule.
+func (m *LogRuleResourceModel) setMatchers(props map[string]string) {
+	for _, matcher := range logRuleMatchers {
+		*matcher.field(m) = stringOrNull(props[matcher.property])
+	}
+}
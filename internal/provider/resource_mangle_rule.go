@@ -0,0 +1,32 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func NewMangleRuleResource() resource.Resource {
+	return &firewallRuleResource{
+		ruleType:    "mangle",
+		typeName:    "_mangle_rule",
+		description: "Firewall mangle rule (`/ip/firewall/mangle`). New rules are added to the end of the table unless `place_before` is set, use the `rule_ordering` resource to arrange them",
+		attributes:  ruleAttributesFor("mangle"),
+	}
+}
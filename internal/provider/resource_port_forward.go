@@ -0,0 +1,454 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &PortForwardResource{}
+
+func NewPortForwardResource() resource.Resource {
+	return &PortForwardResource{}
+}
+
+// PortForwardResource defines the resource implementation.
+type PortForwardResource struct {
+	client client.API
+}
+
+// PortForwardResourceModel describes the resource data model.
+type PortForwardResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	ExternalPort    types.String `tfsdk:"external_port"`
+	InternalAddress types.String `tfsdk:"internal_address"`
+	InternalPort    types.String `tfsdk:"internal_port"`
+	Protocol        types.String `tfsdk:"protocol"`
+	InInterfaceList types.String `tfsdk:"in_interface_list"`
+	DstAddress      types.String `tfsdk:"dst_address"`
+	Comment         types.String `tfsdk:"comment"`
+	AllowForward    types.Bool   `tfsdk:"allow_forward"`
+	AllowBefore     types.String `tfsdk:"allow_before"`
+	DstNATRuleID    types.String `tfsdk:"dstnat_rule_id"`
+	FilterRuleID    types.String `tfsdk:"filter_rule_id"`
+	Ordered         types.Bool   `tfsdk:"ordered"`
+}
+
+func (r *PortForwardResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_port_forward"
+}
+
+func (r *PortForwardResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *PortForwardResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Port forwarding to an internal server. Creates a `dst-nat` rule which is kept in front of all other rules of the `dstnat` chain and, unless disabled, a rule in the `forward` chain of the filter table which accepts the forwarded connections",
+		Description:         "Port forwarding to an internal server. Creates a 'dst-nat' rule which is kept in front of all other rules of the 'dstnat' chain and, unless disabled, a rule in the 'forward' chain of the filter table which accepts the forwarded connections",
+		Attributes: map[string]schema.Attribute{
+			"external_port": schema.StringAttribute{
+				MarkdownDescription: "Port, port range or comma-separated list of ports which is forwarded, e.g. `443` or `8000-8010`",
+				Description:         "Port, port range or comma-separated list of ports which is forwarded, e.g. '443' or '8000-8010'",
+				Required:            true,
+			},
+			"internal_address": schema.StringAttribute{
+				MarkdownDescription: "Address of the internal server, e.g. `192.168.88.10`",
+				Description:         "Address of the internal server, e.g. '192.168.88.10'",
+				Required:            true,
+			},
+			"internal_port": schema.StringAttribute{
+				MarkdownDescription: "Port of the internal server. Defaults to `external_port`",
+				Description:         "Port of the internal server. Defaults to 'external_port'",
+				Optional:            true,
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "Protocol of the forwarded connections. Defaults to `tcp`",
+				Description:         "Protocol of the forwarded connections. Defaults to 'tcp'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("tcp"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("tcp", "udp"),
+				},
+			},
+			"in_interface_list": schema.StringAttribute{
+				MarkdownDescription: "Interface list which connections must arrive on to be forwarded, e.g. `WAN`",
+				Description:         "Interface list which connections must arrive on to be forwarded, e.g. 'WAN'",
+				Optional:            true,
+			},
+			"dst_address": schema.StringAttribute{
+				MarkdownDescription: "Public address which connections must be destined to to be forwarded, e.g. `203.0.113.10`",
+				Description:         "Public address which connections must be destined to to be forwarded, e.g. '203.0.113.10'",
+				Optional:            true,
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment attached to the rules",
+				Description:         "Comment attached to the rules",
+				Optional:            true,
+			},
+			"allow_forward": schema.BoolAttribute{
+				MarkdownDescription: "Whether to create a rule in the `forward` chain of the filter table which accepts the forwarded connections. Defaults to `true`",
+				Description:         "Whether to create a rule in the 'forward' chain of the filter table which accepts the forwarded connections. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"allow_before": schema.StringAttribute{
+				MarkdownDescription: "Filter rule which the accepting rule is kept in front of, referenced either by its ID or by its comment, e.g. `comment:drop all else`. If unset, the accepting rule is kept in front of all other rules of the `forward` chain",
+				Description:         "Filter rule which the accepting rule is kept in front of, referenced either by its ID or by its comment, e.g. 'comment:drop all else'. If unset, the accepting rule is kept in front of all other rules of the 'forward' chain",
+				Optional:            true,
+			},
+			"dstnat_rule_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RouterOS ID of the `dst-nat` rule",
+				Description:         "RouterOS ID of the 'dst-nat' rule",
+			},
+			"filter_rule_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "RouterOS ID of the accepting filter rule, if `allow_forward` is set",
+				Description:         "RouterOS ID of the accepting filter rule, if 'allow_forward' is set",
+			},
+			"ordered": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether all rules exist and are placed as configured. A value of `false` indicates drift, which is corrected on the next apply",
+				Description:         "Whether all rules exist and are placed as configured. A value of 'false' indicates drift, which is corrected on the next apply",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *PortForwardResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PortForwardResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dstnatID, filterID, err := r.applyRules(ctx, &data, "", "")
+	if err == nil {
+		err = r.place(ctx, &data, dstnatID, filterID)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to create port forward"))
+		for _, rule := range []struct{ ruleType, id string }{{"nat", dstnatID}, {"filter", filterID}} {
+			if rule.id == "" {
+				continue
+			}
+			if err := r.client.DeleteRule(ctx, rule.ruleType, rule.id); err != nil && !client.IsNotFound(err) {
+				resp.Diagnostics.AddWarning("Incomplete Cleanup", fmt.Sprintf("The rule '%s' of the port forward could not be removed, got error: %s", rule.id, err))
+			}
+		}
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("nat:%s", dstnatID))
+	data.setRuleIDs(dstnatID, filterID)
+	data.Ordered = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PortForwardResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PortForwardResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dstnat, err := r.client.GetRuleProperties(ctx, "nat", data.DstNATRuleID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read port forward"))
+		return
+	}
+
+	data.ExternalPort = types.StringValue(dstnat["dst-port"])
+	data.Protocol = types.StringValue(dstnat["protocol"])
+	data.InternalAddress = types.StringValue(dstnat["to-addresses"])
+	if !data.InternalPort.IsNull() || dstnat["to-ports"] != dstnat["dst-port"] {
+		data.InternalPort = stringOrNull(dstnat["to-ports"])
+	}
+	data.InInterfaceList = stringOrNull(dstnat["in-interface-list"])
+	data.DstAddress = stringOrNull(dstnat["dst-address"])
+	data.Comment = stringOrNull(dstnat["comment"])
+
+	// A filter rule which was removed from the device is created again on the
+	// next apply, which is signaled through ordered.
+	ordered := true
+	if data.AllowForward.ValueBool() {
+		_, err := r.client.GetRuleProperties(ctx, "filter", data.FilterRuleID.ValueString())
+		if err != nil && !client.IsNotFound(err) {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read port forward"))
+			return
+		}
+		ordered = err == nil
+	}
+
+	if ordered {
+		ordered, err = r.isPlaced(ctx, &data, data.DstNATRuleID.ValueString(), data.FilterRuleID.ValueString())
+		if err != nil {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read port forward"))
+			return
+		}
+	}
+	data.Ordered = types.BoolValue(ordered)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PortForwardResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state PortForwardResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dstnatID, filterID, err := r.applyRules(ctx, &data, state.DstNATRuleID.ValueString(), state.FilterRuleID.ValueString())
+	if err == nil {
+		err = r.place(ctx, &data, dstnatID, filterID)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to update port forward"))
+		return
+	}
+
+	data.setRuleIDs(dstnatID, filterID)
+	data.Ordered = types.BoolValue(true)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PortForwardResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PortForwardResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if id := data.FilterRuleID.ValueString(); id != "" {
+		if err := r.client.DeleteRule(ctx, "filter", id); err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(clientError(err, "Unable to delete port forward"))
+			return
+		}
+	}
+	if err := r.client.DeleteRule(ctx, "nat", data.DstNATRuleID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(clientError(err, "Unable to delete port forward"))
+	}
+}
+
+// applyRules sets the properties of the dst-nat rule and of the filter rule,
+// creating rules which do not exist (yet) and removing the filter rule if it
+// is no longer wanted. It returns the IDs of both rules, the ID of the filter
+// rule is empty if there is none.
+func (r *PortForwardResource) applyRules(ctx context.Context, data *PortForwardResourceModel, dstnatID, filterID string) (string, string, error) {
+	dstnatID, err := upsertRule(ctx, r.client, "nat", dstnatID, data.dstNATProperties())
+	if err != nil {
+		return dstnatID, filterID, err
+	}
+
+	if !data.AllowForward.ValueBool() {
+		if filterID != "" {
+			if err := r.client.DeleteRule(ctx, "filter", filterID); err != nil && !client.IsNotFound(err) {
+				return dstnatID, filterID, err
+			}
+		}
+		return dstnatID, "", nil
+	}
+
+	filterID, err = upsertRule(ctx, r.client, "filter", filterID, data.filterProperties())
+	return dstnatID, filterID, err
+}
+
+// place moves the rules to where they are configured to be, see isPlaced.
+func (r *PortForwardResource) place(ctx context.Context, data *PortForwardResourceModel, dstnatID, filterID string) error {
+	if err := keepFirstOfChain(ctx, r.client, "nat", "dstnat", dstnatID); err != nil {
+		return err
+	}
+	if filterID == "" {
+		return nil
+	}
+
+	if data.AllowBefore.IsNull() {
+		return keepFirstOfChain(ctx, r.client, "filter", "forward", filterID)
+	}
+	placed, target, err := r.isBefore(ctx, filterID, data.AllowBefore.ValueString())
+	if err != nil || placed {
+		return err
+	}
+	return r.client.MoveRules(ctx, "filter", []string{filterID}, client.Before(target))
+}
+
+// isPlaced reports whether the dst-nat rule is the first rule of its chain and
+// the filter rule, if any, is in front of the rule referenced by
+// allow_before, or the first rule of its chain otherwise.
+func (r *PortForwardResource) isPlaced(ctx context.Context, data *PortForwardResourceModel, dstnatID, filterID string) (bool, error) {
+	first, err := isFirstOfChain(ctx, r.client, "nat", "dstnat", dstnatID)
+	if err != nil || !first || filterID == "" {
+		return first, err
+	}
+
+	if data.AllowBefore.IsNull() {
+		return isFirstOfChain(ctx, r.client, "filter", "forward", filterID)
+	}
+	placed, _, err := r.isBefore(ctx, filterID, data.AllowBefore.ValueString())
+	return placed, err
+}
+
+// isBefore reports whether the filter rule with the given ID is placed in
+// front of the rule referenced by ref, and returns the ID of the latter.
+func (r *PortForwardResource) isBefore(ctx context.Context, id, ref string) (bool, string, error) {
+	target, err := r.client.ResolveRuleReference(ctx, "filter", ref)
+	if err != nil {
+		return false, "", fmt.Errorf("unable to resolve allow_before '%s': %w", ref, err)
+	}
+
+	rules, err := r.client.GetRulesOfType(ctx, "filter")
+	if err != nil {
+		return false, target.ID, err
+	}
+	for _, rule := range rules {
+		switch rule.ID {
+		case id:
+			return true, target.ID, nil
+		case target.ID:
+			return false, target.ID, nil
+		}
+	}
+	return false, target.ID, nil
+}
+
+// upsertRule updates the rule with the given ID, or creates it if it does not
+// exist (yet). Empty properties clear the property of an existing rule and are
+// omitted for new rules. It returns the ID of the rule.
+func upsertRule(ctx context.Context, c client.API, ruleType, id string, props map[string]string) (string, error) {
+	if id != "" {
+		_, err := c.UpdateRule(ctx, ruleType, id, props)
+		if err == nil || !client.IsNotFound(err) {
+			return id, err
+		}
+	}
+
+	set := make(map[string]string, len(props))
+	for k, v := range props {
+		if v != "" {
+			set[k] = v
+		}
+	}
+	created, err := c.CreateRule(ctx, ruleType, set)
+	return created[".id"], err
+}
+
+func (m *PortForwardResourceModel) setRuleIDs(dstnatID, filterID string) {
+	m.DstNATRuleID = types.StringValue(dstnatID)
+	m.FilterRuleID = types.StringNull()
+	if filterID != "" {
+		m.FilterRuleID = types.StringValue(filterID)
+	}
+}
+
+// internalPort returns the port of the internal server.
+func (m *PortForwardResourceModel) internalPort() string {
+	if m.InternalPort.IsNull() {
+		return m.ExternalPort.ValueString()
+	}
+	return m.InternalPort.ValueString()
+}
+
+// dstNATProperties returns the properties of the rule which forwards
+// connections to the internal server.
+func (m *PortForwardResourceModel) dstNATProperties() map[string]string {
+	return map[string]string{
+		"chain":             "dstnat",
+		"action":            "dst-nat",
+		"protocol":          m.Protocol.ValueString(),
+		"dst-port":          m.ExternalPort.ValueString(),
+		"dst-address":       m.DstAddress.ValueString(),
+		"in-interface-list": m.InInterfaceList.ValueString(),
+		"to-addresses":      m.InternalAddress.ValueString(),
+		"to-ports":          m.internalPort(),
+		"comment":           m.Comment.ValueString(),
+	}
+}
+
+// filterProperties returns the properties of the rule which accepts the
+// forwarded connections. Only connections which were actually forwarded by
+// dst-nat are matched, so the server is not exposed otherwise.
+func (m *PortForwardResourceModel) filterProperties() map[string]string {
+	return map[string]string{
+		"chain":                "forward",
+		"action":               "accept",
+		"connection-nat-state": "dstnat",
+		"protocol":             m.Protocol.ValueString(),
+		"dst-address":          m.InternalAddress.ValueString(),
+		"dst-port":             m.internalPort(),
+		"in-interface-list":    m.InInterfaceList.ValueString(),
+		"comment":              m.Comment.ValueString(),
+	}
+}
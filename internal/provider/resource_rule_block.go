@@ -0,0 +1,428 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &RuleBlockResource{}
+
+func NewRuleBlockResource() resource.Resource {
+	return &RuleBlockResource{}
+}
+
+// RuleBlockResource defines the resource implementation.
+type RuleBlockResource struct {
+	client client.API
+}
+
+// RuleBlockResourceModel describes the resource data model.
+type RuleBlockResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	RuleType types.String `tfsdk:"rule_type"`
+	Rules    types.List   `tfsdk:"rules"`
+	RuleIDs  types.List   `tfsdk:"rule_ids"`
+	Ordered  types.Bool   `tfsdk:"ordered"`
+}
+
+// ruleBlockRuleModel describes a single rule of a block.
+type ruleBlockRuleModel struct {
+	Chain      types.String `tfsdk:"chain"`
+	Action     types.String `tfsdk:"action"`
+	Comment    types.String `tfsdk:"comment"`
+	Disabled   types.Bool   `tfsdk:"disabled"`
+	Properties types.Map    `tfsdk:"properties"`
+}
+
+var ruleBlockRuleAttrTypes = map[string]attr.Type{
+	"chain":      types.StringType,
+	"action":     types.StringType,
+	"comment":    types.StringType,
+	"disabled":   types.BoolType,
+	"properties": types.MapType{ElemType: types.StringType},
+}
+
+func (r *RuleBlockResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_block"
+}
+
+func (r *RuleBlockResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *RuleBlockResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Block of firewall rules which are created and kept directly adjacent to each other in the configured order. If the block is out of order, it is moved to the end of the table as a whole, use the `rule_ordering` resource to arrange it relative to other rules by passing the elements of `rule_ids` as the `ref` of its `rules`",
+		Description:         "Block of firewall rules which are created and kept directly adjacent to each other in the configured order. If the block is out of order, it is moved to the end of the table as a whole, use the 'rule_ordering' resource to arrange it relative to other rules by passing the elements of 'rule_ids' as the 'ref' of its 'rules'",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "The rule type to create the rules in",
+				Description:         "The rule type to create the rules in",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(client.RuleTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "Rules of the block in their desired order",
+				Description:         "Rules of the block in their desired order",
+				Required:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"chain": schema.StringAttribute{
+							MarkdownDescription: "Chain the rule belongs to",
+							Description:         "Chain the rule belongs to",
+							Required:            true,
+						},
+						"action": schema.StringAttribute{
+							MarkdownDescription: "Action to take if a packet matches the rule, e.g. `accept`",
+							Description:         "Action to take if a packet matches the rule, e.g. 'accept'",
+							Required:            true,
+						},
+						"comment": schema.StringAttribute{
+							MarkdownDescription: "Comment attached to the rule",
+							Description:         "Comment attached to the rule",
+							Optional:            true,
+						},
+						"disabled": schema.BoolAttribute{
+							MarkdownDescription: "Whether the rule is disabled. Defaults to `false`",
+							Description:         "Whether the rule is disabled. Defaults to 'false'",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+						"properties": schema.MapAttribute{
+							ElementType:         types.StringType,
+							MarkdownDescription: "Further RouterOS properties of the rule, e.g. `{ \"dst-port\" = \"22\", \"protocol\" = \"tcp\" }`",
+							Description:         "Further RouterOS properties of the rule, e.g. '{ \"dst-port\" = \"22\", \"protocol\" = \"tcp\" }'",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"rule_ids": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "RouterOS IDs of the rules, in the same order as `rules`",
+				Description:         "RouterOS IDs of the rules, in the same order as 'rules'",
+			},
+			"ordered": schema.BoolAttribute{
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether the rules are directly adjacent to each other in the configured order. A value of `false` indicates drift, which is corrected on the next apply",
+				Description:         "Whether the rules are directly adjacent to each other in the configured order. A value of 'false' indicates drift, which is corrected on the next apply",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RuleBlockResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data RuleBlockResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules, diags := data.rules(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		props, diags := rule.properties(ctx, nil)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		created, err := r.client.CreateRule(ctx, ruleType, props)
+		if err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to create rule block"))
+			r.cleanup(ctx, ruleType, ids, &resp.Diagnostics)
+			return
+		}
+		ids = append(ids, created[".id"])
+	}
+
+	if _, err := r.client.OrderRules(ctx, ruleType, ids, client.OrderingOpts{Strict: true}); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to order rule block"))
+		r.cleanup(ctx, ruleType, ids, &resp.Diagnostics)
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s:%s", ruleType, ids[0]))
+	data.Ordered = types.BoolValue(true)
+	data.RuleIDs, diags = types.ListValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleBlockResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data RuleBlockResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules, diags := data.rules(ctx)
+	resp.Diagnostics.Append(diags...)
+	ids := make([]string, 0, len(data.RuleIDs.Elements()))
+	resp.Diagnostics.Append(data.RuleIDs.ElementsAs(ctx, &ids, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+
+	// Rules which were removed from the device are dropped from state, which
+	// shows up as a diff and causes them to be created again.
+	found := make([]ruleBlockRuleModel, 0, len(ids))
+	foundIDs := make([]string, 0, len(ids))
+	for i, id := range ids {
+		props, err := r.client.GetRuleProperties(ctx, ruleType, id)
+		if client.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read rule block"))
+			return
+		}
+
+		rule := ruleBlockRuleModel{
+			Chain:    types.StringValue(props["chain"]),
+			Action:   types.StringValue(props["action"]),
+			Comment:  stringOrNull(props["comment"]),
+			Disabled: types.BoolValue(props["disabled"] == "true"),
+		}
+		if i < len(rules) {
+			rule.Properties, diags = managedProperties(ctx, rules[i].Properties, props)
+			resp.Diagnostics.Append(diags...)
+		} else {
+			rule.Properties = types.MapNull(types.StringType)
+		}
+		found = append(found, rule)
+		foundIDs = append(foundIDs, id)
+	}
+
+	if len(foundIDs) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	seq := make([]client.FirewallRule, 0, len(foundIDs))
+	for _, id := range foundIDs {
+		seq = append(seq, client.FirewallRule{ID: id})
+	}
+	ordered, err := r.client.RuleOrderExists(ctx, ruleType, seq, client.OrderingOpts{Strict: true})
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read rule block"))
+		return
+	}
+	data.Ordered = types.BoolValue(ordered)
+
+	data.Rules, diags = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ruleBlockRuleAttrTypes}, found)
+	resp.Diagnostics.Append(diags...)
+	data.RuleIDs, diags = types.ListValueFrom(ctx, types.StringType, foundIDs)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleBlockResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state RuleBlockResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rules, diags := data.rules(ctx)
+	resp.Diagnostics.Append(diags...)
+	previous, diags := state.rules(ctx)
+	resp.Diagnostics.Append(diags...)
+	oldIDs := make([]string, 0, len(state.RuleIDs.Elements()))
+	resp.Diagnostics.Append(state.RuleIDs.ElementsAs(ctx, &oldIDs, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ruleType := data.RuleType.ValueString()
+
+	// Rules are matched by their position within the block, existing rules
+	// are updated in place and surplus rules are created or deleted.
+	ids := make([]string, 0, len(rules))
+	for i, rule := range rules {
+		var old *ruleBlockRuleModel
+		if i < len(previous) {
+			old = &previous[i]
+		}
+		props, diags := rule.properties(ctx, old)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var err error
+		if i < len(oldIDs) {
+			_, err = r.client.UpdateRule(ctx, ruleType, oldIDs[i], props)
+			if err == nil {
+				ids = append(ids, oldIDs[i])
+				continue
+			}
+		}
+		if err == nil || client.IsNotFound(err) {
+			var created map[string]string
+			created, err = r.client.CreateRule(ctx, ruleType, props)
+			if err == nil {
+				ids = append(ids, created[".id"])
+				continue
+			}
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to update rule block"))
+		return
+	}
+
+	for i := len(rules); i < len(oldIDs); i++ {
+		err := r.client.DeleteRule(ctx, ruleType, oldIDs[i])
+		if err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(clientError(err, "Unable to update rule block"))
+			return
+		}
+	}
+
+	if _, err := r.client.OrderRules(ctx, ruleType, ids, client.OrderingOpts{Strict: true}); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to order rule block"))
+		return
+	}
+
+	data.Ordered = types.BoolValue(true)
+	data.RuleIDs, diags = types.ListValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleBlockResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data RuleBlockResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := make([]string, 0, len(data.RuleIDs.Elements()))
+	resp.Diagnostics.Append(data.RuleIDs.ElementsAs(ctx, &ids, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, id := range ids {
+		err := r.client.DeleteRule(ctx, data.RuleType.ValueString(), id)
+		if err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(clientError(err, "Unable to delete rule block"))
+			return
+		}
+	}
+}
+
+// cleanup removes the rules of a block which could not be created completely.
+func (r *RuleBlockResource) cleanup(ctx context.Context, ruleType string, ids []string, diags *diag.Diagnostics) {
+	for _, id := range ids {
+		if err := r.client.DeleteRule(ctx, ruleType, id); err != nil && !client.IsNotFound(err) {
+			diags.AddWarning("Incomplete Cleanup", fmt.Sprintf("The rule '%s' of the block could not be removed, got error: %s", id, err))
+		}
+	}
+}
+
+func (m *RuleBlockResourceModel) rules(ctx context.Context) ([]ruleBlockRuleModel, diag.Diagnostics) {
+	rules := make([]ruleBlockRuleModel, 0, len(m.Rules.Elements()))
+	diags := m.Rules.ElementsAs(ctx, &rules, false)
+	return rules, diags
+}
+
+// properties returns the RouterOS properties of the rule. Properties of
+// previous which are no longer configured are cleared.
+func (m *ruleBlockRuleModel) properties(ctx context.Context, previous *ruleBlockRuleModel) (map[string]string, diag.Diagnostics) {
+	old := types.MapNull(types.StringType)
+	if previous != nil {
+		old = previous.Properties
+	}
+
+	props, diags := propertiesFromMap(ctx, m.Properties, old)
+	props["chain"] = m.Chain.ValueString()
+	props["action"] = m.Action.ValueString()
+	props["comment"] = m.Comment.ValueString()
+	props["disabled"] = strconv.FormatBool(m.Disabled.ValueBool())
+	return props, diags
+}
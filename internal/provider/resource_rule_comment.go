@@ -0,0 +1,263 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &RuleCommentResource{}
+
+func NewRuleCommentResource() resource.Resource {
+	return &RuleCommentResource{}
+}
+
+// RuleCommentResource defines the resource implementation.
+type RuleCommentResource struct {
+	client client.API
+}
+
+// RuleCommentResourceModel describes the resource data model.
+type RuleCommentResourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	RuleType         types.String `tfsdk:"rule_type"`
+	Match            types.Map    `tfsdk:"match"`
+	Comment          types.String `tfsdk:"comment"`
+	RestoreOnDestroy types.Bool   `tfsdk:"restore_on_destroy"`
+	InitialComment   types.String `tfsdk:"initial_comment"`
+	RuleID           types.String `tfsdk:"rule_id"`
+}
+
+func (r *RuleCommentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_comment"
+}
+
+func (r *RuleCommentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *RuleCommentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Comment of an existing rule, selected by its properties, e.g. to label rules which predate Terraform with stable identifiers which other resources can reference as `comment:<comment>`. Only the comment is managed, the rule itself is left as it is",
+		Description:         "Comment of an existing rule, selected by its properties, e.g. to label rules which predate Terraform with stable identifiers which other resources can reference as 'comment:<comment>'. Only the comment is managed, the rule itself is left as it is",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "Rule table of the rule, one of `filter`, `nat`, `mangle`, `raw`, `bridge-filter` or `bridge-nat`. Defaults to `filter`",
+				Description:         "Rule table of the rule, one of 'filter', 'nat', 'mangle', 'raw', 'bridge-filter' or 'bridge-nat'. Defaults to 'filter'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("filter"),
+				Validators: []validator.String{
+					stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"match": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "RouterOS properties which select the rule, e.g. `{ chain = \"forward\", action = \"drop\", src-address = \"10.0.0.0/8\" }`. Values must equal those reported by RouterOS, and exactly one rule must match. The rule is selected once when the resource is created",
+				Description:         "RouterOS properties which select the rule, e.g. '{ chain = \"forward\", action = \"drop\", src-address = \"10.0.0.0/8\" }'. Values must equal those reported by RouterOS, and exactly one rule must match. The rule is selected once when the resource is created",
+				Required:            true,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					// the comment is about to change, and IDs are not content
+					mapvalidator.KeysAre(stringvalidator.NoneOf("comment", ".id")),
+				},
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"comment": schema.StringAttribute{
+				MarkdownDescription: "Comment to set on the rule",
+				Description:         "Comment to set on the rule",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"restore_on_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Whether destroying the resource sets the comment of the rule back to `initial_comment`. Otherwise, the comment is kept. Defaults to `true`",
+				Description:         "Whether destroying the resource sets the comment of the rule back to 'initial_comment'. Otherwise, the comment is kept. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"initial_comment": schema.StringAttribute{
+				MarkdownDescription: "Comment of the rule before the resource was created, null if it had none",
+				Description:         "Comment of the rule before the resource was created, null if it had none",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rule_id": schema.StringAttribute{
+				MarkdownDescription: "RouterOS ID of the rule",
+				Description:         "RouterOS ID of the rule",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RuleCommentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data RuleCommentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	match := map[string]string{}
+	resp.Diagnostics.Append(data.Match.ElementsAs(ctx, &match, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	props, err := r.client.FindRuleByProperties(ctx, data.RuleType.ValueString(), match)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("match"),
+			"Unknown Rule",
+			fmt.Sprintf("Unable to find %s rule, got error: %s", data.RuleType.ValueString(), err),
+		)
+		return
+	}
+
+	data.RuleID = types.StringValue(props[".id"])
+	data.InitialComment = stringOrNull(props["comment"])
+	data.ID = types.StringValue(fmt.Sprintf("%s:%s", data.RuleType.ValueString(), props[".id"]))
+
+	if err := r.setComment(ctx, &data, data.Comment.ValueString()); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to set comment of rule"))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleCommentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data RuleCommentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	props, err := r.client.GetRuleProperties(ctx, data.RuleType.ValueString(), data.RuleID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read comment of rule"))
+		return
+	}
+
+	data.Comment = types.StringValue(props["comment"])
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleCommentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data RuleCommentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.setComment(ctx, &data, data.Comment.ValueString()); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to set comment of rule"))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleCommentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data RuleCommentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.RestoreOnDestroy.ValueBool() {
+		return
+	}
+
+	err := r.setComment(ctx, &data, data.InitialComment.ValueString())
+	if client.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to restore comment of rule"))
+	}
+}
+
+// setComment sets the comment of the labeled rule. The comment is set as-is,
+// without the workspace tag of comments of rules created by the provider, so
+// that the rule is not taken for one managed by this workspace.
+func (r *RuleCommentResource) setComment(ctx context.Context, data *RuleCommentResourceModel, comment string) error {
+	return r.client.SetRules(ctx, data.RuleType.ValueString(), []string{data.RuleID.ValueString()}, map[string]string{
+		"comment": comment,
+	})
+}
@@ -20,24 +20,49 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// commentReferenceRegexp matches references to rules by their comment,
+// optionally restricted to a chain, see client.ParseCommentReference.
+var commentReferenceRegexp = regexp.MustCompile("^(" + client.ChainReferencePrefix + "[^,]+,)?" + client.CommentReferencePrefix + ".+")
 
-	"github.com/google/uuid"
+// Values of the `on_unmanaged` attribute of rule_ordering.
+const (
+	onUnmanagedIgnore    = "ignore"
+	onUnmanagedWarn      = "warn"
+	onUnmanagedMoveAfter = "move_after"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &FirewallRuleOrderingResource{}
+var _ resource.ResourceWithModifyPlan = &FirewallRuleOrderingResource{}
+var _ resource.ResourceWithConfigValidators = &FirewallRuleOrderingResource{}
+var _ resource.ResourceWithImportState = &FirewallRuleOrderingResource{}
 
 func NewFirewallRuleOrderingResource() resource.Resource {
 	return &FirewallRuleOrderingResource{}
@@ -45,14 +70,49 @@ func NewFirewallRuleOrderingResource() resource.Resource {
 
 // FirewallRuleOrderingResource defines the resource implementation.
 type FirewallRuleOrderingResource struct {
-	client *client.Client
+	client client.API
 }
 
 // FirewallRuleOrderingResourceModel describes the resource data model.
 type FirewallRuleOrderingResourceModel struct {
-	RuleType types.String `tfsdk:"rule_type"`
-	Rules    types.List   `tfsdk:"rules"`
-	ID       types.String `tfsdk:"id"`
+	Host              types.String `tfsdk:"host"`
+	RuleType          types.String `tfsdk:"rule_type"`
+	Chain             types.String `tfsdk:"chain"`
+	Strict            types.Bool   `tfsdk:"strict"`
+	OnUnmanaged       types.String `tfsdk:"on_unmanaged"`
+	IgnoreDynamic     types.Bool   `tfsdk:"ignore_dynamic"`
+	IgnoreDisabled    types.Bool   `tfsdk:"ignore_disabled"`
+	RestoreOnDestroy  types.Bool   `tfsdk:"restore_on_destroy"`
+	MatchBy           types.String `tfsdk:"match_by"`
+	Rules             types.List   `tfsdk:"rules"`
+	RuleResources     types.List   `tfsdk:"rule_resources"`
+	Name              types.String `tfsdk:"name"`
+	ID                types.String `tfsdk:"id"`
+	LastApplyMoves    types.Int64  `tfsdk:"last_apply_moves"`
+	LastApplyDuration types.String `tfsdk:"last_apply_duration"`
+	Positions         types.Map    `tfsdk:"positions"`
+	PlannedMoves      types.List   `tfsdk:"planned_moves"`
+	Timeouts          types.Object `tfsdk:"timeouts"`
+}
+
+// ruleEntryModel describes a single element of rules.
+type ruleEntryModel struct {
+	Ref      types.String `tfsdk:"ref"`
+	Optional types.Bool   `tfsdk:"optional"`
+}
+
+var ruleEntryAttrTypes = map[string]attr.Type{
+	"ref":      types.StringType,
+	"optional": types.BoolType,
+}
+
+// ruleResourceModel describes a single element of rule_resources.
+type ruleResourceModel struct {
+	ID types.String `tfsdk:"id"`
+}
+
+var ruleResourceAttrTypes = map[string]attr.Type{
+	"id": types.StringType,
 }
 
 func (r *FirewallRuleOrderingResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -63,48 +123,208 @@ func (r *FirewallRuleOrderingResource) Configure(ctx context.Context, req resour
 	if req.ProviderData == nil {
 		return
 	}
-	client, ok := req.ProviderData.(*client.Client)
+	client, ok := req.ProviderData.(client.API)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 	r.client = client
 }
 
+// forHost returns a copy of r which operates on the device selected by host,
+// or nil if there is no such device.
+func (r *FirewallRuleOrderingResource) forHost(host types.String, diags *diag.Diagnostics) *FirewallRuleOrderingResource {
+	// the provider may not be configured yet while planning
+	if r.client == nil {
+		return r
+	}
+	c, err := hostClient(r.client, host)
+	if err != nil {
+		diags.AddAttributeError(path.Root("host"), "Unknown Host", err.Error())
+		return nil
+	}
+	return &FirewallRuleOrderingResource{client: c}
+}
+
 func (r *FirewallRuleOrderingResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             ruleOrderingSchemaVersion,
 		MarkdownDescription: "Firewall rule ordering",
 		Description:         "Firewall rule ordering",
 		Attributes: map[string]schema.Attribute{
+			"host": schema.StringAttribute{
+				MarkdownDescription: "Key of the device in the provider's `hosts` to apply the ordering to. If unset, the ordering is applied to the device configured by `hosturl`",
+				Description:         "Key of the device in the provider's 'hosts' to apply the ordering to. If unset, the ordering is applied to the device configured by 'hosturl'",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"rule_type": schema.StringAttribute{
-				MarkdownDescription: "The rule type to apply ordering to",
-				Description:         "The rule type to apply ordering to",
+				MarkdownDescription: "The rule type to apply ordering to. Either one of `filter`, `nat`, `mangle`, `raw`, `address-list` and `layer7-protocol`, which are tables below `/ip/firewall`, `bridge-filter` and `bridge-nat`, which are tables below `/interface/bridge`, or the menu path of any table with movable items, e.g. `/ipv6/firewall/filter`. All rules of a NAT table must belong to the same chain",
+				Description:         "The rule type to apply ordering to. Either one of 'filter', 'nat', 'mangle', 'raw', 'address-list' and 'layer7-protocol', which are tables below '/ip/firewall', 'bridge-filter' and 'bridge-nat', which are tables below '/interface/bridge', or the menu path of any table with movable items, e.g. '/ipv6/firewall/filter'. All rules of a NAT table must belong to the same chain",
 				Required:            true,
 				Validators: []validator.String{
-					stringvalidator.OneOf("filter", "nat", "mangle", "raw"),
+					stringvalidator.Any(
+						stringvalidator.OneOf(client.OrderableTables...),
+						stringvalidator.RegexMatches(client.MenuPathRegexp, "must be a RouterOS menu path such as '/ipv6/firewall/filter'"),
+					),
 				},
 			},
-			"rules": schema.ListAttribute{
-				ElementType:         types.StringType,
-				MarkdownDescription: "List of rules arranged in their desired order",
-				Description:         "List of rules arranged in their desired order",
-				Required:            true,
+			"chain": schema.StringAttribute{
+				MarkdownDescription: "Restricts the ordering to rules of this chain, e.g. `forward`. Rules of other chains are ignored when checking for drift, and all referenced rules must belong to this chain",
+				Description:         "Restricts the ordering to rules of this chain, e.g. 'forward'. Rules of other chains are ignored when checking for drift, and all referenced rules must belong to this chain",
+				Optional:            true,
+			},
+			"match_by": schema.StringAttribute{
+				MarkdownDescription: "How rules which are referenced by ID are found again after they were deleted and recreated with a new ID. Either `id`, which treats such rules as gone, `comment`, which looks for a rule with the comment the rule had before, or `content-hash`, which looks for a rule with the same properties. Defaults to `id`",
+				Description:         "How rules which are referenced by ID are found again after they were deleted and recreated with a new ID. Either 'id', which treats such rules as gone, 'comment', which looks for a rule with the comment the rule had before, or 'content-hash', which looks for a rule with the same properties. Defaults to 'id'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(matchByID),
+				Validators: []validator.String{
+					stringvalidator.OneOf(matchByID, matchByComment, matchByContentHash),
+				},
+			},
+			"strict": schema.BoolAttribute{
+				MarkdownDescription: "Whether the rules must be directly adjacent to each other. If disabled, other rules may be placed in between as long as the relative order of the listed rules is intact. Defaults to `true`",
+				Description:         "Whether the rules must be directly adjacent to each other. If disabled, other rules may be placed in between as long as the relative order of the listed rules is intact. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"on_unmanaged": schema.StringAttribute{
+				MarkdownDescription: "What to do about unmanaged rules which are found in between the listed rules of the same chain if `strict` is disabled. Either `ignore`, which leaves them be, `warn`, which reports them in a warning, or `move_after`, which moves them after the last listed rule so that they cannot take precedence over any of them. Has no effect if `strict` is enabled. Defaults to `ignore`",
+				Description:         "What to do about unmanaged rules which are found in between the listed rules of the same chain if 'strict' is disabled. Either 'ignore', which leaves them be, 'warn', which reports them in a warning, or 'move_after', which moves them after the last listed rule so that they cannot take precedence over any of them. Has no effect if 'strict' is enabled. Defaults to 'ignore'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(onUnmanagedIgnore),
+				Validators: []validator.String{
+					stringvalidator.OneOf(onUnmanagedIgnore, onUnmanagedWarn, onUnmanagedMoveAfter),
+				},
+			},
+			"ignore_dynamic": schema.BoolAttribute{
+				MarkdownDescription: "Whether to ignore dynamic rules, e.g. those added by UPnP, hotspot or hairpin NAT, when checking for drift. Dynamic rules cannot be moved, and would otherwise break the ordering whenever they appear in between the listed rules. Defaults to `false`",
+				Description:         "Whether to ignore dynamic rules, e.g. those added by UPnP, hotspot or hairpin NAT, when checking for drift. Dynamic rules cannot be moved, and would otherwise break the ordering whenever they appear in between the listed rules. Defaults to 'false'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"ignore_disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether to ignore disabled rules which are not part of `rules` when checking for drift, so that temporarily disabling a rule in between the listed rules does not cause them to be reordered. Defaults to `false`",
+				Description:         "Whether to ignore disabled rules which are not part of 'rules' when checking for drift, so that temporarily disabling a rule in between the listed rules does not cause them to be reordered. Defaults to 'false'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"restore_on_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Whether to restore the ordering which was in place before the rules were first reordered by this resource when it is destroyed. Rules which were created or deleted in the meantime are left alone. Defaults to `false`",
+				Description:         "Whether to restore the ordering which was in place before the rules were first reordered by this resource when it is destroyed. Rules which were created or deleted in the meantime are left alone. Defaults to 'false'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"rules": schema.ListNestedAttribute{
+				MarkdownDescription: "List of rules arranged in their desired order, e.g. `[{ ref = \"*1A\" }, { ref = \"comment:allow ssh\", optional = true }]`. Every rule may only be listed once. Exactly one of `rules` and `rule_resources` must be set",
+				Description:         "List of rules arranged in their desired order, e.g. '[{ ref = \"*1A\" }, { ref = \"comment:allow ssh\", optional = true }]'. Every rule may only be listed once. Exactly one of 'rules' and 'rule_resources' must be set",
+				Optional:            true,
+				Validators: []validator.List{
+					ruleRefsValidator{},
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"ref": schema.StringAttribute{
+							MarkdownDescription: "Reference to the rule, either its RouterOS ID, e.g. `*1A`, or its comment, e.g. `comment:allow ssh`. Prefixing the chain, e.g. `chain:input,comment:allow ssh`, only matches rules of that chain. The `rule_ref` data source builds these references. Comment references must match exactly one rule",
+							Description:         "Reference to the rule, either its RouterOS ID, e.g. '*1A', or its comment, e.g. 'comment:allow ssh'. Prefixing the chain, e.g. 'chain:input,comment:allow ssh', only matches rules of that chain. The 'rule_ref' data source builds these references. Comment references must match exactly one rule",
+							Required:            true,
+						},
+						"optional": schema.BoolAttribute{
+							MarkdownDescription: "Whether the rule may be missing on the device, e.g. because it is only present on some routers. Missing optional rules are skipped, and the remaining rules are ordered as if they were not listed, instead of failing the apply. Defaults to `false`",
+							Description:         "Whether the rule may be missing on the device, e.g. because it is only present on some routers. Missing optional rules are skipped, and the remaining rules are ordered as if they were not listed, instead of failing the apply. Defaults to 'false'",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+					},
+				},
+			},
+			"rule_resources": schema.ListNestedAttribute{
+				MarkdownDescription: "List of rule resources arranged in their desired order, e.g. `[routeros-firewall-list_mangle_rule.a, routeros-firewall-list_mangle_rule.b]`. Any object with an `id` attribute holding a RouterOS ID is accepted. Exactly one of `rules` and `rule_resources` must be set",
+				Description:         "List of rule resources arranged in their desired order, e.g. '[routeros-firewall-list_mangle_rule.a, routeros-firewall-list_mangle_rule.b]'. Any object with an 'id' attribute holding a RouterOS ID is accepted. Exactly one of 'rules' and 'rule_resources' must be set",
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "RouterOS ID of the rule",
+							Description:         "RouterOS ID of the rule",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(client.IDRegexp, "must be a RouterOS id of the form '*1A'"),
+							},
+						},
+					},
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the ordering which is used as its `id`. Must be unique among the orderings of a device. If unset, the `id` is derived from `rule_type`, `chain` and `rules` at creation time",
+				Description:         "Name of the ordering which is used as its 'id'. Must be unique among the orderings of a device. If unset, the 'id' is derived from 'rule_type', 'chain' and 'rules' at creation time",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"id": schema.StringAttribute{
 				Computed:            true,
-				Description:         "Identifier of resource",
-				MarkdownDescription: "Identifier of resource",
+				Description:         "Identifier of resource. Equal to 'name' if set, otherwise derived from the configuration at creation time",
+				MarkdownDescription: "Identifier of resource. Equal to `name` if set, otherwise derived from the configuration at creation time",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"last_apply_moves": schema.Int64Attribute{
+				Computed:            true,
+				Description:         "Number of move operations which were required to converge the ordering during the last apply",
+				MarkdownDescription: "Number of move operations which were required to converge the ordering during the last apply",
+			},
+			"planned_moves": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				Description:         "Move operations which are required to establish the ordering, in the order in which they are performed, e.g. '*A, *B after *C'. Rules which are already in place relative to each other are not moved. Empty if the ordering is in place, and unknown while planning if referenced rules do not exist yet",
+				MarkdownDescription: "Move operations which are required to establish the ordering, in the order in which they are performed, e.g. `*A, *B after *C`. Rules which are already in place relative to each other are not moved. Empty if the ordering is in place, and unknown while planning if referenced rules do not exist yet",
+			},
+			"positions": schema.MapAttribute{
+				ElementType:         types.Int64Type,
+				Computed:            true,
+				Description:         "Zero-based index of each managed rule within its chain, or within the entire table if 'chain' is unset, keyed by rule ID",
+				MarkdownDescription: "Zero-based index of each managed rule within its chain, or within the entire table if `chain` is unset, keyed by rule ID",
+			},
+			"last_apply_duration": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Wall time which was required to converge the ordering during the last apply, e.g. '1.5s'",
+				MarkdownDescription: "Wall time which was required to converge the ordering during the last apply, e.g. `1.5s`",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(),
 		},
 	}
 }
 
+func (r *FirewallRuleOrderingResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(path.MatchRoot("rules"), path.MatchRoot("rule_resources")),
+	}
+}
+
 func (r *FirewallRuleOrderingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data FirewallRuleOrderingResourceModel
 
@@ -113,12 +333,44 @@ func (r *FirewallRuleOrderingResource) Create(ctx context.Context, req resource.
 		return
 	}
 
-	resp.Diagnostics.Append(r.createOrdering(ctx, &data)...)
+	r = r.forHost(data.Host, &resp.Diagnostics)
+	if r == nil {
+		return
+	}
+
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, "create")
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	data.ID = types.StringValue(uuid.New().String())
+	if data.RestoreOnDestroy.ValueBool() {
+		resp.Diagnostics.Append(r.saveOriginalOrdering(ctx, data.RuleType.ValueString(), resp.Private)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.saveSnapshot(ctx, data.RuleType.ValueString(), resp.Private)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	identities := ruleIdentities{}
+	resp.Diagnostics.Append(r.createOrdering(ctx, &data, identities)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(identities.save(ctx, resp.Private)...)
+	resp.Diagnostics.Append(saveOwner(ctx, resp.Private, newOwnerToken())...)
+
+	id, diags := orderingID(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(id)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -131,23 +383,144 @@ func (r *FirewallRuleOrderingResource) Read(ctx context.Context, req resource.Re
 		return
 	}
 
-	rules, diags := r.rulesFromTerraformValue(ctx, &data)
+	r = r.forHost(data.Host, &resp.Diagnostics)
+	if r == nil {
+		return
+	}
+
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, "read")
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	refs, diags := data.ruleRefs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	optional, diags := data.optionalRefs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	identities, diags := loadRuleIdentities(ctx, req.Private)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	match, err := r.client.RuleOrderExists(data.RuleType.ValueString(), rules)
+	// Resolve references so that the ordering can be compared by ID, but keep
+	// track of how each rule was referenced in the configuration.
+	ids := make([]string, 0, len(refs))
+	refsByID := make(map[string]string, len(refs))
+	resolved := make(map[string]client.FirewallRule, len(refs))
+	skipped := map[string]bool{}
+	for _, ref := range refs {
+		rule, err := r.resolveRef(ctx, &data, identities, ref)
+		if optional[ref] && ruleMissing(err) {
+			skipped[ref] = true
+			continue
+		}
+		if ruleMissing(err) {
+			// the rule is gone, which shows up as a diff in the plan
+			continue
+		}
+		if err != nil {
+			if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+				return
+			}
+			resp.Diagnostics.AddError(clientError(err, "Unable to read ordering"))
+			return
+		}
+		ids = append(ids, rule.ID)
+		refsByID[rule.ID] = ref
+		resolved[ref] = rule
+	}
+
+	if err := r.recordIdentities(ctx, &data, identities, resolved); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read ordering"))
+		return
+	}
+	resp.Diagnostics.Append(identities.save(ctx, resp.Private)...)
+
+	// orderings created before owner tokens were introduced get one now
+	if owner, _ := resp.Private.GetKey(ctx, ownerKey); len(owner) == 0 {
+		resp.Diagnostics.Append(saveOwner(ctx, resp.Private, newOwnerToken())...)
+	}
+
+	rules, err := r.client.GetRulesOfChain(ctx, data.RuleType.ValueString(), data.Chain.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read ordering, got error: %s", err))
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read ordering"))
 		return
 	}
 
-	if !match {
-		// force recreation of entire ordering. A little blunt but does the job
-		data.Rules = types.ListNull(types.StringType)
-		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	strict := data.Strict.ValueBool()
+	if unmanaged := r.unmanagedRules(&data, ids, rules); len(unmanaged) > 0 {
+		switch data.OnUnmanaged.ValueString() {
+		case onUnmanagedWarn:
+			resp.Diagnostics.Append(unmanagedRulesWarning(unmanaged))
+		case onUnmanagedMoveAfter:
+			// include the unmanaged rules so that they show up as drift and
+			// are moved out of the way on the next apply
+			strict = true
+		}
+	}
+
+	observed := client.ObservedOrdering(ids, data.orderingOpts().Filter(rules, ids), strict)
+	for i, id := range observed {
+		if ref, ok := refsByID[id]; ok {
+			observed[i] = ref
+		}
+	}
+
+	// optional rules which are missing are not part of the ordering
+	expected := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if !skipped[ref] {
+			expected = append(expected, ref)
+		}
+	}
+
+	if !stringSlicesEqual(observed, expected) {
+		tflog.Debug(ctx, "Detected drift in rule ordering", map[string]interface{}{
+			"rule_type": data.RuleType.ValueString(),
+			"expected":  expected,
+			"actual":    observed,
+		})
+
+		// Refreshing must never change the device, the drift is corrected by
+		// the next apply and only reported here.
+		if r.client.ReportOrderingDrift() {
+			summary := r.driftSummary(ctx, &data, expected, observed)
+			tflog.Warn(ctx, "Detected drift in rule ordering", summary)
+			resp.Diagnostics.Append(driftWarning(summary))
+		}
 	}
+
+	// Store what is actually on the device so that the plan shows precisely
+	// which rules moved instead of replacing the entire list. Skipped rules
+	// are kept where they are configured, as they are not drift.
+	resp.Diagnostics.Append(data.setRuleRefs(ctx, withSkippedRefs(observed, refs, skipped))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Positions, diags = rulePositions(ctx, ids, rules)
+	resp.Diagnostics.Append(diags...)
+	data.PlannedMoves, diags = plannedMoves(ctx, &data, ids, rules)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *FirewallRuleOrderingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -158,60 +531,876 @@ func (r *FirewallRuleOrderingResource) Update(ctx context.Context, req resource.
 		return
 	}
 
-	resp.Diagnostics.Append(r.createOrdering(ctx, &data)...)
+	r = r.forHost(data.Host, &resp.Diagnostics)
+	if r == nil {
+		return
+	}
+
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, "update")
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the ordering captured at creation time, unless there is none yet
+	// because restoring was only enabled later on, or it belongs to another
+	// rule table.
+	if data.RestoreOnDestroy.ValueBool() {
+		original, diags := loadOriginalOrdering(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if original == nil || original.RuleType != data.RuleType.ValueString() {
+			resp.Diagnostics.Append(r.saveOriginalOrdering(ctx, data.RuleType.ValueString(), resp.Private)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+	}
+
+	identities, diags := loadRuleIdentities(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.saveSnapshot(ctx, data.RuleType.ValueString(), resp.Private)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// The prior state holds the ordering observed by the last refresh, so it
+	// differs from the plan if the ordering drifted.
+	if r.client.ReportOrderingDrift() {
+		var prior FirewallRuleOrderingResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+		observed, diags := prior.ruleRefs(ctx)
+		resp.Diagnostics.Append(diags...)
+		expected, diags := data.ruleRefs(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if prior.RuleType.Equal(data.RuleType) && !stringSlicesEqual(observed, expected) {
+			tflog.Warn(ctx, "Correcting drift in rule ordering", r.driftSummary(ctx, &data, expected, observed))
+		}
+	}
+
+	resp.Diagnostics.Append(r.createOrdering(ctx, &data, identities)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(identities.save(ctx, resp.Private)...)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// ModifyPlan ensures that no rule is managed by more than one ordering
+// resource of the same provider configuration.
+func (r *FirewallRuleOrderingResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var data FirewallRuleOrderingResourceModel
+
+	// Neither the ID nor the configuration is unique to a resource, e.g. if a
+	// resource was copied, so resources are told apart by their owner token.
+	owner, diags := orderingOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The rules and name claimed while planning previously may have changed
+	// since, or the ordering is about to be destroyed.
+	claimedRules.release(owner)
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Rules.IsUnknown() || data.RuleResources.IsUnknown() || data.RuleType.IsUnknown() || data.Host.IsUnknown() {
+		return
+	}
+
+	r = r.forHost(data.Host, &resp.Diagnostics)
+	if r == nil {
+		return
+	}
+
+	elems, diags := data.ruleValues(ctx)
+	resp.Diagnostics.Append(diags...)
+	optional, diags := data.optionalRefs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := make([]string, 0, len(elems))
+	optionalIDs := map[string]bool{}
+	for _, v := range elems {
+		// rule IDs which are only known after apply cannot be checked yet
+		if v.IsUnknown() {
+			continue
+		}
+		id := r.resolveForPlan(ctx, data.RuleType.ValueString(), v.ValueString())
+		ids = append(ids, id)
+		if optional[v.ValueString()] {
+			optionalIDs[id] = true
+		}
+	}
+
+	// The ID of new resources is derived from their configuration, so it can
+	// be shown in the plan already if everything it depends on is known.
+	if data.ID.IsUnknown() && len(ids) == len(elems) && !data.Chain.IsUnknown() && !data.Name.IsUnknown() {
+		id, diags := orderingID(ctx, &data)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.ID = types.StringValue(id)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), data.ID)...)
+	}
+
+	// Preview the moves of the apply, so that they can be reviewed as part of
+	// the plan. This is best effort, as rules may only be created during the
+	// same apply.
+	if len(ids) == len(elems) && !data.Chain.IsUnknown() && !data.Strict.IsUnknown() {
+		if moves, ok := r.previewMoves(ctx, &data, ids, optionalIDs); ok {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("planned_moves"), moves)...)
+		}
+	}
+
+	if !data.Name.IsUnknown() && !data.Name.IsNull() && claimedRules.claimName(r.client, data.Name.ValueString(), owner) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Duplicate Ordering Name",
+			fmt.Sprintf("The name '%s' is already used by another ordering resource. The name is used as the id of the ordering, so it must be unique.",
+				data.Name.ValueString()),
+		)
+	}
+
+	for _, id := range claimedRules.claim(r.client, data.RuleType.ValueString(), ids, owner) {
+		resp.Diagnostics.AddAttributeError(
+			data.rulesPath(),
+			"Conflicting Rule Ordering",
+			fmt.Sprintf("Rule '%s' of type '%s' is already part of another ordering resource. "+
+				"A rule may only be managed by a single ordering, otherwise both resources keep reordering it on every apply.",
+				id, data.RuleType.ValueString()),
+		)
+	}
+}
+
 // Delete removes the ordering lock.
 //
 // Note that since this is a pseudo-resource, no API call / further cleanup is
-// necessary upon deletion. This does imply, however, that original state (in
-// terms of the original rule ordering) is not restored. This is still
-// appropriate given that this "resource" is simply meant to represent a lock /
-// ordering guarantee between *two* firewall rules, and not some absolute
-// ordering of the entire chain.
+// necessary upon deletion by default. This does imply, however, that original
+// state (in terms of the original rule ordering) is not restored. This is
+// still appropriate given that this "resource" is simply meant to represent a
+// lock / ordering guarantee between *two* firewall rules, and not some
+// absolute ordering of the entire chain. If restore_on_destroy is set, the
+// managed rules are moved back to where they were before the resource was
+// created.
 func (r *FirewallRuleOrderingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data FirewallRuleOrderingResourceModel
+
+	// the rules may be taken over by another ordering of the same apply
+	owner, diags := orderingOwner(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	claimedRules.release(owner)
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || !data.RestoreOnDestroy.ValueBool() {
+		return
+	}
+
+	r = r.forHost(data.Host, &resp.Diagnostics)
+	if r == nil {
+		return
+	}
+
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, "delete")
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	original, diags := loadOriginalOrdering(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if original == nil || original.RuleType != data.RuleType.ValueString() {
+		resp.Diagnostics.AddWarning(
+			"Original Ordering Unavailable",
+			"The ordering which was in place before this resource was created has not been recorded, so it cannot be restored.",
+		)
+		return
+	}
+
+	refs, diags := data.ruleRefs(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	identities, diags := loadRuleIdentities(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	managed := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		rule, err := r.resolveRef(ctx, &data, identities, ref)
+		if ruleMissing(err) {
+			continue
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to restore ordering"))
+			return
+		}
+		managed[rule.ID] = true
+	}
+
+	current, err := r.client.GetRulesOfType(ctx, data.RuleType.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to restore ordering"))
+		return
+	}
+
+	for _, m := range restoreMoves(original.Rules, current, managed) {
+		if err := r.client.MoveRules(ctx, data.RuleType.ValueString(), m.ids, m.target); err != nil {
+			resp.Diagnostics.AddError(clientError(err, "Unable to restore ordering"))
+			return
+		}
+	}
+}
+
+// ImportState imports an ordering by its ID, see parseOrderingImportID. Only
+// the rule type and name are known afterwards, the rules are taken from the
+// configuration and put in order by the next apply.
+func (r *FirewallRuleOrderingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ruleType, name, err := parseOrderingImportID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Import ID", fmt.Sprintf("Unable to import ordering, %s", err))
+		return
+	}
+
+	id := req.ID
+	if name != "" {
+		id = name
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rule_type"), ruleType)...)
 }
 
 // createOrdering orders rules in accordance to the passed resource model. It
 // *does not* set or otherwise interact with state; this responsibility is left
-// to the caller.
-func (r *FirewallRuleOrderingResource) createOrdering(ctx context.Context, data *FirewallRuleOrderingResourceModel) (diags diag.Diagnostics) {
+// to the caller. The only fields of the model which are modified are the
+// convergence metrics and the resulting rule positions.
+func (r *FirewallRuleOrderingResource) createOrdering(ctx context.Context, data *FirewallRuleOrderingResourceModel, identities ruleIdentities) (diags diag.Diagnostics) {
 	var rules []client.FirewallRule
+	var moves int64
+	start := time.Now()
 
-	rules, err := r.rulesFromTerraformValue(ctx, data)
+	defer func() {
+		data.LastApplyMoves = types.Int64Value(moves)
+		data.LastApplyDuration = types.StringValue(time.Since(start).String())
+	}()
+
+	diags.Append(versionDiagnostics(ctx, r.client)...)
+
+	rules, err := r.rulesFromTerraformValue(ctx, data, identities)
 	diags.Append(err...)
 	if diags.HasError() {
 		return
 	}
 
-	if err := r.client.OrderRules(data.RuleType.ValueString(), rules...); err != nil {
-		diags.AddError("Client Error", fmt.Sprintf("Unable to create ordering, got error(s): %s", err))
+	if chain := data.Chain.ValueString(); chain != "" {
+		for _, rule := range rules {
+			if rule.Chain != chain {
+				diags.AddAttributeError(
+					data.rulesPath(),
+					"Rule Outside Of Chain",
+					fmt.Sprintf("Rule '%s' belongs to chain '%s', but the ordering is restricted to chain '%s'", rule.ID, rule.Chain, chain),
+				)
+			}
+		}
+		if diags.HasError() {
+			return
+		}
+	}
+
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+
+	n, e := r.client.OrderRules(ctx, data.RuleType.ValueString(), ids, data.orderingOpts())
+	moves = int64(n)
+	if e != nil {
+		diags.AddError(clientError(e, "Unable to create ordering"))
+		return
+	}
+
+	table, e := r.client.GetRulesOfChain(ctx, data.RuleType.ValueString(), data.Chain.ValueString())
+	if e != nil {
+		diags.AddError(clientError(e, "Unable to read ordering"))
+		return
+	}
+
+	if unmanaged := r.unmanagedRules(data, ids, table); len(unmanaged) > 0 {
+		switch data.OnUnmanaged.ValueString() {
+		case onUnmanagedWarn:
+			diags.Append(unmanagedRulesWarning(unmanaged))
+		case onUnmanagedMoveAfter:
+			if e := r.client.MoveRules(ctx, data.RuleType.ValueString(), unmanaged, client.After(ids[len(ids)-1])); e != nil {
+				diags.AddError(clientError(e, "Unable to move unmanaged rules"))
+				return
+			}
+			moves++
+			if table, e = r.client.GetRulesOfChain(ctx, data.RuleType.ValueString(), data.Chain.ValueString()); e != nil {
+				diags.AddError(clientError(e, "Unable to read ordering"))
+				return
+			}
+		}
+	}
+	var d diag.Diagnostics
+	data.Positions, d = rulePositions(ctx, ids, table)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	// The moves shown in the plan must be kept as-is. If they could not be
+	// determined while planning, nothing is left to be moved now.
+	if data.PlannedMoves.IsUnknown() {
+		data.PlannedMoves = types.ListValueMust(types.StringType, []attr.Value{})
+	}
+	if moves == 0 {
+		tflog.Debug(ctx, "Rule ordering already in place, skipping move", map[string]interface{}{
+			"rule_type": data.RuleType.ValueString(),
+		})
 	}
 
 	return
 }
 
+// driftSummary returns a structured summary of an ordering which drifted
+// from refs to observed, including who moved rules of the table according to
+// the system log, to help tracing out-of-band changes.
+func (r *FirewallRuleOrderingResource) driftSummary(ctx context.Context, data *FirewallRuleOrderingResourceModel, refs, observed []string) map[string]interface{} {
+	ruleType := data.RuleType.ValueString()
+
+	var outOfPlace []string
+	for i, ref := range refs {
+		if i >= len(observed) || observed[i] != ref {
+			outOfPlace = append(outOfPlace, ref)
+		}
+	}
+	fields := map[string]interface{}{
+		"rule_type":    ruleType,
+		"chain":        data.Chain.ValueString(),
+		"expected":     refs,
+		"actual":       observed,
+		"out_of_place": outOfPlace,
+	}
+
+	// the system log is only consulted for the summary, so failing to read
+	// it is not an error
+	moves, err := r.client.FindRuleMoves(ctx, ruleType)
+	if err != nil {
+		tflog.Debug(ctx, "Unable to read rule moves from the system log", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if len(moves) > 0 {
+		var users []string
+		seen := map[string]bool{}
+		for _, m := range moves {
+			if !seen[m.By] {
+				seen[m.By] = true
+				users = append(users, m.By)
+			}
+		}
+		fields["moved_by"] = users
+		fields["last_moved_at"] = moves[len(moves)-1].Time
+	}
+	return fields
+}
+
+// driftWarning returns a warning which reports the summary of an ordering
+// which drifted, see driftSummary.
+func driftWarning(fields map[string]interface{}) diag.Diagnostic {
+	detail := fmt.Sprintf("The %s rules %s are out of place, expected [%s], observed [%s].",
+		fields["rule_type"], strings.Join(fields["out_of_place"].([]string), ", "),
+		strings.Join(fields["expected"].([]string), ", "), strings.Join(fields["actual"].([]string), ", "))
+	if users, ok := fields["moved_by"].([]string); ok {
+		detail += fmt.Sprintf(" According to the system log, rules of the table were moved by %s, most recently at %s.",
+			strings.Join(users, ", "), fields["last_moved_at"])
+	}
+	detail += " The configured ordering is restored by the next apply."
+	return diag.NewWarningDiagnostic("Rule Ordering Drifted", detail)
+}
+
+// unmanagedRules returns the IDs of the unmanaged rules in between the rules
+// with the given IDs which on_unmanaged has to act upon. There are none in
+// strict mode, as such rules are moved out of the way by the ordering itself.
+func (r *FirewallRuleOrderingResource) unmanagedRules(data *FirewallRuleOrderingResourceModel, ids []string, rules []client.FirewallRule) []string {
+	if data.Strict.ValueBool() || data.OnUnmanaged.ValueString() == onUnmanagedIgnore || len(ids) == 0 {
+		return nil
+	}
+	return client.UnmanagedBetween(ids, data.orderingOpts().Filter(rules, ids))
+}
+
+// unmanagedRulesWarning reports unmanaged rules which were found in between
+// the rules of an ordering.
+func unmanagedRulesWarning(ids []string) diag.Diagnostic {
+	return diag.NewWarningDiagnostic(
+		"Unmanaged Rules Within Ordering",
+		fmt.Sprintf("The rules %s are not part of the ordering, but are placed in between its rules and therefore take precedence over some of them. Set on_unmanaged to \"move_after\" to move them after the ordering instead.", strings.Join(ids, ", ")),
+	)
+}
+
 // rulesFromTerraformValue converts Terraform's internal list representation to
-// a usable array of FirewallRules which the client can understand.
-func (r *FirewallRuleOrderingResource) rulesFromTerraformValue(ctx context.Context, data *FirewallRuleOrderingResourceModel) ([]client.FirewallRule, diag.Diagnostics) {
-	var rules []client.FirewallRule
+// a usable array of FirewallRules which the client can understand. The
+// identities of the resolved rules are recorded in identities. Optional rules
+// which are missing are skipped. If any other rule cannot be resolved, no
+// rules are returned, so that no partial ordering is applied, and a single
+// diagnostic lists every failed lookup.
+func (r *FirewallRuleOrderingResource) rulesFromTerraformValue(ctx context.Context, data *FirewallRuleOrderingResourceModel, identities ruleIdentities) ([]client.FirewallRule, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	arr := make([]types.String, 0, len(data.Rules.Elements()))
-	diags.Append(data.Rules.ElementsAs(ctx, &arr, false)...)
+	arr, d := data.ruleValues(ctx)
+	diags.Append(d...)
+	optional, d := data.optionalRefs(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
 
+	rules := make([]client.FirewallRule, 0, len(arr))
+	resolved := make(map[string]client.FirewallRule, len(arr))
+	var failures []string
 	for _, v := range arr {
-		rule, err := r.client.GetRule(data.RuleType.ValueString(), v.ValueString())
+		rule, err := r.resolveRef(ctx, data, identities, v.ValueString())
+		if optional[v.ValueString()] && ruleMissing(err) {
+			tflog.Debug(ctx, "Skipping missing optional rule", map[string]interface{}{
+				"rule_type": data.RuleType.ValueString(),
+				"reference": v.ValueString(),
+			})
+			continue
+		}
 		if err != nil {
-			diags.AddError("Client Error", fmt.Sprintf("Unable to create ordering, got error: %s", err))
+			failures = append(failures, fmt.Sprintf("- '%s': %s", v.ValueString(), err))
+			continue
 		}
 		rules = append(rules, rule)
+		resolved[v.ValueString()] = rule
+	}
+	if len(failures) > 0 {
+		diags.AddAttributeError(
+			data.rulesPath(),
+			"Unable To Resolve Rules",
+			fmt.Sprintf("Unable to create ordering, %d of %d rules could not be resolved, so no rule was moved:\n%s", len(failures), len(arr), strings.Join(failures, "\n")),
+		)
+		return nil, diags
 	}
 
+	if err := r.recordIdentities(ctx, data, identities, resolved); err != nil {
+		diags.AddError(clientError(err, "Unable to create ordering"))
+	}
 	return rules, diags
 }
+
+// previewMoves returns the moves which are needed to establish the ordering of
+// the rules with the given IDs, skipping missing rules whose IDs are listed in
+// optional. It reports false if the table cannot be read or not all other
+// rules exist yet.
+func (r *FirewallRuleOrderingResource) previewMoves(ctx context.Context, data *FirewallRuleOrderingResourceModel, ids []string, optional map[string]bool) (types.List, bool) {
+	if r.client == nil {
+		return types.ListUnknown(types.StringType), false
+	}
+
+	rules, err := r.client.GetRulesOfChain(ctx, data.RuleType.ValueString(), data.Chain.ValueString())
+	if err != nil {
+		return types.ListUnknown(types.StringType), false
+	}
+
+	exists := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		exists[rule.ID] = true
+	}
+	present := make([]string, 0, len(ids))
+	for _, id := range ids {
+		switch {
+		case exists[id]:
+			present = append(present, id)
+		case !optional[id]:
+			return types.ListUnknown(types.StringType), false
+		}
+	}
+
+	moves, diags := plannedMoves(ctx, data, present, rules)
+	return moves, !diags.HasError()
+}
+
+// resolveForPlan resolves comment references to rule IDs on a best-effort
+// basis. The referenced rule may well be created during the same apply, so
+// unresolvable references are returned as-is instead of failing the plan.
+func (r *FirewallRuleOrderingResource) resolveForPlan(ctx context.Context, ruleType, ref string) string {
+	if r.client == nil || !client.IsCommentReference(ref) {
+		return ref
+	}
+	rule, err := r.client.ResolveRuleReference(ctx, ruleType, ref)
+	if err != nil {
+		return ref
+	}
+	return rule.ID
+}
+
+// originalOrderingKey is the private state key under which the ordering of the
+// rule table prior to the first apply is stored.
+const originalOrderingKey = "original_ordering"
+
+// originalOrdering is the ordering of a rule table before it was modified.
+type originalOrdering struct {
+	RuleType string   `json:"rule_type"`
+	Rules    []string `json:"rules"`
+}
+
+// snapshotKey is the private state key under which the rule table as found
+// before the latest apply is stored.
+const snapshotKey = "pre_apply_snapshot"
+
+// tableSnapshot is the full ordering of a rule table at a point in time. It is
+// captured before every apply which may move rules, so that the table as it
+// was before Terraform touched it can be inspected, e.g. with `terraform
+// state pull`, when troubleshooting an unexpected ordering.
+type tableSnapshot struct {
+	RuleType   string         `json:"rule_type"`
+	CapturedAt string         `json:"captured_at"`
+	Rules      []snapshotRule `json:"rules"`
+}
+
+type snapshotRule struct {
+	ID      string `json:"id"`
+	Chain   string `json:"chain,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// privateState is implemented by the private state of all resource requests
+// and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// saveOriginalOrdering stores the current ordering of the given rule table in
+// private state.
+func (r *FirewallRuleOrderingResource) saveOriginalOrdering(ctx context.Context, ruleType string, private privateState) (diags diag.Diagnostics) {
+	rules, err := r.client.GetRulesOfType(ctx, ruleType)
+	if err != nil {
+		diags.AddError(clientError(err, "Unable to read ordering"))
+		return
+	}
+
+	original := originalOrdering{RuleType: ruleType, Rules: make([]string, 0, len(rules))}
+	for _, rule := range rules {
+		original.Rules = append(original.Rules, rule.ID)
+	}
+
+	b, err := json.Marshal(original)
+	if err != nil {
+		diags.AddError("Internal Error", fmt.Sprintf("Unable to encode original ordering, got error: %s", err))
+		return
+	}
+	return private.SetKey(ctx, originalOrderingKey, b)
+}
+
+// saveSnapshot stores the current ordering of the given rule table in private
+// state, replacing the snapshot of any previous apply, see tableSnapshot.
+func (r *FirewallRuleOrderingResource) saveSnapshot(ctx context.Context, ruleType string, private privateState) (diags diag.Diagnostics) {
+	rules, err := r.client.GetRulesOfType(ctx, ruleType)
+	if err != nil {
+		diags.AddError(clientError(err, "Unable to read ordering"))
+		return
+	}
+
+	snapshot := tableSnapshot{
+		RuleType:   ruleType,
+		CapturedAt: time.Now().UTC().Format(time.RFC3339),
+		Rules:      make([]snapshotRule, 0, len(rules)),
+	}
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		snapshot.Rules = append(snapshot.Rules, snapshotRule{ID: rule.ID, Chain: rule.Chain, Comment: rule.Comment})
+		ids = append(ids, rule.ID)
+	}
+	tflog.Debug(ctx, "Captured rule table before apply", map[string]interface{}{
+		"rule_type": ruleType,
+		"rules":     ids,
+	})
+
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		diags.AddError("Internal Error", fmt.Sprintf("Unable to encode snapshot, got error: %s", err))
+		return
+	}
+	return private.SetKey(ctx, snapshotKey, b)
+}
+
+// loadOriginalOrdering returns the ordering stored by saveOriginalOrdering, or
+// nil if there is none.
+func loadOriginalOrdering(ctx context.Context, private privateState) (*originalOrdering, diag.Diagnostics) {
+	b, diags := private.GetKey(ctx, originalOrderingKey)
+	if diags.HasError() || len(b) == 0 {
+		return nil, diags
+	}
+
+	var original originalOrdering
+	if err := json.Unmarshal(b, &original); err != nil {
+		diags.AddError("Internal Error", fmt.Sprintf("Unable to decode original ordering, got error: %s", err))
+		return nil, diags
+	}
+	return &original, diags
+}
+
+type restoreMove struct {
+	ids    []string
+	target client.Position
+}
+
+// restoreMoves returns the moves which put the managed rules back to their
+// position in the original ordering. Each managed rule is placed in front of
+// the unmanaged rule which originally followed it, so only managed rules are
+// moved. Rules which did not exist originally or no longer exist are skipped.
+func restoreMoves(original []string, current []client.FirewallRule, managed map[string]bool) []restoreMove {
+	exists := make(map[string]bool, len(current))
+	for _, rule := range current {
+		exists[rule.ID] = true
+	}
+
+	var moves []restoreMove
+	var group []string
+	for _, id := range original {
+		if !exists[id] {
+			continue
+		}
+		if managed[id] {
+			group = append(group, id)
+			continue
+		}
+		if len(group) > 0 {
+			moves = append(moves, restoreMove{ids: group, target: client.Before(id)})
+			group = nil
+		}
+	}
+	if len(group) > 0 {
+		moves = append(moves, restoreMove{ids: group, target: client.End})
+	}
+	return moves
+}
+
+// ruleMissing reports whether err indicates that a referenced rule does not
+// exist. Rules which exist in another table are not missing, but misplaced.
+func ruleMissing(err error) bool {
+	var mismatch *client.TableMismatchError
+	return errors.Is(err, client.ErrRuleNotFound) && !errors.As(err, &mismatch)
+}
+
+// withSkippedRefs returns observed with the skipped references of refs
+// inserted at their configured positions, so that the result equals refs if
+// the other rules are in place.
+func withSkippedRefs(observed, refs []string, skipped map[string]bool) []string {
+	result := append([]string{}, observed...)
+	for i, ref := range refs {
+		if !skipped[ref] {
+			continue
+		}
+		if i > len(result) {
+			i = len(result)
+		}
+		result = append(result[:i], append([]string{ref}, result[i:]...)...)
+	}
+	return result
+}
+
+// plannedMoves returns the descriptions of the moves which establish the
+// ordering of the rules with the given IDs within rules, see client.PlanMoves.
+func plannedMoves(ctx context.Context, data *FirewallRuleOrderingResourceModel, ids []string, rules []client.FirewallRule) (types.List, diag.Diagnostics) {
+	moves := client.PlanMoves(data.orderingOpts().Filter(rules, ids), ids, data.Strict.ValueBool())
+
+	descriptions := make([]string, 0, len(moves))
+	for _, m := range moves {
+		descriptions = append(descriptions, m.String())
+	}
+	return types.ListValueFrom(ctx, types.StringType, descriptions)
+}
+
+// rulePositions returns the index of each rule with the given IDs within
+// rules. Rules which are not part of rules are omitted.
+func rulePositions(ctx context.Context, ids []string, rules []client.FirewallRule) (types.Map, diag.Diagnostics) {
+	managed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+
+	positions := make(map[string]int64, len(ids))
+	for i, rule := range rules {
+		if managed[rule.ID] {
+			positions[rule.ID] = int64(i)
+		}
+	}
+	return types.MapValueFrom(ctx, types.Int64Type, positions)
+}
+
+// ruleValues returns the configured rule references, taken from either the
+// `ref` of the elements of rules or the `id` of those of rule_resources.
+// References which are only known after apply are returned as unknown values.
+func (m *FirewallRuleOrderingResourceModel) ruleValues(ctx context.Context) ([]types.String, diag.Diagnostics) {
+	list, key := m.Rules, "ref"
+	if !m.RuleResources.IsNull() {
+		list, key = m.RuleResources, "id"
+	}
+
+	objects := make([]types.Object, 0, len(list.Elements()))
+	diags := list.ElementsAs(ctx, &objects, false)
+	values := make([]types.String, 0, len(objects))
+	for _, o := range objects {
+		ref, ok := o.Attributes()[key].(types.String)
+		if o.IsUnknown() || !ok {
+			ref = types.StringUnknown()
+		}
+		values = append(values, ref)
+	}
+	return values, diags
+}
+
+// optionalRefs returns the references of the elements of rules which are
+// marked as optional.
+func (m *FirewallRuleOrderingResourceModel) optionalRefs(ctx context.Context) (map[string]bool, diag.Diagnostics) {
+	optional := map[string]bool{}
+	if m.Rules.IsNull() || m.Rules.IsUnknown() {
+		return optional, nil
+	}
+
+	objects := make([]types.Object, 0, len(m.Rules.Elements()))
+	diags := m.Rules.ElementsAs(ctx, &objects, false)
+	for _, o := range objects {
+		ref, _ := o.Attributes()["ref"].(types.String)
+		opt, _ := o.Attributes()["optional"].(types.Bool)
+		if !ref.IsUnknown() && opt.ValueBool() {
+			optional[ref.ValueString()] = true
+		}
+	}
+	return optional, diags
+}
+
+// ruleRefs returns the configured rule references, see ruleValues.
+func (m *FirewallRuleOrderingResourceModel) ruleRefs(ctx context.Context) ([]string, diag.Diagnostics) {
+	values, diags := m.ruleValues(ctx)
+	refs := make([]string, 0, len(values))
+	for _, v := range values {
+		refs = append(refs, v.ValueString())
+	}
+	return refs, diags
+}
+
+// rulesPath returns the path of the attribute the rules are configured with.
+func (m *FirewallRuleOrderingResourceModel) rulesPath() path.Path {
+	if m.RuleResources.IsNull() {
+		return path.Root("rules")
+	}
+	return path.Root("rule_resources")
+}
+
+// setRuleRefs stores refs in whichever of rules and rule_resources is used
+// by the configuration. Elements of rules keep whether they are optional.
+func (m *FirewallRuleOrderingResourceModel) setRuleRefs(ctx context.Context, refs []string) diag.Diagnostics {
+	if m.RuleResources.IsNull() {
+		optional, diags := m.optionalRefs(ctx)
+		if diags.HasError() {
+			return diags
+		}
+		entries := make([]ruleEntryModel, 0, len(refs))
+		for _, ref := range refs {
+			entries = append(entries, ruleEntryModel{Ref: types.StringValue(ref), Optional: types.BoolValue(optional[ref])})
+		}
+		m.Rules, diags = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ruleEntryAttrTypes}, entries)
+		return diags
+	}
+
+	var diags diag.Diagnostics
+	objects := make([]ruleResourceModel, 0, len(refs))
+	for _, ref := range refs {
+		objects = append(objects, ruleResourceModel{ID: types.StringValue(ref)})
+	}
+	m.RuleResources, diags = types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ruleResourceAttrTypes}, objects)
+	return diags
+}
+
+// orderingOpts returns the options for comparing the ordering on the device
+// with the configured one.
+func (m *FirewallRuleOrderingResourceModel) orderingOpts() client.OrderingOpts {
+	return client.OrderingOpts{
+		Chain:          m.Chain.ValueString(),
+		Strict:         m.Strict.ValueBool(),
+		IgnoreDynamic:  m.IgnoreDynamic.ValueBool(),
+		IgnoreDisabled: m.IgnoreDisabled.ValueBool(),
+	}
+}
+
+// orderingID returns the identifier of an ordering. This is its name if set,
+// otherwise the rule type followed by a hash of the host, rule type, chain and
+// rule references, so that the same configuration always yields the same ID.
+// Copies of a resource are told apart by their owner token instead, see
+// orderingOwner.
+func orderingID(ctx context.Context, data *FirewallRuleOrderingResourceModel) (string, diag.Diagnostics) {
+	if name := data.Name.ValueString(); name != "" {
+		return name, nil
+	}
+
+	refs, diags := data.ruleRefs(ctx)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	h := sha256.New()
+	// the host is only hashed if set, which keeps the IDs of orderings created
+	// before hosts were supported stable
+	if host := data.Host.ValueString(); host != "" {
+		h.Write([]byte(host))
+		h.Write([]byte{0, 0})
+	}
+	for _, s := range append([]string{data.RuleType.ValueString(), data.Chain.ValueString()}, refs...) {
+		// NUL cannot occur in RouterOS strings and thus separates unambiguously
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s-%x", data.RuleType.ValueString(), h.Sum(nil)[:8]), diags
+}
+
+// orderingIDHashRegexp matches the hash which derived ordering IDs end with,
+// see orderingID.
+var orderingIDHashRegexp = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// parseOrderingImportID returns the rule type and, for named orderings, the
+// name of the ordering with the given import ID. This is either an ID derived
+// by orderingID, e.g. `filter-0123456789abcdef`, or `<rule_type>:<name>`.
+func parseOrderingImportID(id string) (ruleType, name string, err error) {
+	if ruleType, name, ok := strings.Cut(id, ":"); ok {
+		if name == "" || client.ValidateRuleType(ruleType) != nil {
+			return "", "", fmt.Errorf("expected an import ID of the form '<rule_type>:<name>', e.g. 'filter:input', got: %s", id)
+		}
+		return ruleType, name, nil
+	}
+
+	i := strings.LastIndex(id, "-")
+	if i == -1 || !orderingIDHashRegexp.MatchString(id[i+1:]) || client.ValidateRuleType(id[:i]) != nil {
+		return "", "", fmt.Errorf("expected either the id of an unnamed ordering, e.g. 'filter-0123456789abcdef', or '<rule_type>:<name>', got: %s", id)
+	}
+	return id[:i], "", nil
+}
@@ -0,0 +1,139 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+var _ resource.ResourceWithUpgradeState = &FirewallRuleOrderingResource{}
+
+// ruleOrderingSchemaVersion is the current version of the rule_ordering
+// schema. It has to be bumped, and an upgrader added to UpgradeState, whenever
+// a change to the schema cannot be applied to existing states as-is, e.g. when
+// an attribute is renamed or changes its type.
+const ruleOrderingSchemaVersion = 3
+
+// ruleOrderingDefaultsV0 are the values of attributes which were added with
+// a default while the schema was unversioned. States written before they
+// existed lack them entirely, and would otherwise show a diff on the next plan.
+var ruleOrderingDefaultsV0 = map[string]interface{}{
+	"strict":             true,
+	"ignore_dynamic":     false,
+	"ignore_disabled":    false,
+	"restore_on_destroy": false,
+	"match_by":           matchByID,
+}
+
+// ruleOrderingDefaultsV1 are the values of attributes which were added with
+// a default in version 2 of the schema.
+var ruleOrderingDefaultsV1 = map[string]interface{}{
+	"on_unmanaged": onUnmanagedIgnore,
+}
+
+// UpgradeState migrates states written by earlier releases of the provider to
+// the current schema.
+func (r *FirewallRuleOrderingResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {StateUpgrader: r.upgradeStateV0},
+		1: {StateUpgrader: r.upgradeStateV1},
+		2: {StateUpgrader: r.upgradeStateV2},
+	}
+}
+
+// upgradeStateV0 fills in the defaults of attributes which are missing from
+// unversioned states. Any other missing attribute is null, as before.
+func (r *FirewallRuleOrderingResource) upgradeStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	fillStateDefaults(ctx, req, resp, ruleOrderingDefaultsV0, ruleOrderingDefaultsV1)
+}
+
+// upgradeStateV1 fills in the defaults of attributes which were added in
+// version 2 of the schema.
+func (r *FirewallRuleOrderingResource) upgradeStateV1(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	fillStateDefaults(ctx, req, resp, ruleOrderingDefaultsV1)
+}
+
+// upgradeStateV2 converts the references of rules, which were plain strings
+// until version 3 of the schema, see nestRuleRefs.
+func (r *FirewallRuleOrderingResource) upgradeStateV2(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	fillStateDefaults(ctx, req, resp)
+}
+
+// fillStateDefaults sets the attributes of the prior state which are missing
+// or null to the values given by defaults, nests plain rule references, and
+// converts the result to the current schema.
+func fillStateDefaults(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse, defaults ...map[string]interface{}) {
+	if req.RawState == nil || len(req.RawState.JSON) == 0 {
+		resp.Diagnostics.AddError("Unable To Upgrade State", "The prior state of the rule ordering is missing")
+		return
+	}
+
+	var state map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+	dec.UseNumber()
+	if err := dec.Decode(&state); err != nil {
+		resp.Diagnostics.AddError("Unable To Upgrade State", fmt.Sprintf("Unable to decode the prior state of the rule ordering, got error: %s", err))
+		return
+	}
+
+	for _, d := range defaults {
+		for attr, def := range d {
+			if v, ok := state[attr]; !ok || v == nil {
+				state[attr] = def
+			}
+		}
+	}
+
+	nestRuleRefs(state)
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable To Upgrade State", fmt.Sprintf("Unable to encode the upgraded state of the rule ordering, got error: %s", err))
+		return
+	}
+
+	raw := tfprotov6.RawState{JSON: b}
+	v, err := raw.Unmarshal(resp.State.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		resp.Diagnostics.AddError("Unable To Upgrade State", fmt.Sprintf("Unable to convert the prior state of the rule ordering to the current schema, got error: %s", err))
+		return
+	}
+	resp.State.Raw = v
+}
+
+// nestRuleRefs converts the elements of rules which are plain references, as
+// written before version 3 of the schema, to objects holding the reference,
+// e.g. `"*1A"` becomes `{"ref": "*1A", "optional": false}`.
+func nestRuleRefs(state map[string]interface{}) {
+	refs, ok := state["rules"].([]interface{})
+	if !ok {
+		return
+	}
+	for i, ref := range refs {
+		if s, ok := ref.(string); ok {
+			refs[i] = map[string]interface{}{"ref": s, "optional": false}
+		}
+	}
+}
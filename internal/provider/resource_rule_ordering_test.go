@@ -0,0 +1,323 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/rostest"
+)
+
+const testFilterMenu = "/ip/firewall/filter"
+
+func TestAccRuleOrderingResource(t *testing.T) {
+	server := newTestServer(t)
+	ids := server.Add(testFilterMenu,
+		map[string]string{"chain": "input", "action": "accept", "comment": "allow established"},
+		map[string]string{"chain": "input", "action": "accept", "comment": "allow ssh"},
+		map[string]string{"chain": "input", "action": "drop", "comment": "drop invalid"},
+	)
+	established, ssh, invalid := ids[0], ids[1], ids[2]
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create
+			{
+				Config: testAccRuleOrderingConfig("comment:drop invalid", "comment:allow established", "comment:allow ssh"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("routeros-firewall-list_rule_ordering.test", "rules.#", "3"),
+					resource.TestCheckResourceAttr("routeros-firewall-list_rule_ordering.test", "rules.0.ref", "comment:drop invalid"),
+					resource.TestCheckResourceAttr("routeros-firewall-list_rule_ordering.test", "rules.2.ref", "comment:allow ssh"),
+					testCheckRuleOrder(server, invalid, established, ssh),
+				),
+			},
+			// Drift after a rule was moved outside of Terraform shows up in
+			// the plan, but refreshing leaves the device alone
+			{
+				PreConfig: func() {
+					if err := server.Move(testFilterMenu, established, ssh); err != nil {
+						t.Fatalf("unable to move rule: %s", err)
+					}
+				},
+				Config:             testAccRuleOrderingConfig("comment:drop invalid", "comment:allow established", "comment:allow ssh"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Applying corrects the drift
+			{
+				Config: testAccRuleOrderingConfig("comment:drop invalid", "comment:allow established", "comment:allow ssh"),
+				Check:  testCheckRuleOrder(server, invalid, established, ssh),
+			},
+			// Re-ordering
+			{
+				Config: testAccRuleOrderingConfig("comment:allow established", "comment:allow ssh", "comment:drop invalid"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("routeros-firewall-list_rule_ordering.test", "rules.0.ref", "comment:allow established"),
+					resource.TestCheckResourceAttr("routeros-firewall-list_rule_ordering.test", "rules.2.ref", "comment:drop invalid"),
+					testCheckRuleOrder(server, established, ssh, invalid),
+				),
+			},
+			// A failing move is reported and leaves the table as it was
+			{
+				PreConfig: func() {
+					server.FailCommand(testFilterMenu, "move", "not enough permissions (9)")
+				},
+				Config:      testAccRuleOrderingConfig("comment:allow ssh", "comment:allow established", "comment:drop invalid"),
+				ExpectError: regexp.MustCompile(`not enough permissions`),
+			},
+			{
+				PreConfig: func() {
+					if err := testCheckRuleOrder(server, established, ssh, invalid)(nil); err != nil {
+						t.Fatalf("rules were moved by the failed apply: %s", err)
+					}
+					server.FailCommand(testFilterMenu, "move", "")
+				},
+				Config: testAccRuleOrderingConfig("comment:allow ssh", "comment:allow established", "comment:drop invalid"),
+				Check:  testCheckRuleOrder(server, ssh, established, invalid),
+			},
+		},
+	})
+}
+
+func TestAccRuleOrderingResource_missingRule(t *testing.T) {
+	server := newTestServer(t)
+	ids := server.Add(testFilterMenu,
+		map[string]string{"chain": "input", "action": "accept", "comment": "allow ssh"},
+		map[string]string{"chain": "input", "action": "drop", "comment": "drop invalid"},
+	)
+	ssh, invalid := ids[0], ids[1]
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// A missing rule fails the apply without moving any rule
+			{
+				Config:      testAccRuleOrderingConfig("comment:drop invalid", "comment:allow vpn", "comment:allow ssh"),
+				ExpectError: regexp.MustCompile(`(?s)Unable To Resolve Rules.*'comment:allow vpn'`),
+			},
+			// Unless the rule is optional, in which case it is skipped
+			{
+				PreConfig: func() {
+					if err := testCheckRuleOrder(server, ssh, invalid)(nil); err != nil {
+						t.Fatalf("rules were moved by the failed apply: %s", err)
+					}
+				},
+				Config: `
+resource "routeros-firewall-list_rule_ordering" "test" {
+  rule_type = "filter"
+  rules = [
+    { ref = "comment:drop invalid" },
+    { ref = "comment:allow vpn", optional = true },
+    { ref = "comment:allow ssh" },
+  ]
+}
+`,
+				Check: testCheckRuleOrder(server, invalid, ssh),
+			},
+		},
+	})
+}
+
+func TestOrderingID(t *testing.T) {
+	ctx := context.Background()
+	model := func(name, chain string, refs ...string) *FirewallRuleOrderingResourceModel {
+		entries := make([]ruleEntryModel, 0, len(refs))
+		for _, ref := range refs {
+			entries = append(entries, ruleEntryModel{Ref: types.StringValue(ref), Optional: types.BoolValue(false)})
+		}
+		rules, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ruleEntryAttrTypes}, entries)
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+		return &FirewallRuleOrderingResourceModel{
+			RuleType:      types.StringValue("filter"),
+			Chain:         types.StringValue(chain),
+			Name:          types.StringValue(name),
+			Rules:         rules,
+			RuleResources: types.ListNull(types.ObjectType{AttrTypes: ruleResourceAttrTypes}),
+		}
+	}
+	id := func(m *FirewallRuleOrderingResourceModel) string {
+		id, diags := orderingID(ctx, m)
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+		return id
+	}
+
+	derived := id(model("", "input", "*1", "*2"))
+	if !regexp.MustCompile(`^filter-[0-9a-f]{16}$`).MatchString(derived) {
+		t.Errorf("orderingID() = %s, want the rule type followed by a hash", derived)
+	}
+	if again := id(model("", "input", "*1", "*2")); again != derived {
+		t.Errorf("orderingID() = %s for the same configuration, want %s", again, derived)
+	}
+	for _, m := range []*FirewallRuleOrderingResourceModel{model("", "input", "*2", "*1"), model("", "forward", "*1", "*2"), model("", "input", "*1,*2")} {
+		if other := id(m); other == derived {
+			t.Errorf("orderingID() = %s for a different configuration", other)
+		}
+	}
+	if named := id(model("input", "input", "*1", "*2")); named != "input" {
+		t.Errorf("orderingID() = %s, want the name", named)
+	}
+}
+
+func TestParseOrderingImportID(t *testing.T) {
+	tests := []struct {
+		id       string
+		ruleType string
+		name     string
+		wantErr  bool
+	}{
+		{id: "filter-0123456789abcdef", ruleType: "filter"},
+		{id: "bridge-nat-0123456789abcdef", ruleType: "bridge-nat"},
+		{id: "/ipv6/firewall/filter-0123456789abcdef", ruleType: "/ipv6/firewall/filter"},
+		{id: "filter:input", ruleType: "filter", name: "input"},
+		{id: "nat:dst:nat", ruleType: "nat", name: "dst:nat"},
+		{id: "filter", wantErr: true},
+		{id: "filter-0123", wantErr: true},
+		{id: "filter-0123456789ABCDEF", wantErr: true},
+		{id: "filter-0123456789abcdef-01234567", wantErr: true},
+		{id: "chain-0123456789abcdef", wantErr: true},
+		{id: "filter:", wantErr: true},
+		{id: "input:filter", wantErr: true},
+		{id: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		ruleType, name, err := parseOrderingImportID(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOrderingImportID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if ruleType != tt.ruleType || name != tt.name {
+			t.Errorf("parseOrderingImportID(%q) = %q, %q, want %q, %q", tt.id, ruleType, name, tt.ruleType, tt.name)
+		}
+	}
+}
+
+func TestRestoreMoves(t *testing.T) {
+	rules := func(ids ...string) []client.FirewallRule {
+		rules := make([]client.FirewallRule, 0, len(ids))
+		for _, id := range ids {
+			rules = append(rules, client.FirewallRule{ID: id})
+		}
+		return rules
+	}
+	managed := map[string]bool{"*M1": true, "*M2": true, "*M3": true, "*M4": true, "*M5": true}
+
+	tests := []struct {
+		name     string
+		original []string
+		current  []client.FirewallRule
+		want     []restoreMove
+	}{
+		{
+			name:     "already in place",
+			original: []string{"*1", "*M1", "*2"},
+			current:  rules("*1", "*M1", "*2"),
+			want:     []restoreMove{{ids: []string{"*M1"}, target: client.Before("*2")}},
+		},
+		{
+			name:     "managed rules in front of their original successors",
+			original: []string{"*1", "*M1", "*M2", "*2", "*M3", "*3"},
+			current:  rules("*M3", "*M2", "*M1", "*1", "*2", "*3"),
+			want: []restoreMove{
+				{ids: []string{"*M1", "*M2"}, target: client.Before("*2")},
+				{ids: []string{"*M3"}, target: client.Before("*3")},
+			},
+		},
+		{
+			name:     "managed rules at the end",
+			original: []string{"*1", "*M1", "*M2"},
+			current:  rules("*M2", "*M1", "*1"),
+			want:     []restoreMove{{ids: []string{"*M1", "*M2"}, target: client.End}},
+		},
+		{
+			name:     "deleted successor",
+			original: []string{"*1", "*M1", "*2", "*M2", "*3", "*4"},
+			current:  rules("*M2", "*M1", "*1", "*4"),
+			want:     []restoreMove{{ids: []string{"*M1", "*M2"}, target: client.Before("*4")}},
+		},
+		{
+			name:     "deleted managed rule",
+			original: []string{"*1", "*M1", "*M2", "*2"},
+			current:  rules("*M2", "*1", "*2"),
+			want:     []restoreMove{{ids: []string{"*M2"}, target: client.Before("*2")}},
+		},
+		{
+			name:     "rules created since are left alone",
+			original: []string{"*1", "*M1", "*2"},
+			current:  rules("*M5", "*M1", "*5", "*1", "*2"),
+			want:     []restoreMove{{ids: []string{"*M1"}, target: client.Before("*2")}},
+		},
+		{
+			name:     "no managed rules left",
+			original: []string{"*1", "*M1", "*2"},
+			current:  rules("*1", "*2"),
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restoreMoves(tt.original, tt.current, managed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("restoreMoves() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testAccRuleOrderingConfig returns the configuration of a filter rule
+// ordering of the given rule references.
+func testAccRuleOrderingConfig(refs ...string) string {
+	rules := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		rules = append(rules, fmt.Sprintf("    { ref = %q },", ref))
+	}
+	return fmt.Sprintf(`
+resource "routeros-firewall-list_rule_ordering" "test" {
+  rule_type = "filter"
+  rules = [
+%s
+  ]
+}
+`, strings.Join(rules, "\n"))
+}
+
+// testCheckRuleOrder checks that the filter table of server holds exactly the
+// rules with the given IDs in that order.
+func testCheckRuleOrder(server *rostest.Server, ids ...string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		got := server.IDs(testFilterMenu)
+		if strings.Join(got, ",") != strings.Join(ids, ",") {
+			return fmt.Errorf("expected rules to be ordered %v, got %v", ids, got)
+		}
+		return nil
+	}
+}
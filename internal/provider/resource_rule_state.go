@@ -0,0 +1,286 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/rosmap"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &RuleStateResource{}
+var _ resource.ResourceWithImportState = &RuleStateResource{}
+
+func NewRuleStateResource() resource.Resource {
+	return &RuleStateResource{}
+}
+
+// RuleStateResource defines the resource implementation.
+type RuleStateResource struct {
+	client client.API
+}
+
+// RuleStateResourceModel describes the resource data model.
+type RuleStateResourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	RuleType         types.String `tfsdk:"rule_type"`
+	Rule             types.String `tfsdk:"rule"`
+	RuleID           types.String `tfsdk:"rule_id"`
+	Disabled         types.Bool   `tfsdk:"disabled"`
+	RestoreOnDestroy types.Bool   `tfsdk:"restore_on_destroy"`
+	InitialDisabled  types.Bool   `tfsdk:"initial_disabled"`
+}
+
+func (r *RuleStateResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_state"
+}
+
+func (r *RuleStateResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *RuleStateResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Whether an existing rule is disabled, e.g. to temporarily disable a block rule in an emergency. Only the `disabled` flag of the rule is managed, the rule itself is left to whoever created it, which need not be Terraform",
+		Description:         "Whether an existing rule is disabled, e.g. to temporarily disable a block rule in an emergency. Only the 'disabled' flag of the rule is managed, the rule itself is left to whoever created it, which need not be Terraform",
+		Attributes: map[string]schema.Attribute{
+			"rule_type": schema.StringAttribute{
+				MarkdownDescription: "Rule table of the rule, one of `filter`, `nat`, `mangle`, `raw`, `bridge-filter` or `bridge-nat`. Defaults to `filter`",
+				Description:         "Rule table of the rule, one of 'filter', 'nat', 'mangle', 'raw', 'bridge-filter' or 'bridge-nat'. Defaults to 'filter'",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("filter"),
+				Validators: []validator.String{
+					stringvalidator.OneOf(append(append([]string{}, client.RuleTypes...), client.BridgeRuleTypes...)...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rule": schema.StringAttribute{
+				MarkdownDescription: "Rule to manage, referenced either by its ID, e.g. `*1A`, or by its comment, e.g. `comment:drop bogons`. A comment reference is resolved once when the resource is created",
+				Description:         "Rule to manage, referenced either by its ID, e.g. '*1A', or by its comment, e.g. 'comment:drop bogons'. A comment reference is resolved once when the resource is created",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.Any(
+						stringvalidator.RegexMatches(client.IDRegexp, "must be a RouterOS id such as '*1A'"),
+						stringvalidator.RegexMatches(commentReferenceRegexp, "must be a comment reference such as 'comment:drop bogons'"),
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the rule is disabled",
+				Description:         "Whether the rule is disabled",
+				Required:            true,
+			},
+			"restore_on_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Whether destroying the resource sets the `disabled` flag of the rule back to `initial_disabled`. Otherwise, the rule is left as it is. Defaults to `true`",
+				Description:         "Whether destroying the resource sets the 'disabled' flag of the rule back to 'initial_disabled'. Otherwise, the rule is left as it is. Defaults to 'true'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"initial_disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the rule was disabled before the resource was created",
+				Description:         "Whether the rule was disabled before the resource was created",
+				Computed:            true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rule_id": schema.StringAttribute{
+				MarkdownDescription: "RouterOS ID of the rule",
+				Description:         "RouterOS ID of the rule",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource",
+				MarkdownDescription: "Identifier of resource",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RuleStateResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data RuleStateResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rule, err := r.client.ResolveRuleReference(ctx, data.RuleType.ValueString(), data.Rule.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rule"),
+			"Unknown Rule",
+			fmt.Sprintf("Unable to resolve %s rule '%s', got error: %s", data.RuleType.ValueString(), data.Rule.ValueString(), err),
+		)
+		return
+	}
+
+	data.RuleID = types.StringValue(rule.ID)
+	data.InitialDisabled = types.BoolValue(rule.Disabled == "true")
+	data.ID = types.StringValue(fmt.Sprintf("%s:%s", data.RuleType.ValueString(), rule.ID))
+
+	if err := r.setDisabled(ctx, &data, data.Disabled.ValueBool()); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to set state of rule"))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleStateResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data RuleStateResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	props, err := r.client.GetRuleProperties(ctx, data.RuleType.ValueString(), data.RuleID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read state of rule"))
+		return
+	}
+
+	disabled, err := rosmap.ParseBool(props["disabled"])
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to read state of rule"))
+		return
+	}
+	data.Disabled = types.BoolValue(disabled)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleStateResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data RuleStateResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.setDisabled(ctx, &data, data.Disabled.ValueBool()); err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to set state of rule"))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *RuleStateResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data RuleStateResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.RestoreOnDestroy.ValueBool() || data.InitialDisabled.IsNull() {
+		return
+	}
+
+	err := r.setDisabled(ctx, &data, data.InitialDisabled.ValueBool())
+	if client.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to restore state of rule"))
+	}
+}
+
+// ImportState imports the state of a rule given as `<rule_type>:<id>`, e.g.
+// `filter:*1A`. The rule's current state is taken as its initial state.
+func (r *RuleStateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ruleType, id, ok := strings.Cut(req.ID, ":")
+	if !ok || client.ValidateID(id) != nil {
+		resp.Diagnostics.AddError("Invalid Import ID", fmt.Sprintf("Expected an import ID of the form '<rule_type>:<id>', e.g. 'filter:*1A', got: %s", req.ID))
+		return
+	}
+
+	rule, err := r.client.GetRule(ctx, ruleType, id)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to import state of rule"))
+		return
+	}
+
+	data := RuleStateResourceModel{
+		ID:               types.StringValue(req.ID),
+		RuleType:         types.StringValue(ruleType),
+		Rule:             types.StringValue(id),
+		RuleID:           types.StringValue(id),
+		Disabled:         types.BoolValue(rule.Disabled == "true"),
+		RestoreOnDestroy: types.BoolValue(true),
+		InitialDisabled:  types.BoolValue(rule.Disabled == "true"),
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// setDisabled sets the disabled flag of the managed rule.
+func (r *RuleStateResource) setDisabled(ctx context.Context, data *RuleStateResourceModel, disabled bool) error {
+	return r.client.SetRules(ctx, data.RuleType.ValueString(), []string{data.RuleID.ValueString()}, map[string]string{
+		"disabled": rosmap.FormatBool(disabled),
+	})
+}
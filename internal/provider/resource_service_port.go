@@ -0,0 +1,211 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &ServicePortResource{}
+var _ resource.ResourceWithImportState = &ServicePortResource{}
+
+func NewServicePortResource() resource.Resource {
+	return &ServicePortResource{}
+}
+
+// ServicePortResource defines the resource implementation.
+type ServicePortResource struct {
+	client client.API
+}
+
+// ServicePortResourceModel describes the resource data model.
+type ServicePortResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	Ports    types.String `tfsdk:"ports"`
+	Disabled types.Bool   `tfsdk:"disabled"`
+}
+
+func (r *ServicePortResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_port"
+}
+
+func (r *ServicePortResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(client.API)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	r.client = client
+}
+
+func (r *ServicePortResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Connection tracking helper (`/ip/firewall/service-port`). Service ports are built into RouterOS, so this resource only manages their settings. Destroying the resource leaves the service port as it is",
+		Description:         "Connection tracking helper (/ip/firewall/service-port). Service ports are built into RouterOS, so this resource only manages their settings. Destroying the resource leaves the service port as it is",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the service port, e.g. `sip`, `ftp` or `pptp`",
+				Description:         "Name of the service port, e.g. 'sip', 'ftp' or 'pptp'",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"disabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the helper is disabled. Defaults to `false`",
+				Description:         "Whether the helper is disabled. Defaults to 'false'",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"ports": schema.StringAttribute{
+				MarkdownDescription: "Comma-separated ports the helper listens on, e.g. `5060,5061`. Only applicable to helpers which are bound to ports; left untouched if unset",
+				Description:         "Comma-separated ports the helper listens on, e.g. '5060,5061'. Only applicable to helpers which are bound to ports; left untouched if unset",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				Description:         "Identifier of resource, equal to the name of the service port",
+				MarkdownDescription: "Identifier of resource, equal to the name of the service port",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *ServicePortResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ServicePortResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.apply(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to configure service port"))
+		return
+	}
+
+	data.fromClient(updated)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServicePortResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ServicePortResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s, err := r.client.GetServicePort(ctx, data.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		if keepStateOnUnreachable(r.client, err, &resp.Diagnostics) {
+			return
+		}
+		resp.Diagnostics.AddError(clientError(err, "Unable to read service port"))
+		return
+	}
+
+	data.fromClient(s)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ServicePortResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data ServicePortResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.apply(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError(clientError(err, "Unable to configure service port"))
+		return
+	}
+
+	data.fromClient(updated)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete removes the service port from state only. Service ports cannot be
+// removed from the device, and there is no sensible default to reset them to.
+func (r *ServicePortResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// ImportState imports service ports by their name.
+func (r *ServicePortResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// apply looks up the service port by name and sets the configured properties
+// on it.
+func (r *ServicePortResource) apply(ctx context.Context, data *ServicePortResourceModel) (client.ServicePort, error) {
+	s, err := r.client.GetServicePort(ctx, data.Name.ValueString())
+	if err != nil {
+		return s, err
+	}
+
+	update := client.ServicePort{
+		ID:       s.ID,
+		Disabled: fmt.Sprintf("%t", data.Disabled.ValueBool()),
+	}
+	if !data.Ports.IsNull() && !data.Ports.IsUnknown() {
+		update.Ports = data.Ports.ValueString()
+	}
+
+	return r.client.UpdateServicePort(ctx, update)
+}
+
+func (m *ServicePortResourceModel) fromClient(s client.ServicePort) {
+	m.ID = types.StringValue(s.Name)
+	m.Name = types.StringValue(s.Name)
+	m.Ports = types.StringValue(s.Ports)
+	m.Disabled = types.BoolValue(s.Disabled == "true")
+}
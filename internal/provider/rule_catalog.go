@@ -0,0 +1,161 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// ruleField describes a single property of RouterOS firewall rules. The
+// schemas, validators and property mappings of all rule resources are
+// generated from ruleCatalog, so that the same property is described the
+// same way in every table.
+type ruleField struct {
+	// name is the name of the Terraform attribute, e.g. `src_address`.
+	name string
+	// property is the name of the RouterOS property, e.g. `src-address`.
+	property    string
+	description string
+	kind        ruleAttributeKind
+	required    bool
+	// computed must be set for properties which RouterOS assigns a default
+	// value to if they are not specified.
+	computed bool
+	// values lists the valid values of the property. Any value is accepted
+	// if it is empty.
+	values []string
+	// tables lists the rule types which support the property. All firewall
+	// tables support it if it is empty.
+	tables []string
+}
+
+var filterActions = []string{
+	"accept", "add-dst-to-address-list", "add-src-to-address-list", "drop", "fasttrack-connection", "jump",
+	"log", "passthrough", "reject", "return", "tarpit",
+}
+
+var natActions = []string{
+	"accept", "add-dst-to-address-list", "add-src-to-address-list", "dst-nat", "jump", "log", "masquerade",
+	"netmap", "passthrough", "redirect", "return", "same", "src-nat",
+}
+
+var mangleActions = []string{
+	"accept", "add-dst-to-address-list", "add-src-to-address-list", "change-dscp", "change-mss", "change-ttl",
+	"clear-df", "fasttrack-connection", "jump", "log", "mark-connection", "mark-packet", "mark-routing",
+	"passthrough", "return", "route", "set-priority", "sniff-pc", "sniff-tzsp", "strip-ipv4-options",
+}
+
+var rawActions = []string{
+	"accept", "add-dst-to-address-list", "add-src-to-address-list", "drop", "jump", "log", "notrack",
+	"passthrough", "return",
+}
+
+var rejectReasons = []string{
+	"icmp-admin-prohibited", "icmp-host-prohibited", "icmp-host-unreachable", "icmp-net-prohibited",
+	"icmp-network-unreachable", "icmp-port-unreachable", "icmp-protocol-unreachable", "tcp-reset",
+}
+
+const actionDescription = "Action to take if a packet matches the rule. Defaults to `accept`"
+
+// ruleCatalog lists every supported property of the rules of the firewall
+// tables. A property may be listed more than once for different tables if
+// its valid values differ between them.
+var ruleCatalog = []ruleField{
+	{name: "chain", property: "chain", required: true, description: "Chain the rule belongs to"},
+	{name: "action", property: "action", computed: true, description: actionDescription, values: filterActions, tables: []string{"filter"}},
+	{name: "action", property: "action", computed: true, description: actionDescription, values: natActions, tables: []string{"nat"}},
+	{name: "action", property: "action", computed: true, description: actionDescription, values: mangleActions, tables: []string{"mangle"}},
+	{name: "action", property: "action", computed: true, description: actionDescription, values: rawActions, tables: []string{"raw"}},
+	{name: "comment", property: "comment", description: "Comment attached to the rule"},
+	{name: "disabled", property: "disabled", kind: ruleAttributeBool, computed: true, description: "Whether the rule is disabled. Defaults to `false`"},
+	{name: "src_address", property: "src-address", kind: ruleAttributeAddress, description: "Source address, range or subnet to match"},
+	{name: "dst_address", property: "dst-address", kind: ruleAttributeAddress, description: "Destination address, range or subnet to match"},
+	{name: "src_address_list", property: "src-address-list", description: "Name of an address list to match the source address against"},
+	{name: "dst_address_list", property: "dst-address-list", description: "Name of an address list to match the destination address against"},
+	{name: "protocol", property: "protocol", description: "IP protocol to match, e.g. `tcp`"},
+	{name: "src_port", property: "src-port", description: "Source ports or port ranges to match. Requires `protocol` to be `tcp` or `udp`"},
+	{name: "dst_port", property: "dst-port", description: "Destination ports or port ranges to match. Requires `protocol` to be `tcp` or `udp`"},
+	{name: "in_interface", property: "in-interface", description: "Interface the packet entered the router through"},
+	{name: "out_interface", property: "out-interface", description: "Interface the packet is leaving the router through"},
+	{name: "in_interface_list", property: "in-interface-list", description: "Interface list the incoming interface must be a member of"},
+	{name: "out_interface_list", property: "out-interface-list", description: "Interface list the outgoing interface must be a member of"},
+	{name: "jump_target", property: "jump-target", description: "Chain to jump to if `action` is `jump`"},
+	{name: "address_list", property: "address-list", description: "Address list to add addresses to if `action` is `add-src-to-address-list` or `add-dst-to-address-list`"},
+	{name: "address_list_timeout", property: "address-list-timeout", kind: ruleAttributeDuration, computed: true, description: "Time after which addresses added by the rule are removed from `address_list`, e.g. `1d` or `24h`"},
+	{name: "log", property: "log", kind: ruleAttributeBool, computed: true, description: "Whether to log matching packets. Defaults to `false`"},
+	{name: "log_prefix", property: "log-prefix", description: "Prefix of log messages of matching packets"},
+
+	// connection tracking, which raw rules are processed before
+	{name: "connection_state", property: "connection-state", description: "Comma-separated connection tracking states to match, e.g. `established,related`", tables: []string{"filter", "nat", "mangle"}},
+	{name: "connection_nat_state", property: "connection-nat-state", description: "Comma-separated NAT states of the connection to match, e.g. `dstnat`", tables: []string{"filter", "mangle"}},
+	{name: "connection_mark", property: "connection-mark", description: "Connection mark to match", tables: []string{"filter", "nat", "mangle"}},
+	{name: "packet_mark", property: "packet-mark", description: "Packet mark to match", tables: []string{"filter", "nat", "mangle"}},
+	{name: "routing_mark", property: "routing-mark", description: "Routing mark to match", tables: []string{"filter", "nat", "mangle"}},
+
+	// filter
+	{name: "reject_with", property: "reject-with", description: "ICMP error or TCP reset to respond with if `action` is `reject`", values: rejectReasons, tables: []string{"filter"}},
+
+	// nat
+	{name: "to_addresses", property: "to-addresses", description: "Address or range to translate to if `action` is `dst-nat`, `src-nat` or `netmap`", tables: []string{"nat"}},
+	{name: "to_ports", property: "to-ports", description: "Port or port range to translate to if `action` is `dst-nat`, `src-nat`, `masquerade`, `redirect` or `netmap`", tables: []string{"nat"}},
+
+	// mangle
+	{name: "new_connection_mark", property: "new-connection-mark", description: "Connection mark to set if `action` is `mark-connection`", tables: []string{"mangle"}},
+	{name: "new_packet_mark", property: "new-packet-mark", description: "Packet mark to set if `action` is `mark-packet`", tables: []string{"mangle"}},
+	{name: "new_routing_mark", property: "new-routing-mark", description: "Routing mark to set if `action` is `mark-routing`", tables: []string{"mangle"}},
+	{name: "passthrough", property: "passthrough", kind: ruleAttributeBool, computed: true, description: "Whether packets continue to be processed by subsequent rules after being marked", tables: []string{"mangle"}},
+}
+
+// ruleAttributesFor returns the attributes of the rules of the given rule
+// type, as listed in ruleCatalog.
+func ruleAttributesFor(ruleType string) []ruleAttribute {
+	var attributes []ruleAttribute
+	for _, f := range ruleCatalog {
+		if !f.supports(ruleType) {
+			continue
+		}
+		a := ruleAttribute{
+			name:        f.name,
+			property:    f.property,
+			description: f.description,
+			kind:        f.kind,
+			required:    f.required,
+			computed:    f.computed,
+		}
+		if len(f.values) > 0 {
+			a.validators = []validator.String{stringvalidator.OneOf(f.values...)}
+		}
+		attributes = append(attributes, a)
+	}
+	return attributes
+}
+
+// supports reports whether rules of the given rule type have the property.
+func (f ruleField) supports(ruleType string) bool {
+	if len(f.tables) == 0 {
+		return true
+	}
+	for _, t := range f.tables {
+		if t == ruleType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Values of the `match_by` attribute of rule_ordering.
+const (
+	matchByID          = "id"
+	matchByComment     = "comment"
+	matchByContentHash = "content-hash"
+)
+
+// ruleIdentitiesKey is the private state key under which the identities of
+// the rules referenced by an ordering are stored.
+const ruleIdentitiesKey = "rule_identities"
+
+// ruleIdentity records what a rule looked like when it was last resolved, so
+// that it can be found again after it has been recreated with a new ID.
+type ruleIdentity struct {
+	ID          string `json:"id"`
+	Comment     string `json:"comment,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// ruleIdentities maps rule references to the identity of the rule they last
+// resolved to.
+type ruleIdentities map[string]ruleIdentity
+
+// loadRuleIdentities returns the identities stored in private state. The
+// result is never nil.
+func loadRuleIdentities(ctx context.Context, private privateState) (ruleIdentities, diag.Diagnostics) {
+	identities := ruleIdentities{}
+
+	b, diags := private.GetKey(ctx, ruleIdentitiesKey)
+	if diags.HasError() || len(b) == 0 {
+		return identities, diags
+	}
+
+	if err := json.Unmarshal(b, &identities); err != nil {
+		diags.AddError("Internal Error", fmt.Sprintf("Unable to decode rule identities, got error: %s", err))
+	}
+	return identities, diags
+}
+
+// save stores the identities in private state.
+func (ids ruleIdentities) save(ctx context.Context, private privateState) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	b, err := json.Marshal(ids)
+	if err != nil {
+		diags.AddError("Internal Error", fmt.Sprintf("Unable to encode rule identities, got error: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, ruleIdentitiesKey, b)
+}
+
+// resolveRef resolves a rule reference like client.ResolveRuleReference. If
+// the referenced ID no longer exists and the ordering matches rules by comment
+// or content, the rule is looked up by the identity recorded for ref instead.
+func (r *FirewallRuleOrderingResource) resolveRef(ctx context.Context, data *FirewallRuleOrderingResourceModel, identities ruleIdentities, ref string) (client.FirewallRule, error) {
+	ruleType := data.RuleType.ValueString()
+	matchBy := data.MatchBy.ValueString()
+
+	rule, err := r.client.ResolveRuleReference(ctx, ruleType, ref)
+	if !errors.Is(err, client.ErrRuleNotFound) || matchBy == matchByID || client.IsCommentReference(ref) {
+		return rule, err
+	}
+
+	known, ok := identities[ref]
+	if !ok {
+		return rule, err
+	}
+
+	var found client.FirewallRule
+	var e error
+	switch {
+	case matchBy == matchByComment && known.Comment != "":
+		found, e = r.client.ResolveRuleReference(ctx, ruleType, client.CommentReference(known.Comment))
+	case matchBy == matchByContentHash && known.Fingerprint != "":
+		found, e = r.client.FindRuleByFingerprint(ctx, ruleType, known.Fingerprint)
+	default:
+		return rule, err
+	}
+	if errors.Is(e, client.ErrRuleNotFound) {
+		return rule, err
+	}
+	if e != nil {
+		return found, e
+	}
+
+	tflog.Info(ctx, "Referenced rule was recreated, following it to its new ID", map[string]interface{}{
+		"rule_type": ruleType,
+		"reference": ref,
+		"old_id":    known.ID,
+		"new_id":    found.ID,
+		"match_by":  matchBy,
+	})
+	return found, nil
+}
+
+// recordIdentities records the identities of the rules which the given
+// references resolved to. This is a no-op when matching by ID, which does not
+// require any bookkeeping.
+func (r *FirewallRuleOrderingResource) recordIdentities(ctx context.Context, data *FirewallRuleOrderingResourceModel, identities ruleIdentities, resolved map[string]client.FirewallRule) error {
+	matchBy := data.MatchBy.ValueString()
+	if matchBy != matchByComment && matchBy != matchByContentHash {
+		return nil
+	}
+
+	// the properties of all rules are fetched at once, as fingerprints have
+	// to reflect any change which was made to the rules in the meantime
+	fingerprints := map[string]string{}
+	if matchBy == matchByContentHash {
+		rules, err := r.client.ListRuleProperties(ctx, data.RuleType.ValueString())
+		if err != nil {
+			return err
+		}
+		for _, props := range rules {
+			fingerprints[props[".id"]] = client.RuleFingerprint(props)
+		}
+	}
+
+	for ref, rule := range resolved {
+		identities[ref] = ruleIdentity{ID: rule.ID, Comment: rule.Comment, Fingerprint: fingerprints[rule.ID]}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// ruleRefsValidator validates a list of rule references, see
+// client.ResolveRuleReference, given either as strings or as objects holding
+// the reference in their `ref` attribute. Every reference must be a RouterOS
+// ID such as `*1A` or a comment reference such as `comment:allow ssh`, and no
+// rule may be referenced twice, as RouterOS rejects moves which list a rule
+// more than once with an obscure error. Errors are reported for the offending
+// element. Elements which are not known yet are validated once they are.
+type ruleRefsValidator struct{}
+
+func (v ruleRefsValidator) Description(ctx context.Context) string {
+	return "elements must be unique RouterOS ids such as '*1A' or comment references such as 'comment:allow ssh'"
+}
+
+func (v ruleRefsValidator) MarkdownDescription(ctx context.Context) string {
+	return "elements must be unique RouterOS ids such as `*1A` or comment references such as `comment:allow ssh`"
+}
+
+func (v ruleRefsValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	seen := map[string]int{}
+	for i, elem := range req.ConfigValue.Elements() {
+		p := req.Path.AtListIndex(i)
+
+		// elements of rule_ordering's rules hold the reference in `ref`
+		if o, ok := elem.(types.Object); ok {
+			if o.IsUnknown() {
+				continue
+			}
+			elem, p = o.Attributes()["ref"], p.AtName("ref")
+		}
+
+		ref, ok := elem.(types.String)
+		if !ok {
+			resp.Diagnostics.AddAttributeError(p, "Invalid Rule Reference", fmt.Sprintf("Expected a string, got: %T", elem))
+			continue
+		}
+		if ref.IsUnknown() {
+			continue
+		}
+		if ref.IsNull() || strings.TrimSpace(ref.ValueString()) == "" {
+			resp.Diagnostics.AddAttributeError(p, "Empty Rule Reference", "Rules must be referenced by their RouterOS id, e.g. '*1A', or by their comment, e.g. 'comment:allow ssh' or 'chain:input,comment:allow ssh'")
+			continue
+		}
+
+		s := ref.ValueString()
+		var key string
+		switch {
+		case client.IDRegexp.MatchString(s):
+			// IDs are hexadecimal numbers, so `*1a` and `*1A` are the same rule
+			key = strings.ToUpper(s)
+		case commentReferenceRegexp.MatchString(s):
+			key = s
+		default:
+			resp.Diagnostics.AddAttributeError(p, "Invalid Rule Reference",
+				fmt.Sprintf("Expected a RouterOS id of the form '*1A' or a comment reference of the form 'comment:<comment>' or 'chain:<chain>,comment:<comment>', got: %s", s))
+			continue
+		}
+
+		if first, ok := seen[key]; ok {
+			resp.Diagnostics.AddAttributeError(p, "Duplicate Rule Reference",
+				fmt.Sprintf("The rule '%s' is already listed at index %d. Every rule may only be listed once", s, first))
+			continue
+		}
+		seen[key] = i
+	}
+}
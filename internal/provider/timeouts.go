@@ -0,0 +1,118 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// timeoutsModel describes the `timeouts` block of resources which support
+// operation timeouts.
+type timeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+var timeoutsAttrTypes = map[string]attr.Type{
+	"create": types.StringType,
+	"read":   types.StringType,
+	"update": types.StringType,
+	"delete": types.StringType,
+}
+
+// timeoutsBlock returns the schema of the `timeouts` block. Operations without
+// a configured timeout are only limited by the provider's per-request
+// `timeout`.
+func timeoutsBlock() schema.Block {
+	attrs := map[string]schema.Attribute{}
+	for op := range timeoutsAttrTypes {
+		attrs[op] = schema.StringAttribute{
+			MarkdownDescription: fmt.Sprintf("Maximum duration of the %s operation, e.g. `2m` or `30s`", op),
+			Description:         fmt.Sprintf("Maximum duration of the %s operation, e.g. '2m' or '30s'", op),
+			Optional:            true,
+			Validators: []validator.String{
+				durationValidator{},
+			},
+		}
+	}
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Timeouts of the individual operations",
+		Description:         "Timeouts of the individual operations",
+		Attributes:          attrs,
+	}
+}
+
+// withTimeout returns a context which is cancelled once the timeout of the
+// given operation, e.g. `create`, configured in timeouts has elapsed. If no
+// timeout is configured, the context is returned unchanged.
+func withTimeout(ctx context.Context, timeouts types.Object, op string) (context.Context, context.CancelFunc, diag.Diagnostics) {
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return ctx, func() {}, nil
+	}
+
+	value, ok := timeouts.Attributes()[op].(types.String)
+	if !ok || value.IsNull() || value.IsUnknown() {
+		return ctx, func() {}, nil
+	}
+
+	var diags diag.Diagnostics
+	d, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		diags.AddError("Invalid Timeout", fmt.Sprintf("Unable to parse %s timeout '%s': %s", op, value.ValueString(), err))
+		return ctx, func() {}, diags
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, diags
+}
+
+// durationValidator validates that a string is a positive Go duration such as
+// `1m30s`.
+type durationValidator struct{}
+
+func (v durationValidator) Description(ctx context.Context) string {
+	return "value must be a positive duration such as '1m30s'"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be a positive duration such as `1m30s`"
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if d, err := time.ParseDuration(req.ConfigValue.ValueString()); err != nil || d <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("Expected a positive duration such as '1m30s', got: %s", req.ConfigValue.ValueString()),
+		)
+	}
+}
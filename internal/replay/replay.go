@@ -0,0 +1,259 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package replay serves recorded transcripts of the RouterOS REST API, see
+// client.ClientOpts.RecordPath, so that a trace attached to a bug report can
+// be turned into a regression test without access to the reporter's device:
+//
+//	s, err := replay.NewServerFromFile("testdata/issue-42.jsonl")
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer s.Close()
+//	c, _ := client.New(s.ClientOpts())
+//	// exercise the client, then
+//	if err := s.Err(); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Every incoming request is answered with the response of the next recorded
+// exchange with the same method and path. Requests are matched in the order
+// in which they were recorded, but a request may be answered out of order if
+// it was sent concurrently with others, see Options.Strict.
+package replay
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Username and Password are the credentials the client is configured with.
+// Transcripts contain no credentials, so any are accepted.
+const (
+	Username = "replay"
+	Password = "replay"
+)
+
+// Options control how requests are matched against the transcript.
+type Options struct {
+	// Strict requires requests to arrive in exactly the recorded order.
+	// Otherwise, a request is answered by the first exchange with the same
+	// method and path which has not been used yet.
+	Strict bool
+}
+
+// Server is a stub of the RouterOS REST API serving a transcript over TLS.
+type Server struct {
+	*httptest.Server
+
+	// CAFile is the path to the PEM encoded certificate of the server. It is
+	// removed again by Close.
+	CAFile string
+
+	opts Options
+
+	mu        sync.Mutex
+	exchanges []client.Exchange
+	used      []bool
+	errs      []string
+}
+
+// NewServerFromFile starts a server replaying the transcript at path. It
+// must be closed by the caller.
+func NewServerFromFile(path string, opts ...Options) (*Server, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	exchanges, err := client.ReadTranscript(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read transcript %s: %w", path, err)
+	}
+	return NewServer(exchanges, opts...)
+}
+
+// NewServer starts a server replaying the given exchanges. It must be closed
+// by the caller.
+func NewServer(exchanges []client.Exchange, opts ...Options) (*Server, error) {
+	s := &Server{
+		exchanges: exchanges,
+		used:      make([]bool, len(exchanges)),
+	}
+	if len(opts) > 0 {
+		s.opts = opts[0]
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+
+	f, err := os.CreateTemp("", "replay-ca-*.pem")
+	if err != nil {
+		s.Server.Close()
+		return nil, err
+	}
+	defer f.Close()
+	s.CAFile = f.Name()
+
+	if err := pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close shuts down the server and removes its certificate file.
+func (s *Server) Close() {
+	s.Server.Close()
+	os.Remove(s.CAFile)
+}
+
+// ClientOpts returns options for a client connecting to the server.
+func (s *Server) ClientOpts() client.ClientOpts {
+	return client.ClientOpts{
+		HostURL:  s.URL,
+		Username: Username,
+		Password: Password,
+		CA:       s.CAFile,
+	}
+}
+
+// Env returns the provider environment variables for connecting to the
+// server, e.g. for use with `t.Setenv` in acceptance tests.
+func (s *Server) Env() map[string]string {
+	u, _ := url.Parse(s.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	return map[string]string{
+		"ROS_HOSTURL":        host,
+		"ROS_PORT":           port,
+		"ROS_USERNAME":       Username,
+		"ROS_PASSWORD":       Password,
+		"ROS_CA_CERTIFICATE": s.CAFile,
+	}
+}
+
+// Remaining returns the exchanges which have not been replayed yet.
+func (s *Server) Remaining() []client.Exchange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var remaining []client.Exchange
+	for i, e := range s.exchanges {
+		if !s.used[i] {
+			remaining = append(remaining, e)
+		}
+	}
+	return remaining
+}
+
+// Err returns an error describing every request which did not match the
+// transcript, or nil if all requests matched.
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d request(s) did not match the transcript:\n%s", len(s.errs), strings.Join(s.errs, "\n"))
+}
+
+// Done returns an error if not all exchanges have been replayed or any
+// request did not match the transcript, see Err.
+func (s *Server) Done() error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if remaining := s.Remaining(); len(remaining) > 0 {
+		requests := make([]string, 0, len(remaining))
+		for _, e := range remaining {
+			requests = append(requests, fmt.Sprintf("%s %s", e.Method, e.Path))
+		}
+		return fmt.Errorf("%d recorded request(s) were never sent:\n%s", len(remaining), strings.Join(requests, "\n"))
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// drain the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, r.Body)
+
+	e, ok := s.next(r.Method, r.URL.RequestURI())
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(client.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: "Internal Server Error",
+			Detail:  fmt.Sprintf("request %s %s is not part of the transcript", r.Method, r.URL.RequestURI()),
+		})
+		return
+	}
+
+	if e.Status == 0 {
+		// the request failed without a response when it was recorded
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		http.Error(w, e.Error, http.StatusBadGateway)
+		return
+	}
+
+	if e.ContentType != "" {
+		w.Header().Set("Content-Type", e.ContentType)
+	}
+	w.WriteHeader(e.Status)
+	_, _ = io.WriteString(w, e.ResponseBody)
+}
+
+// next returns the exchange answering a request with the given method and
+// path and marks it as used, see Options.Strict.
+func (s *Server) next(method, path string) (client.Exchange, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.exchanges {
+		if s.used[i] {
+			continue
+		}
+		if e.Method == method && e.Path == path {
+			s.used[i] = true
+			return e, true
+		}
+		if s.opts.Strict {
+			s.errs = append(s.errs, fmt.Sprintf("expected %s %s, got %s %s", e.Method, e.Path, method, path))
+			return client.Exchange{}, false
+		}
+	}
+
+	s.errs = append(s.errs, fmt.Sprintf("unexpected %s %s", method, path))
+	return client.Exchange{}, false
+}
@@ -0,0 +1,174 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package replay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// newTestServer starts a server replaying the transcript in testdata with the
+// given name, and returns it together with a client connected to it, which
+// records to recordPath unless it is empty.
+func newTestServer(t *testing.T, name, recordPath string) (*Server, *client.Client) {
+	t.Helper()
+
+	s, err := NewServerFromFile(filepath.Join("testdata", name+".jsonl"), Options{Strict: true})
+	if err != nil {
+		t.Fatalf("NewServerFromFile() error = %s", err)
+	}
+	t.Cleanup(s.Close)
+
+	o := s.ClientOpts()
+	o.RecordPath = recordPath
+	c, err := client.New(o)
+	if err != nil {
+		t.Fatalf("client.New() error = %s", err)
+	}
+	return s, c
+}
+
+func ruleIDs(rules []client.FirewallRule) string {
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestReplayOrderRules(t *testing.T) {
+	s, c := newTestServer(t, "order-rules", "")
+	ctx := context.Background()
+
+	n, err := c.OrderRules(ctx, "filter", []string{"*3", "*1", "*2"}, client.OrderingOpts{})
+	if err != nil {
+		t.Fatalf("OrderRules() error = %s", err)
+	}
+	if n != 1 {
+		t.Errorf("OrderRules() = %d moves, want 1", n)
+	}
+
+	rules, err := c.GetRulesOfType(ctx, "filter")
+	if err != nil {
+		t.Fatalf("GetRulesOfType() error = %s", err)
+	}
+	if got := ruleIDs(rules); got != "*3,*1,*2" {
+		t.Errorf("GetRulesOfType() = %s, want *3,*1,*2", got)
+	}
+
+	if err := s.Done(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayMoveRejected(t *testing.T) {
+	s, c := newTestServer(t, "move-rejected", "")
+
+	_, err := c.OrderRules(context.Background(), "filter", []string{"*3", "*1", "*2"}, client.OrderingOpts{})
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("OrderRules() error = %v, want an *APIError", err)
+	}
+	if apiErr.Status != 400 || !strings.Contains(apiErr.Detail, "not enough permissions") {
+		t.Errorf("OrderRules() error = %s, want the rejection of the device", apiErr)
+	}
+
+	if err := s.Done(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayUnexpectedRequest(t *testing.T) {
+	s, c := newTestServer(t, "order-rules", "")
+
+	if _, err := c.GetRulesOfType(context.Background(), "nat"); err == nil {
+		t.Fatal("GetRulesOfType() succeeded for a request which is not part of the transcript")
+	}
+	if err := s.Err(); err == nil || !strings.Contains(err.Error(), "/rest/ip/firewall/nat") {
+		t.Errorf("Err() = %v, want it to name the unexpected request", err)
+	}
+}
+
+func TestReplayMissingRequest(t *testing.T) {
+	s, c := newTestServer(t, "order-rules", "")
+
+	if _, err := c.GetRulesOfType(context.Background(), "filter"); err != nil {
+		t.Fatalf("GetRulesOfType() error = %s", err)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Err() = %s", err)
+	}
+	if err := s.Done(); err == nil || !strings.Contains(err.Error(), "POST /rest/ip/firewall/filter/move") {
+		t.Errorf("Done() = %v, want it to name the move which was never sent", err)
+	}
+}
+
+// TestReplayRecordsTranscript checks that replaying a transcript while
+// recording yields the same transcript again, so that a trace can be replayed
+// as often as needed.
+func TestReplayRecordsTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	s, c := newTestServer(t, "order-rules", path)
+	ctx := context.Background()
+
+	if _, err := c.OrderRules(ctx, "filter", []string{"*3", "*1", "*2"}, client.OrderingOpts{}); err != nil {
+		t.Fatalf("OrderRules() error = %s", err)
+	}
+	if _, err := c.GetRulesOfType(ctx, "filter"); err != nil {
+		t.Fatalf("GetRulesOfType() error = %s", err)
+	}
+	if err := s.Done(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := readTranscript(t, filepath.Join("testdata", "order-rules.jsonl"))
+	got := readTranscript(t, path)
+	if len(got) != len(want) {
+		t.Fatalf("recorded %d exchanges, want %d", len(got), len(want))
+	}
+	for i := range want {
+		// only the time of an exchange differs
+		got[i].Time = want[i].Time
+		if got[i] != want[i] {
+			t.Errorf("exchange %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func readTranscript(t *testing.T, path string) []client.Exchange {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	exchanges, err := client.ReadTranscript(f)
+	if err != nil {
+		t.Fatalf("ReadTranscript() error = %s", err)
+	}
+	return exchanges
+}
@@ -0,0 +1,135 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rosmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// durationUnits are the units of RouterOS durations in seconds, largest first.
+var durationUnits = []struct {
+	suffix  string
+	seconds int64
+}{
+	{"w", 7 * 24 * 60 * 60},
+	{"d", 24 * 60 * 60},
+	{"h", 60 * 60},
+	{"m", 60},
+	{"s", 1},
+}
+
+// FormatDuration returns the RouterOS representation of a duration of the
+// given number of seconds, e.g. `1w3d` or `1h30m`. RouterOS has no negative
+// durations, which are formatted as zero.
+func FormatDuration(seconds int64) string {
+	if seconds <= 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	for _, u := range durationUnits {
+		if n := seconds / u.seconds; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, u.suffix)
+			seconds -= n * u.seconds
+		}
+	}
+	return b.String()
+}
+
+// ParseDuration parses a RouterOS duration and returns it in seconds.
+// Durations consist of numbers followed by a unit, e.g. `4w2d` or `1h30m`,
+// optionally ending in a clock time such as `1d00:00:00` or `00:05:00`. A
+// plain number is taken as seconds. Fractions of seconds, such as `500ms`,
+// are truncated.
+func ParseDuration(s string) (int64, error) {
+	orig := s
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+
+	var total, millis int64
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("invalid duration '%s'", orig)
+		}
+		n, err := strconv.ParseInt(s[:i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %w", orig, err)
+		}
+		s = s[i:]
+
+		if strings.HasPrefix(s, ":") {
+			clock, err := parseClock(s[1:])
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration '%s': %w", orig, err)
+			}
+			return total + n*60*60 + clock, nil
+		}
+
+		switch {
+		case strings.HasPrefix(s, "ms"):
+			millis += n
+			s = s[2:]
+		case strings.HasPrefix(s, "us"), strings.HasPrefix(s, "ns"):
+			s = s[2:]
+		default:
+			found := false
+			for _, u := range durationUnits {
+				if strings.HasPrefix(s, u.suffix) {
+					total += n * u.seconds
+					s = s[len(u.suffix):]
+					found = true
+					break
+				}
+			}
+			if !found {
+				return 0, fmt.Errorf("invalid duration '%s': missing or unknown unit", orig)
+			}
+		}
+	}
+	return total + millis/1000, nil
+}
+
+// parseClock parses the `mm:ss` part of a clock time, optionally followed by
+// a fraction of a second, and returns it in seconds.
+func parseClock(s string) (int64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("malformed clock time")
+	}
+	minutes, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed clock time: %w", err)
+	}
+	sec, _, _ := strings.Cut(parts[1], ".")
+	seconds, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed clock time: %w", err)
+	}
+	return minutes*60 + seconds, nil
+}
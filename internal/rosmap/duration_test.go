@@ -0,0 +1,69 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rosmap
+
+import "testing"
+
+func TestDurationRoundTrip(t *testing.T) {
+	tests := []struct {
+		seconds int64
+		s       string
+	}{
+		{0, "0s"},
+		{1, "1s"},
+		{59, "59s"},
+		{60, "1m"},
+		{90 * 60, "1h30m"},
+		{24 * 60 * 60, "1d"},
+		{10 * 24 * 60 * 60, "1w3d"},
+		{52 * 7 * 24 * 60 * 60, "52w"},
+		{7*24*60*60 + 1, "1w1s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := FormatDuration(tt.seconds); got != tt.s {
+				t.Errorf("FormatDuration(%d) = %s, want %s", tt.seconds, got, tt.s)
+			}
+			got, err := ParseDuration(tt.s)
+			if err != nil {
+				t.Fatalf("ParseDuration(%s) error = %s", tt.s, err)
+			}
+			if got != tt.seconds {
+				t.Errorf("ParseDuration(%s) = %d, want %d", tt.s, got, tt.seconds)
+			}
+		})
+	}
+}
+
+func TestFormatDurationNegative(t *testing.T) {
+	if got := FormatDuration(-5); got != "0s" {
+		t.Errorf("FormatDuration(-5) = %s, want 0s", got)
+	}
+}
+
+func TestParseDurationMalformed(t *testing.T) {
+	for _, s := range []string{"", "d", "1x", "1w3", "1h-5m", "00:05", "1:2:3:4", "99999999999999999999s"} {
+		t.Run(s, func(t *testing.T) {
+			if got, err := ParseDuration(s); err == nil {
+				t.Errorf("ParseDuration(%q) = %d, want error", s, got)
+			}
+		})
+	}
+}
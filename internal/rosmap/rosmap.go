@@ -0,0 +1,259 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package rosmap converts between Terraform resource models and the
+// properties of RouterOS objects as exchanged with the REST API.
+//
+// The fields of a model are mapped by their `tfsdk` tag, with underscores
+// replaced by dashes, e.g. `src_address` becomes `src-address`. A `ros` tag
+// overrides the property name, and may carry options separated by commas:
+//
+//	Timeout types.Int64 `tfsdk:"timeout" ros:"address-list-timeout,duration"`
+//	ID      types.String `tfsdk:"id" ros:".id,readonly"`
+//	Host    types.String `tfsdk:"host" ros:"-"`
+//
+// Fields tagged `-` are skipped. Fields with the `readonly` option are only
+// read from the device. Fields with the `duration` option must be of type
+// types.Int64 and hold a number of seconds, which is converted from and to
+// the RouterOS time format, e.g. `1w3d`.
+//
+// Supported field types are types.String, types.Bool and types.Int64.
+// Booleans are sent as `yes` or `no`, and both those and `true` or `false`
+// are accepted when reading.
+package rosmap
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	stringType = reflect.TypeOf(types.String{})
+	boolType   = reflect.TypeOf(types.Bool{})
+	int64Type  = reflect.TypeOf(types.Int64{})
+)
+
+// field describes how a single field of a model is mapped.
+type field struct {
+	index    int
+	property string
+	readonly bool
+	duration bool
+}
+
+// Marshal converts the fields of model, a struct or a pointer to one, to
+// RouterOS properties. Null and unknown values are omitted, unless clear is
+// set, in which case null values are sent as empty strings so that the
+// properties are unset on an existing object.
+func Marshal(model interface{}, clear bool) (map[string]string, error) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	fields, err := fieldsOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	props := make(map[string]string, len(fields))
+	for _, f := range fields {
+		if f.readonly {
+			continue
+		}
+		value, null, err := encode(v.Field(f.index).Interface(), f)
+		if err != nil {
+			return nil, err
+		}
+		if null {
+			if clear {
+				props[f.property] = ""
+			}
+			continue
+		}
+		props[f.property] = value
+	}
+	return props, nil
+}
+
+// Unmarshal sets the fields of model, which must be a pointer to a struct, to
+// the given RouterOS properties. Fields of properties which are missing or
+// empty are set to null.
+func Unmarshal(props map[string]string, model interface{}) error {
+	p := reflect.ValueOf(model)
+	if p.Kind() != reflect.Pointer || p.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rosmap: expected a pointer to a struct, got %T", model)
+	}
+	v := p.Elem()
+	fields, err := fieldsOf(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		value, err := decode(props[f.property], v.Type().Field(f.index).Type, f)
+		if err != nil {
+			return fmt.Errorf("rosmap: invalid value of property '%s': %w", f.property, err)
+		}
+		v.Field(f.index).Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+// Property returns the name of the RouterOS property which the attribute of
+// model with the given name is mapped to, and false if there is none.
+func Property(model interface{}, attribute string) (string, bool) {
+	t := reflect.Indirect(reflect.ValueOf(model)).Type()
+	fields, err := fieldsOf(t)
+	if err != nil {
+		return "", false
+	}
+	for _, f := range fields {
+		if t.Field(f.index).Tag.Get("tfsdk") == attribute {
+			return f.property, true
+		}
+	}
+	return "", false
+}
+
+// fieldsOf returns the mapped fields of the struct type t.
+func fieldsOf(t reflect.Type) ([]field, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("rosmap: expected a struct, got %s", t)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("tfsdk")
+		tag := sf.Tag.Get("ros")
+		if name == "" || name == "-" || tag == "-" {
+			continue
+		}
+
+		f := field{index: i, property: strings.ReplaceAll(name, "_", "-")}
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			f.property = opts[0]
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "readonly":
+				f.readonly = true
+			case "duration":
+				f.duration = true
+			default:
+				return nil, fmt.Errorf("rosmap: unknown option '%s' of field %s", opt, sf.Name)
+			}
+		}
+
+		switch sf.Type {
+		case stringType, boolType:
+			if f.duration {
+				return nil, fmt.Errorf("rosmap: field %s must be of type types.Int64 to hold a duration", sf.Name)
+			}
+		case int64Type:
+		default:
+			return nil, fmt.Errorf("rosmap: unsupported type %s of field %s", sf.Type, sf.Name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// encode returns the RouterOS representation of v, or true if v is null or
+// unknown.
+func encode(v interface{}, f field) (string, bool, error) {
+	switch v := v.(type) {
+	case types.String:
+		if v.IsNull() || v.IsUnknown() {
+			return "", true, nil
+		}
+		return v.ValueString(), false, nil
+	case types.Bool:
+		if v.IsNull() || v.IsUnknown() {
+			return "", true, nil
+		}
+		return FormatBool(v.ValueBool()), false, nil
+	case types.Int64:
+		if v.IsNull() || v.IsUnknown() {
+			return "", true, nil
+		}
+		if f.duration {
+			return FormatDuration(v.ValueInt64()), false, nil
+		}
+		return strconv.FormatInt(v.ValueInt64(), 10), false, nil
+	}
+	return "", false, fmt.Errorf("rosmap: unsupported type %T", v)
+}
+
+// decode converts the RouterOS representation s to a value of type t.
+func decode(s string, t reflect.Type, f field) (interface{}, error) {
+	switch t {
+	case stringType:
+		if s == "" {
+			return types.StringNull(), nil
+		}
+		return types.StringValue(s), nil
+	case boolType:
+		if s == "" {
+			return types.BoolNull(), nil
+		}
+		b, err := ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return types.BoolValue(b), nil
+	case int64Type:
+		if s == "" {
+			return types.Int64Null(), nil
+		}
+		var n int64
+		var err error
+		if f.duration {
+			n, err = ParseDuration(s)
+		} else {
+			n, err = strconv.ParseInt(s, 10, 64)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return types.Int64Value(n), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// FormatBool returns the RouterOS representation of b.
+func FormatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// ParseBool parses a RouterOS boolean, which is either `yes`, `no`, `true`
+// or `false`.
+func ParseBool(s string) (bool, error) {
+	switch s {
+	case "yes", "true":
+		return true, nil
+	case "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("'%s' is not a boolean", s)
+}
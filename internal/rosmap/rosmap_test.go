@@ -0,0 +1,376 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rosmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type testModel struct {
+	ID         types.String `tfsdk:"id" ros:".id,readonly"`
+	SrcAddress types.String `tfsdk:"src_address"`
+	ListName   types.String `tfsdk:"list_name" ros:"list"`
+	Disabled   types.Bool   `tfsdk:"disabled"`
+	Timeout    types.Int64  `tfsdk:"timeout" ros:"address-list-timeout,duration"`
+	Priority   types.Int64  `tfsdk:"priority"`
+	Host       types.String `tfsdk:"host" ros:"-"`
+}
+
+// nullModel returns a testModel with all fields set to null.
+func nullModel() testModel {
+	return testModel{
+		ID:         types.StringNull(),
+		SrcAddress: types.StringNull(),
+		ListName:   types.StringNull(),
+		Disabled:   types.BoolNull(),
+		Timeout:    types.Int64Null(),
+		Priority:   types.Int64Null(),
+		Host:       types.StringNull(),
+	}
+}
+
+// roundTrip marshals model to JSON as sent to the device and unmarshals the
+// JSON into a new model. It returns the JSON object and the new model.
+func roundTrip(t *testing.T, model testModel) (map[string]string, testModel) {
+	t.Helper()
+
+	props, err := Marshal(model, false)
+	if err != nil {
+		t.Fatalf("Marshal() error = %s", err)
+	}
+	b, err := json.Marshal(props)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %s", err)
+	}
+
+	var sent map[string]string
+	if err := json.Unmarshal(b, &sent); err != nil {
+		t.Fatalf("json.Unmarshal() error = %s", err)
+	}
+	got := nullModel()
+	if err := Unmarshal(sent, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %s", err)
+	}
+	return sent, got
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		model func(m *testModel)
+		props map[string]string
+	}{
+		{
+			name:  "null",
+			model: func(m *testModel) {},
+			props: map[string]string{},
+		},
+		{
+			name: "snake case to kebab case",
+			model: func(m *testModel) {
+				m.SrcAddress = types.StringValue("10.0.0.0/24")
+			},
+			props: map[string]string{"src-address": "10.0.0.0/24"},
+		},
+		{
+			name: "renamed property",
+			model: func(m *testModel) {
+				m.ListName = types.StringValue("bogons")
+			},
+			props: map[string]string{"list": "bogons"},
+		},
+		{
+			name: "true",
+			model: func(m *testModel) {
+				m.Disabled = types.BoolValue(true)
+			},
+			props: map[string]string{"disabled": "yes"},
+		},
+		{
+			name: "false",
+			model: func(m *testModel) {
+				m.Disabled = types.BoolValue(false)
+			},
+			props: map[string]string{"disabled": "no"},
+		},
+		{
+			name: "duration",
+			model: func(m *testModel) {
+				m.Timeout = types.Int64Value(10 * 24 * 60 * 60)
+			},
+			props: map[string]string{"address-list-timeout": "1w3d"},
+		},
+		{
+			name: "duration of all units",
+			model: func(m *testModel) {
+				m.Timeout = types.Int64Value(7*24*60*60 + 24*60*60 + 60*60 + 60 + 1)
+			},
+			props: map[string]string{"address-list-timeout": "1w1d1h1m1s"},
+		},
+		{
+			name: "zero duration",
+			model: func(m *testModel) {
+				m.Timeout = types.Int64Value(0)
+			},
+			props: map[string]string{"address-list-timeout": "0s"},
+		},
+		{
+			name: "integer",
+			model: func(m *testModel) {
+				m.Priority = types.Int64Value(-3)
+			},
+			props: map[string]string{"priority": "-3"},
+		},
+		{
+			name: "special characters",
+			model: func(m *testModel) {
+				m.SrcAddress = types.StringValue(`"quoted" \ äöü, ;=`)
+			},
+			props: map[string]string{"src-address": `"quoted" \ äöü, ;=`},
+		},
+		{
+			name: "all fields",
+			model: func(m *testModel) {
+				m.SrcAddress = types.StringValue("192.168.88.1")
+				m.ListName = types.StringValue("lan")
+				m.Disabled = types.BoolValue(true)
+				m.Timeout = types.Int64Value(90 * 60)
+				m.Priority = types.Int64Value(7)
+			},
+			props: map[string]string{
+				"src-address":          "192.168.88.1",
+				"list":                 "lan",
+				"disabled":             "yes",
+				"address-list-timeout": "1h30m",
+				"priority":             "7",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := nullModel()
+			tt.model(&model)
+
+			sent, got := roundTrip(t, model)
+			if !reflect.DeepEqual(sent, tt.props) {
+				t.Errorf("Marshal() = %v, want %v", sent, tt.props)
+			}
+			if !reflect.DeepEqual(got, model) {
+				t.Errorf("round trip = %+v, want %+v", got, model)
+			}
+		})
+	}
+}
+
+func TestRoundTripSkipsReadonlyAndIgnoredFields(t *testing.T) {
+	model := nullModel()
+	model.ID = types.StringValue("*1A")
+	model.Host = types.StringValue("router.lan")
+
+	sent, got := roundTrip(t, model)
+	if len(sent) != 0 {
+		t.Errorf("Marshal() = %v, want no properties", sent)
+	}
+	if !reflect.DeepEqual(got, nullModel()) {
+		t.Errorf("round trip = %+v, want %+v", got, nullModel())
+	}
+}
+
+func TestUnmarshalRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		// want is the JSON sent back to the device, if it differs from the
+		// JSON which was read
+		want string
+	}{
+		{
+			name: "canonical",
+			json: `{".id":"*1A","src-address":"10.0.0.1","list":"lan","disabled":"no","address-list-timeout":"1w3d","priority":"1"}`,
+			want: `{"src-address":"10.0.0.1","list":"lan","disabled":"no","address-list-timeout":"1w3d","priority":"1"}`,
+		},
+		{
+			name: "true and false",
+			json: `{"disabled":"true"}`,
+			want: `{"disabled":"yes"}`,
+		},
+		{
+			name: "unnormalized duration",
+			json: `{"address-list-timeout":"10d"}`,
+			want: `{"address-list-timeout":"1w3d"}`,
+		},
+		{
+			name: "clock time",
+			json: `{"address-list-timeout":"1d00:05:00"}`,
+			want: `{"address-list-timeout":"1d5m"}`,
+		},
+		{
+			name: "fractional seconds",
+			json: `{"address-list-timeout":"1m30s500ms"}`,
+			want: `{"address-list-timeout":"1m30s"}`,
+		},
+		{
+			name: "plain seconds",
+			json: `{"address-list-timeout":"300"}`,
+			want: `{"address-list-timeout":"5m"}`,
+		},
+		{
+			name: "empty values are null",
+			json: `{"src-address":"","disabled":"","address-list-timeout":""}`,
+			want: `{}`,
+		},
+		{
+			name: "unknown properties",
+			json: `{"dynamic":"yes","bytes":"1234"}`,
+			want: `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var props map[string]string
+			if err := json.Unmarshal([]byte(tt.json), &props); err != nil {
+				t.Fatalf("json.Unmarshal() error = %s", err)
+			}
+			model := nullModel()
+			if err := Unmarshal(props, &model); err != nil {
+				t.Fatalf("Unmarshal() error = %s", err)
+			}
+
+			sent, got := roundTrip(t, model)
+			var want map[string]string
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("json.Unmarshal() error = %s", err)
+			}
+			if !reflect.DeepEqual(sent, want) {
+				t.Errorf("Marshal() = %v, want %v", sent, want)
+			}
+			// what was sent must read back as the same model
+			if !reflect.DeepEqual(got, withoutID(model)) {
+				t.Errorf("round trip = %+v, want %+v", got, withoutID(model))
+			}
+		})
+	}
+}
+
+// withoutID returns m without its read-only ID, which is never sent.
+func withoutID(m testModel) testModel {
+	m.ID = types.StringNull()
+	return m
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		property string
+	}{
+		{name: "boolean", json: `{"disabled":"maybe"}`, property: "disabled"},
+		{name: "capitalized boolean", json: `{"disabled":"Yes"}`, property: "disabled"},
+		{name: "integer", json: `{"priority":"1.5"}`, property: "priority"},
+		{name: "integer overflow", json: `{"priority":"99999999999999999999"}`, property: "priority"},
+		{name: "duration without unit", json: `{"address-list-timeout":"1w3"}`, property: "address-list-timeout"},
+		{name: "duration with unknown unit", json: `{"address-list-timeout":"3y"}`, property: "address-list-timeout"},
+		{name: "duration without number", json: `{"address-list-timeout":"w"}`, property: "address-list-timeout"},
+		{name: "negative duration", json: `{"address-list-timeout":"-1d"}`, property: "address-list-timeout"},
+		{name: "malformed clock time", json: `{"address-list-timeout":"1d00:05"}`, property: "address-list-timeout"},
+		{name: "clock time with garbage", json: `{"address-list-timeout":"00:xx:00"}`, property: "address-list-timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var props map[string]string
+			if err := json.Unmarshal([]byte(tt.json), &props); err != nil {
+				t.Fatalf("json.Unmarshal() error = %s", err)
+			}
+			model := nullModel()
+			err := Unmarshal(props, &model)
+			if err == nil {
+				t.Fatalf("Unmarshal() = %+v, want error", model)
+			}
+			if !strings.Contains(err.Error(), "'"+tt.property+"'") {
+				t.Errorf("Unmarshal() error = %s, want it to name the property '%s'", err, tt.property)
+			}
+		})
+	}
+}
+
+func TestMarshalClear(t *testing.T) {
+	model := nullModel()
+	model.Disabled = types.BoolValue(false)
+
+	props, err := Marshal(model, true)
+	if err != nil {
+		t.Fatalf("Marshal() error = %s", err)
+	}
+	want := map[string]string{
+		"src-address":          "",
+		"list":                 "",
+		"disabled":             "no",
+		"address-list-timeout": "",
+		"priority":             "",
+	}
+	if !reflect.DeepEqual(props, want) {
+		t.Errorf("Marshal() = %v, want %v", props, want)
+	}
+
+	// clearing must not change what is read back
+	got := nullModel()
+	if err := Unmarshal(props, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %s", err)
+	}
+	if !reflect.DeepEqual(got, model) {
+		t.Errorf("round trip = %+v, want %+v", got, model)
+	}
+}
+
+func TestFieldsOfInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+	}{
+		{name: "unknown option", model: &struct {
+			A types.String `tfsdk:"a" ros:"a,secret"`
+		}{}},
+		{name: "duration of wrong type", model: &struct {
+			A types.String `tfsdk:"a" ros:",duration"`
+		}{}},
+		{name: "unsupported type", model: &struct {
+			A types.List `tfsdk:"a"`
+		}{}},
+		{name: "no struct", model: new(string)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.model, false); err == nil {
+				t.Error("Marshal() error = nil, want error")
+			}
+			if err := Unmarshal(map[string]string{}, tt.model); err == nil {
+				t.Error("Unmarshal() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rostest
+
+import (
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+// Fake is a client.API implementation backed by an in-memory Server. Since it
+// uses the actual client against the fake device, it exhibits the same
+// behavior as a client connected to a real device.
+type Fake struct {
+	*client.Client
+
+	// Server is the fake device, e.g. for seeding and inspecting tables.
+	Server *Server
+}
+
+// Ensure Fake fully implements client.API.
+var _ client.API = &Fake{}
+
+// NewFake starts a new fake device and returns a client connected to it. It
+// must be closed by the caller.
+func NewFake() (*Fake, error) {
+	s, err := NewServer()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(s.ClientOpts())
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return &Fake{Client: c, Server: s}, nil
+}
+
+// Close shuts down the fake device.
+func (f *Fake) Close() {
+	f.Server.Close()
+}
@@ -0,0 +1,124 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// print implements the `print` command, which lists the objects of menu
+// matching the `.query` of the request, restricted to its `.proplist`.
+func (s *Server) print(w http.ResponseWriter, r *http.Request, menu string) {
+	var body struct {
+		Query    []string `json:".query"`
+		Proplist []string `json:".proplist"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.reads[menu]++
+	table := s.tables[menu]
+	if st, ok := s.stale[menu]; ok && time.Now().Before(st.until) {
+		table = st.objects
+	}
+	matching := []map[string]string{}
+	for _, o := range table {
+		ok, err := evalQuery(body.Query, o)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if ok {
+			matching = append(matching, project(o, body.Proplist))
+		}
+	}
+	writeJSON(w, matching)
+}
+
+// evalQuery reports whether o matches the query words, which are evaluated
+// on a stack like RouterOS does: `key=value`, `key<value` and `key>value`
+// compare a property, `key` and `-key` test its presence, and `#!`, `#&` and
+// `#|` negate the topmost result or combine the two topmost ones. Remaining
+// results must all be true. Comparisons are made on strings.
+func evalQuery(words []string, o map[string]string) (bool, error) {
+	var stack []bool
+	pop := func(n int) ([]bool, error) {
+		if len(stack) < n {
+			return nil, fmt.Errorf("invalid query, not enough operands")
+		}
+		top := stack[len(stack)-n:]
+		stack = stack[:len(stack)-n]
+		return top, nil
+	}
+
+	for _, word := range words {
+		switch {
+		case word == "#!":
+			v, err := pop(1)
+			if err != nil {
+				return false, err
+			}
+			stack = append(stack, !v[0])
+		case word == "#&" || word == "#|":
+			v, err := pop(2)
+			if err != nil {
+				return false, err
+			}
+			if word == "#&" {
+				stack = append(stack, v[0] && v[1])
+			} else {
+				stack = append(stack, v[0] || v[1])
+			}
+		case strings.HasPrefix(word, "#"):
+			return false, fmt.Errorf("invalid query, unsupported operation '%s'", word)
+		case strings.HasPrefix(word, "-"):
+			_, ok := o[word[1:]]
+			stack = append(stack, !ok)
+		default:
+			i := strings.IndexAny(word, "=<>")
+			if i == -1 {
+				_, ok := o[word]
+				stack = append(stack, ok)
+				continue
+			}
+			v, ok := o[word[:i]]
+			switch word[i] {
+			case '=':
+				stack = append(stack, v == word[i+1:])
+			case '<':
+				stack = append(stack, ok && v < word[i+1:])
+			case '>':
+				stack = append(stack, ok && v > word[i+1:])
+			}
+		}
+	}
+
+	for _, v := range stack {
+		if !v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,540 @@
+/*
+ * terraform-provider-routeros-firewall-list
+ * Copyright (C) 2023  Samuel Kunst
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package rostest provides an in-memory fake of the RouterOS REST API for
+// exercising the client and provider without a physical device.
+package rostest
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
+)
+
+const (
+	// Username and Password are the credentials accepted by the server.
+	Username = "admin"
+	Password = "password"
+
+	// DefaultVersion is the RouterOS version reported by new servers.
+	DefaultVersion = "7.16 (stable)"
+)
+
+// Server is a fake RouterOS device serving the REST API over TLS. Every menu
+// path is backed by an ordered table of objects which is created on first
+// use, so arbitrary menus such as `/ip/firewall/filter` or `/interface/list`
+// work without further setup.
+type Server struct {
+	*httptest.Server
+
+	// CAFile is the path to the PEM encoded certificate of the server. It is
+	// removed again by Close.
+	CAFile string
+
+	mu      sync.Mutex
+	tables  map[string][]map[string]string
+	nextID  int
+	version string
+
+	// moveLag delays the visibility of moves, see SetMoveLag.
+	moveLag time.Duration
+	stale   map[string]staleTable
+
+	// failures maps commands such as `/ip/firewall/filter/move` to the
+	// error they fail with, see FailCommand.
+	failures map[string]string
+	// reads counts how often each table was listed, see Reads.
+	reads map[string]int
+
+	// dropped holds the commands which are acknowledged without taking
+	// effect, see DropCommand.
+	dropped map[string]bool
+}
+
+// staleTable is the ordering of a table before a move, which is served until
+// the move becomes visible.
+type staleTable struct {
+	objects []map[string]string
+	until   time.Time
+}
+
+// NewServer starts a new fake device. It must be closed by the caller.
+func NewServer() (*Server, error) {
+	s := &Server{
+		tables:  map[string][]map[string]string{},
+		reads:   map[string]int{},
+		nextID:  1,
+		version: DefaultVersion,
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+
+	f, err := os.CreateTemp("", "rostest-ca-*.pem")
+	if err != nil {
+		s.Server.Close()
+		return nil, err
+	}
+	defer f.Close()
+	s.CAFile = f.Name()
+
+	if err := pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close shuts down the server and removes its certificate file.
+func (s *Server) Close() {
+	s.Server.Close()
+	os.Remove(s.CAFile)
+}
+
+// ClientOpts returns options for a client connecting to the server.
+func (s *Server) ClientOpts() client.ClientOpts {
+	return client.ClientOpts{
+		HostURL:  s.URL,
+		Username: Username,
+		Password: Password,
+		CA:       s.CAFile,
+	}
+}
+
+// Env returns the provider environment variables for connecting to the
+// server, e.g. for use with `t.Setenv` in acceptance tests.
+func (s *Server) Env() map[string]string {
+	u, _ := url.Parse(s.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	return map[string]string{
+		"ROS_HOSTURL":        host,
+		"ROS_PORT":           port,
+		"ROS_USERNAME":       Username,
+		"ROS_PASSWORD":       Password,
+		"ROS_CA_CERTIFICATE": s.CAFile,
+	}
+}
+
+// SetVersion sets the RouterOS version reported by `/system/resource`, e.g.
+// `7.10 (stable)`.
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// SetMoveLag makes moves become visible to reads only after d has elapsed,
+// mimicking devices which apply moves with a delay. Until then, reads of the
+// table return the ordering before the most recent move.
+func (s *Server) SetMoveLag(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.moveLag = d
+}
+
+// FailCommand makes every invocation of command on the table at menu, e.g.
+// `move` on `/ip/firewall/filter`, fail with detail as the error. An empty
+// detail makes the command succeed again.
+func (s *Server) FailCommand(menu, command, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures == nil {
+		s.failures = map[string]string{}
+	}
+	if detail == "" {
+		delete(s.failures, menu+"/"+command)
+		return
+	}
+	s.failures[menu+"/"+command] = detail
+}
+
+// DropCommand makes every invocation of command on the table at menu succeed
+// without taking effect, mimicking devices which acknowledge moves that never
+// land. Passing false makes the command take effect again.
+func (s *Server) DropCommand(menu, command string, drop bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dropped == nil {
+		s.dropped = map[string]bool{}
+	}
+	s.dropped[menu+"/"+command] = drop
+}
+
+// Move places the objects with the given IDs in front of destination, like
+// the `move` command, e.g. to simulate changes made outside of Terraform.
+func (s *Server) Move(menu, destination string, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.moveObjects(menu, strings.Join(ids, ","), destination)
+}
+
+// Reads returns how often the table at menu was listed, either as a whole or
+// by the `print` command.
+func (s *Server) Reads(menu string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads[menu]
+}
+
+// Add appends objects to the table at menu and returns their IDs.
+func (s *Server) Add(menu string, objects ...map[string]string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(objects))
+	for _, o := range objects {
+		ids = append(ids, s.add(menu, o)[".id"])
+	}
+	return ids
+}
+
+// Objects returns a copy of all objects of the table at menu, in order.
+func (s *Server) Objects(menu string) []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects := make([]map[string]string, 0, len(s.tables[menu]))
+	for _, o := range s.tables[menu] {
+		objects = append(objects, copyObject(o))
+	}
+	return objects
+}
+
+// IDs returns the IDs of all objects of the table at menu, in order.
+func (s *Server) IDs(menu string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.tables[menu]))
+	for _, o := range s.tables[menu] {
+		ids = append(ids, o[".id"])
+	}
+	return ids
+}
+
+// add appends a copy of o with a newly assigned ID to the table at menu and
+// returns the stored object. The caller must hold s.mu.
+func (s *Server) add(menu string, o map[string]string) map[string]string {
+	obj := copyObject(o)
+	obj[".id"] = fmt.Sprintf("*%X", s.nextID)
+	s.nextID++
+	s.tables[menu] = append(s.tables[menu], obj)
+	return obj
+}
+
+func (s *Server) index(menu, id string) int {
+	for i, o := range s.tables[menu] {
+		if o[".id"] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != Username || pass != Password {
+		writeError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, "/rest")
+	if p == r.URL.Path || !strings.HasPrefix(p, "/") {
+		writeError(w, http.StatusNotFound, "no such command")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p == "/system/resource" && r.Method == http.MethodGet {
+		writeJSON(w, map[string]string{"version": s.version, "board-name": "rostest"})
+		return
+	}
+
+	i := strings.LastIndex(p, "/")
+	menu, last := p[:i], p[i+1:]
+	if strings.HasPrefix(last, "*") {
+		s.serveObject(w, r, menu, last)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.serveTable(w, r, p)
+		return
+	}
+	if last == "print" {
+		s.print(w, r, menu)
+		return
+	}
+
+	body := map[string]string{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if detail, ok := s.failures[p]; ok {
+		writeError(w, http.StatusBadRequest, detail)
+		return
+	}
+	if s.dropped[p] {
+		writeJSON(w, []string{})
+		return
+	}
+	switch last {
+	case "move":
+		s.move(w, menu, body)
+	case "remove":
+		s.remove(w, menu, body)
+	case "set":
+		s.set(w, menu, body)
+	default:
+		writeError(w, http.StatusBadRequest, "no such command")
+	}
+}
+
+// serveTable handles requests on the table at menu.
+func (s *Server) serveTable(w http.ResponseWriter, r *http.Request, menu string) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		var proplist []string
+		if query.Has(".proplist") {
+			proplist = strings.Split(query.Get(".proplist"), ",")
+			query.Del(".proplist")
+		}
+		s.reads[menu]++
+		table := s.tables[menu]
+		if st, ok := s.stale[menu]; ok && time.Now().Before(st.until) {
+			table = st.objects
+		}
+		matching := []map[string]string{}
+	objects:
+		for _, o := range table {
+			for k := range query {
+				if o[k] != query.Get(k) {
+					continue objects
+				}
+			}
+			matching = append(matching, project(o, proplist))
+		}
+		writeJSON(w, matching)
+	case http.MethodPut:
+		o := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// place-before is a parameter of the command rather than a property
+		before := -1
+		if id, ok := o["place-before"]; ok {
+			delete(o, "place-before")
+			if before = s.index(menu, id); before == -1 {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("no such item (%s)", id))
+				return
+			}
+		}
+
+		obj := s.add(menu, o)
+		if before != -1 {
+			table := s.tables[menu]
+			copy(table[before+1:], table[before:len(table)-1])
+			table[before] = obj
+		}
+		writeJSON(w, obj)
+	default:
+		writeError(w, http.StatusBadRequest, "no such command")
+	}
+}
+
+// serveObject handles requests on the object with the given ID in menu.
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, menu, id string) {
+	i := s.index(menu, id)
+	if i == -1 {
+		writeError(w, http.StatusNotFound, "no such item")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, s.tables[menu][i])
+	case http.MethodPatch:
+		props := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&props); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for k, v := range props {
+			if k != ".id" {
+				s.tables[menu][i][k] = v
+			}
+		}
+		writeJSON(w, s.tables[menu][i])
+	case http.MethodDelete:
+		s.tables[menu] = append(s.tables[menu][:i], s.tables[menu][i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusBadRequest, "no such command")
+	}
+}
+
+// lookup resolves the comma-separated IDs in numbers to their objects.
+func (s *Server) lookup(menu, numbers string) ([]map[string]string, error) {
+	var objects []map[string]string
+	for _, id := range strings.Split(numbers, ",") {
+		i := s.index(menu, id)
+		if i == -1 {
+			return nil, fmt.Errorf("no such item (%s)", id)
+		}
+		objects = append(objects, s.tables[menu][i])
+	}
+	return objects, nil
+}
+
+// move implements the `move` command, see moveObjects.
+func (s *Server) move(w http.ResponseWriter, menu string, body map[string]string) {
+	if err := s.moveObjects(menu, body["numbers"], body["destination"]); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, []string{})
+}
+
+// moveObjects places the objects in numbers in front of destination in the
+// given order, or at the end of the table if there is no such object. The
+// caller must hold s.mu.
+func (s *Server) moveObjects(menu, numbers, destination string) error {
+	moved, err := s.lookup(menu, numbers)
+	if err != nil {
+		return err
+	}
+
+	isMoved := map[string]bool{}
+	for _, o := range moved {
+		isMoved[o[".id"]] = true
+	}
+
+	rest := make([]map[string]string, 0, len(s.tables[menu]))
+	for _, o := range s.tables[menu] {
+		if !isMoved[o[".id"]] {
+			rest = append(rest, o)
+		}
+	}
+
+	at := len(rest)
+	for i, o := range rest {
+		if o[".id"] == destination {
+			at = i
+			break
+		}
+	}
+
+	table := make([]map[string]string, 0, len(s.tables[menu]))
+	table = append(table, rest[:at]...)
+	table = append(table, moved...)
+	table = append(table, rest[at:]...)
+	if s.moveLag > 0 {
+		if s.stale == nil {
+			s.stale = map[string]staleTable{}
+		}
+		s.stale[menu] = staleTable{objects: append([]map[string]string(nil), s.tables[menu]...), until: time.Now().Add(s.moveLag)}
+	}
+	s.tables[menu] = table
+	return nil
+}
+
+// remove implements the `remove` command for the objects in `numbers`.
+func (s *Server) remove(w http.ResponseWriter, menu string, body map[string]string) {
+	removed, err := s.lookup(menu, body["numbers"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, o := range removed {
+		i := s.index(menu, o[".id"])
+		s.tables[menu] = append(s.tables[menu][:i], s.tables[menu][i+1:]...)
+	}
+	writeJSON(w, []string{})
+}
+
+// set implements the `set` command for the objects in `numbers`.
+func (s *Server) set(w http.ResponseWriter, menu string, body map[string]string) {
+	objects, err := s.lookup(menu, body["numbers"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, o := range objects {
+		for k, v := range body {
+			if k != "numbers" && k != ".id" {
+				o[k] = v
+			}
+		}
+	}
+	writeJSON(w, []string{})
+}
+
+// project returns the given properties of o, or all of them if props is
+// empty, like the `.proplist` query parameter.
+func project(o map[string]string, props []string) map[string]string {
+	if len(props) == 0 {
+		return o
+	}
+	p := make(map[string]string, len(props))
+	for _, k := range props {
+		if v, ok := o[k]; ok {
+			p[k] = v
+		}
+	}
+	return p
+}
+
+func copyObject(o map[string]string) map[string]string {
+	c := make(map[string]string, len(o))
+	for k, v := range o {
+		c[k] = v
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds in the format used by RouterOS for failed requests.
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	body := map[string]any{"error": status, "message": http.StatusText(status)}
+	if detail != "" {
+		body["detail"] = detail
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -21,9 +21,14 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/client"
 	"github.com/toalaah/terraform-provider-routeros-firewall-list/internal/provider"
 )
 
@@ -57,9 +62,63 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	dumpMetrics, err := setupMetrics()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = providerserver.Serve(context.Background(), provider.New(version), opts)
+	dumpMetrics()
 
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 }
+
+// setupMetrics enables collecting metrics if requested via the environment.
+// ROS_METRICS_LISTEN serves them on the given loopback address, e.g.
+// `127.0.0.1:9464`, for as long as the provider runs. ROS_METRICS_FILE writes
+// them to the given file once the provider shuts down, which is done by the
+// returned function.
+func setupMetrics() (func(), error) {
+	listen, file := os.Getenv("ROS_METRICS_LISTEN"), os.Getenv("ROS_METRICS_FILE")
+	if listen == "" && file == "" {
+		return func() {}, nil
+	}
+
+	metrics := client.EnableMetrics()
+
+	if listen != "" {
+		host, _, err := net.SplitHostPort(listen)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ROS_METRICS_LISTEN address %s: %w", listen, err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return nil, fmt.Errorf("invalid ROS_METRICS_LISTEN address %s, metrics may only be served on a loopback address", listen)
+		}
+		l, err := net.Listen("tcp", listen)
+		if err != nil {
+			return nil, err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		go func() {
+			_ = http.Serve(l, mux)
+		}()
+	}
+
+	return func() {
+		if file == "" {
+			return
+		}
+		f, err := os.Create(file)
+		if err != nil {
+			log.Printf("unable to write metrics: %s", err)
+			return
+		}
+		defer f.Close()
+		if _, err := metrics.WriteTo(f); err != nil {
+			log.Printf("unable to write metrics: %s", err)
+		}
+	}, nil
+}